@@ -0,0 +1,246 @@
+package bluegenes
+
+// How Recombine pairs the subunits of two parents before recombining them.
+type AlignmentMethod int
+
+const (
+	// Pairs subunits by index (default).
+	PositionalAlignment AlignmentMethod = iota
+	// Pairs each subunit with the first unpaired subunit of the other parent
+	// that has the same Name, regardless of order.
+	NameAlignment
+	// Pairs subunits with similar Names using a Needleman-Wunsch global
+	// alignment of the sequences of Names, preserving order.
+	SequenceAlignment
+)
+
+// Which subunits left unpaired by an AlignmentMethod are inherited by the child.
+type UnmatchedPolicy int
+
+const (
+	// Inherits the unmatched subunits of the first parent only (default).
+	InheritFirst UnmatchedPolicy = iota
+	// Inherits the unmatched subunits of both parents.
+	InheritBoth
+	// Inherits each unmatched subunit with probability 0.5.
+	InheritRandom
+	// Inherits no unmatched subunits.
+	InheritNone
+)
+
+// A column of an alignment: indices into the first and second sequences of
+// subunits, where -1 marks a gap.
+type alignedPair struct {
+	first  int
+	second int
+}
+
+// Returns true if both sides of the alignedPair are set.
+func (p alignedPair) paired() bool {
+	return p.first >= 0 && p.second >= 0
+}
+
+// Aligns two sequences of subunit names, returning the columns of the
+// alignment in the order the child should inherit them.
+func alignSubunits(first, second []string, method AlignmentMethod) []alignedPair {
+	switch method {
+	case NameAlignment:
+		return alignByName(first, second)
+	case SequenceAlignment:
+		return needlemanWunsch(first, second)
+	default:
+		size, _ := max(len(first), len(second))
+		pairs := make([]alignedPair, size)
+		for i := range pairs {
+			pairs[i] = alignedPair{-1, -1}
+			if i < len(first) {
+				pairs[i].first = i
+			}
+			if i < len(second) {
+				pairs[i].second = i
+			}
+		}
+		return pairs
+	}
+}
+
+// Pairs names greedily in order of the first sequence. Unpaired names of the
+// second sequence are placed after the column holding their predecessor.
+func alignByName(first, second []string) []alignedPair {
+	available := make(map[string][]int)
+	for j, name := range second {
+		available[name] = append(available[name], j)
+	}
+	pairs := []alignedPair{}
+	paired := make([]bool, len(second))
+	for i, name := range first {
+		pair := alignedPair{i, -1}
+		if indices := available[name]; len(indices) > 0 {
+			pair.second = indices[0]
+			paired[indices[0]] = true
+			available[name] = indices[1:]
+		}
+		pairs = append(pairs, pair)
+	}
+	for j := range second {
+		if paired[j] {
+			continue
+		}
+		position := 0
+		for k, pair := range pairs {
+			if pair.second == j-1 && j > 0 {
+				position = k + 1
+				break
+			}
+		}
+		pairs = append(pairs[:position], append([]alignedPair{{-1, j}}, pairs[position:]...)...)
+	}
+	return pairs
+}
+
+// Returns the similarity of two names in [0, 1]: the fraction of positions
+// with the same rune.
+func nameSimilarity(a, b string) float64 {
+	first, second := []rune(a), []rune(b)
+	size, _ := max(len(first), len(second))
+	if size == 0 {
+		return 1.0
+	}
+	same := 0
+	for i := 0; i < len(first) && i < len(second); i++ {
+		if first[i] == second[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(size)
+}
+
+// Globally aligns two sequences of names. Pairing two names scores
+// 2*similarity-1 and gaps score 0, so only names that are more than half
+// similar are paired.
+func needlemanWunsch(first, second []string) []alignedPair {
+	n, m := len(first), len(second)
+	scores := make([][]float64, n+1)
+	for i := range scores {
+		scores[i] = make([]float64, m+1)
+	}
+	pair_score := func(i, j int) float64 {
+		return 2.0*nameSimilarity(first[i], second[j]) - 1.0
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			best := scores[i-1][j-1] + pair_score(i-1, j-1)
+			if scores[i-1][j] > best {
+				best = scores[i-1][j]
+			}
+			if scores[i][j-1] > best {
+				best = scores[i][j-1]
+			}
+			scores[i][j] = best
+		}
+	}
+
+	pairs := []alignedPair{}
+	i, j := n, m
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && pair_score(i-1, j-1) > 0.0 &&
+			scores[i][j] == scores[i-1][j-1]+pair_score(i-1, j-1):
+			pairs = append(pairs, alignedPair{i - 1, j - 1})
+			i, j = i-1, j-1
+		case i > 0 && (j == 0 || scores[i][j] == scores[i-1][j]):
+			pairs = append(pairs, alignedPair{i - 1, -1})
+			i--
+		default:
+			pairs = append(pairs, alignedPair{-1, j - 1})
+			j--
+		}
+	}
+	for a, b := 0, len(pairs)-1; a < b; a, b = a+1, b-1 {
+		pairs[a], pairs[b] = pairs[b], pairs[a]
+	}
+	return pairs
+}
+
+// Returns true if an unmatched subunit should be inherited by the child.
+func inheritUnmatched(options RecombineOptions, pair alignedPair) bool {
+	switch options.UnmatchedSubunits.Val {
+	case InheritBoth:
+		return true
+	case InheritRandom:
+		return options.Rand.Val.Float64() < 0.5
+	case InheritNone:
+		return false
+	default:
+		return pair.first >= 0
+	}
+}
+
+// Returns the number of paired columns in the alignment.
+func countPaired(pairs []alignedPair) int {
+	count := 0
+	for _, pair := range pairs {
+		if pair.paired() {
+			count++
+		}
+	}
+	return count
+}
+
+// Pairs the subunits of two parents by the names returned by name according to
+// method and returns the subunits of their child: paired subunits are passed
+// to recombine, swapping dad and mom at the points chosen by crossover (unless
+// skip), and unpaired subunits are copied with copy_subunit according to
+// options.UnmatchedSubunits. Called by Recombine with both parents' locks held.
+func recombineAligned[S any](first, second []S, name func(S) string, method AlignmentMethod,
+	crossover CrossoverOptions, options RecombineOptions, skip bool, copy_subunit func(S) S,
+	recombine func(dad, mom S) (S, error)) ([]S, error) {
+	names, other_names := make([]string, len(first)), make([]string, len(second))
+	for i, subunit := range first {
+		names[i] = name(subunit)
+	}
+	for i, subunit := range second {
+		other_names[i] = name(subunit)
+	}
+	pairs := alignSubunits(names, other_names, method)
+
+	var indices []int
+	if !skip {
+		var err error
+		indices, err = crossover.indices(options.Rand.Val, countPaired(pairs))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	subunits := []S{}
+	paired, swapped := 0, false
+	for _, pair := range pairs {
+		if !pair.paired() {
+			if inheritUnmatched(options, pair) {
+				if pair.first >= 0 {
+					subunits = append(subunits, copy_subunit(first[pair.first]))
+				} else {
+					subunits = append(subunits, copy_subunit(second[pair.second]))
+				}
+			}
+			continue
+		}
+		for len(indices) > 0 && indices[0] == paired {
+			swapped = !swapped
+			indices = indices[1:]
+		}
+		paired++
+
+		dad, mom := first[pair.first], second[pair.second]
+		if swapped {
+			dad, mom = mom, dad
+		}
+		subunit, err := recombine(dad, mom)
+		if err != nil {
+			return nil, err
+		}
+		subunits = append(subunits, subunit)
+	}
+	return subunits, nil
+}
@@ -0,0 +1,172 @@
+package bluegenes
+
+import (
+	"testing"
+)
+
+func namedNucleosome(names ...string) *Nucleosome[int] {
+	nucleosome := &Nucleosome[int]{Name: "nuc"}
+	for i, name := range names {
+		nucleosome.Genes = append(nucleosome.Genes, &Gene[int]{Name: name, Bases: []int{i, i, i}})
+	}
+	return nucleosome
+}
+
+func TestAlignment(t *testing.T) {
+	t.Run("NameAlignment", func(t *testing.T) {
+		t.Parallel()
+		pairs := alignSubunits([]string{"a", "b", "c", "d"}, []string{"b", "x", "a", "d"}, NameAlignment)
+		expected := []alignedPair{{0, 2}, {1, 0}, {-1, 1}, {2, -1}, {3, 3}}
+		if !equal(pairs, expected) {
+			t.Errorf("NameAlignment failed: expected %v, observed %v", expected, pairs)
+		}
+
+		pairs = alignSubunits([]string{"a", "a"}, []string{"a", "b", "a"}, NameAlignment)
+		expected = []alignedPair{{0, 0}, {-1, 1}, {1, 2}}
+		if !equal(pairs, expected) {
+			t.Errorf("NameAlignment failed with duplicate names: expected %v, observed %v", expected, pairs)
+		}
+	})
+
+	t.Run("SequenceAlignment", func(t *testing.T) {
+		t.Parallel()
+		pairs := alignSubunits([]string{"abcd", "efgh", "ijkl"}, []string{"abcd", "ijkz"}, SequenceAlignment)
+		expected := []alignedPair{{0, 0}, {1, -1}, {2, 1}}
+		if !equal(pairs, expected) {
+			t.Errorf("SequenceAlignment failed: expected %v, observed %v", expected, pairs)
+		}
+
+		pairs = alignSubunits([]string{"abcd"}, []string{"wxyz"}, SequenceAlignment)
+		if countPaired(pairs) != 0 || len(pairs) != 2 {
+			t.Errorf("SequenceAlignment failed to leave dissimilar names unpaired: observed %v", pairs)
+		}
+
+		pairs = alignSubunits([]string{"a", "b"}, []string{"a"}, PositionalAlignment)
+		expected = []alignedPair{{0, 0}, {1, -1}}
+		if !equal(pairs, expected) {
+			t.Errorf("PositionalAlignment failed: expected %v, observed %v", expected, pairs)
+		}
+	})
+
+	policies := map[string]struct {
+		policy UnmatchedPolicy
+		names  []string
+	}{
+		"InheritFirst": {InheritFirst, []string{"a", "b", "c"}},
+		"InheritBoth":  {InheritBoth, []string{"x", "a", "b", "c"}},
+		"InheritNone":  {InheritNone, []string{"a", "b"}},
+	}
+	for name, policy := range policies {
+		name, policy := name, policy
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dad := namedNucleosome("a", "b", "c")
+			mom := namedNucleosome("x", "a", "b")
+			child := &Nucleosome[int]{}
+			err := dad.Recombine(mom, []int{}, child, RecombineOptions{
+				AlignGenes:          NewOption(NameAlignment),
+				UnmatchedSubunits:   NewOption(policy.policy),
+				NucleosomeCrossover: NewOption(CrossoverOptions{Rate: NewOption(0.0)}),
+				GeneCrossover: NewOption(CrossoverOptions{
+					Method: NewOption(UniformCrossover), SwapProbability: NewOption(1.0),
+				}),
+			})
+			if err != nil {
+				t.Fatalf("%s failed with error: %v", name, err)
+			}
+			names := []string{}
+			for _, gene := range child.Genes {
+				names = append(names, gene.Name)
+				for i, other := range mom.Genes {
+					if gene.Name == other.Name && gene.Name != "c" && !equal(gene.Bases, mom.Genes[i].Bases) {
+						t.Errorf("%s failed to recombine %s with its namesake: observed %v", name, gene.Name, gene.Bases)
+					}
+				}
+			}
+			if !equal(names, policy.names) {
+				t.Errorf("%s failed: expected %v, observed %v", name, policy.names, names)
+			}
+		})
+	}
+
+	t.Run("InheritRandom", func(t *testing.T) {
+		t.Parallel()
+		dad := namedNucleosome("a", "b", "c")
+		mom := namedNucleosome("x", "a", "b")
+		sizes := newSet[int]()
+		for i := 0; i < 50; i++ {
+			child := &Nucleosome[int]{}
+			dad.Recombine(mom, []int{}, child, RecombineOptions{
+				AlignGenes:        NewOption(NameAlignment),
+				UnmatchedSubunits: NewOption(InheritRandom),
+				Rand:              NewOption(NewRand(int64(i))),
+			})
+			sizes.add(len(child.Genes))
+		}
+		if !sizes.equal(newSet(2, 3, 4)) || sizes.len() != 3 {
+			t.Errorf("InheritRandom failed: expected 2 to 4 genes, observed %v", sizes.toSlice())
+		}
+	})
+
+	t.Run("Chromosome", func(t *testing.T) {
+		t.Parallel()
+		dad, _ := rangeChromosome(3, 2, 0, 4)
+		mom := dad.DeepCopy()
+		mom.Nucleosomes = append([]*Nucleosome[int]{namedNucleosome("z")}, mom.Nucleosomes...)
+		mom.Nucleosomes[0].Name = "zzzz"
+		child := &Chromosome[int]{}
+		err := dad.Recombine(mom, []int{}, child, RecombineOptions{
+			AlignNucleosomes: NewOption(SequenceAlignment),
+		})
+		if err != nil {
+			t.Fatalf("Chromosome.Recombine failed with error: %v", err)
+		}
+		if len(child.Nucleosomes) != 3 {
+			t.Fatalf("Chromosome.Recombine failed: expected 3 nucleosomes, observed %d", len(child.Nucleosomes))
+		}
+		for i, nucleosome := range child.Nucleosomes {
+			for j, gene := range nucleosome.Genes {
+				if !equal(gene.Bases, dad.Nucleosomes[i].Genes[j].Bases) {
+					t.Errorf("Chromosome.Recombine failed to align identical nucleosomes: expected %v, observed %v",
+						dad.Nucleosomes[i].Genes[j].Bases, gene.Bases)
+				}
+			}
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(31)
+		initial_population := []Code[int]{}
+		for i := 0; i < 10; i++ {
+			nucleosome, _ := rangeNucleosome(3, 0, 5)
+			initial_population = append(initial_population, Code[int]{Nucleosome: NewOption(nucleosome)})
+		}
+		n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate: NewOption(func(code *Code[int]) {
+				MutateCode(code)
+				nucleosome := code.Nucleosome.Val
+				if len(nucleosome.Genes) > 1 && rng.Float64() < 0.1 {
+					nucleosome.Delete(randomInt(rng, 0, len(nucleosome.Genes)))
+				} else if rng.Float64() < 0.1 {
+					nucleosome.Duplicate(randomInt(rng, 0, len(nucleosome.Genes)))
+				}
+			}),
+			RecombinationOpts: NewOption(RecombineOptions{
+				AlignGenes:        NewOption(NameAlignment),
+				MatchGenes:        NewOption(true),
+				UnmatchedSubunits: NewOption(InheritRandom),
+			}),
+			MaxIterations: NewOption(1000),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with NameAlignment failed with error: %v", err)
+		}
+		if n_iterations < 1000 && final_population[0].Score < 0.99 {
+			t.Errorf("Optimize with NameAlignment failed to meet fitness threshold: %f", final_population[0].Score)
+		}
+	})
+}
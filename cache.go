@@ -0,0 +1,112 @@
+package bluegenes
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A bounded least-recently-used cache of fitness scores keyed by the exact
+// Bases, structure, and names of each Code, so that neither hash collisions nor
+// renamed genetic material can return the wrong score. It is safe for
+// concurrent use and can be shared between runs of the same problem.
+type FitnessCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+}
+
+type fitnessCacheEntry struct {
+	key   string
+	score float64
+}
+
+// Creates a FitnessCache that holds at most size scores.
+func NewFitnessCache(size int) *FitnessCache {
+	return &FitnessCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *FitnessCache) get(key string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return 0.0, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return element.Value.(*fitnessCacheEntry).score, true
+}
+
+func (c *FitnessCache) put(key string, score float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size < 1 {
+		return
+	}
+	if element, ok := c.entries[key]; ok {
+		element.Value.(*fitnessCacheEntry).score = score
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&fitnessCacheEntry{key: key, score: score})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*fitnessCacheEntry).key)
+	}
+}
+
+// Returns the number of lookups that found a cached score.
+func (c *FitnessCache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Returns the number of lookups that did not find a cached score.
+func (c *FitnessCache) Misses() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.misses
+}
+
+// Returns the number of cached scores.
+func (c *FitnessCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Returns the cached scores from least to most recently used.
+func (c *FitnessCache) entriesByAge() []fitnessCacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]fitnessCacheEntry, 0, c.order.Len())
+	for element := c.order.Back(); element != nil; element = element.Prev() {
+		entries = append(entries, *element.Value.(*fitnessCacheEntry))
+	}
+	return entries
+}
+
+// Scores the Code using params.FitnessCache if it is set. Returns true if the
+// score came from the cache, i.e. MeasureFitness was not called.
+func measureFitness[T Ordered](params OptimizationParams[T], code Code[T]) (float64, bool) {
+	if !params.FitnessCache.Ok() || params.FitnessCache.Val == nil {
+		return params.MeasureFitness.Val(code), false
+	}
+	key := code.key()
+	if score, ok := params.FitnessCache.Val.get(key); ok {
+		return score, true
+	}
+	score := params.MeasureFitness.Val(code)
+	params.FitnessCache.Val.put(key, score)
+	return score, false
+}
@@ -0,0 +1,369 @@
+package bluegenes
+
+import (
+	"context"
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// The serialized form of the genetic material. These mirror the genetic types
+// without their mutexes so that they can be encoded with encoding/gob.
+type geneCheckpoint[T Ordered] struct {
+	Name  string
+	Bases []T
+}
+
+type nucleosomeCheckpoint[T Ordered] struct {
+	Name  string
+	Genes []geneCheckpoint[T]
+}
+
+type chromosomeCheckpoint[T Ordered] struct {
+	Name        string
+	Nucleosomes []nucleosomeCheckpoint[T]
+}
+
+type genomeCheckpoint[T Ordered] struct {
+	Name        string
+	Chromosomes []chromosomeCheckpoint[T]
+}
+
+type codeCheckpoint[T Ordered] struct {
+	Gene       *geneCheckpoint[T]
+	Nucleosome *nucleosomeCheckpoint[T]
+	Chromosome *chromosomeCheckpoint[T]
+	Genome     *genomeCheckpoint[T]
+	Strategy   []float64
+}
+
+type scoredCodeCheckpoint[T Ordered] struct {
+	Code      codeCheckpoint[T]
+	Score     float64
+	Violation float64
+	Age       int
+	Species   int
+}
+
+type speciesCheckpoint[T Ordered] struct {
+	ID             int
+	Representative codeCheckpoint[T]
+	Best           float64
+	Stagnant       int
+}
+
+type cacheEntryCheckpoint struct {
+	Key   string
+	Score float64
+}
+
+// Everything needed to continue an optimizationRun exactly where it left off.
+// RandState is empty if the run did not use a Rand, and Cache holds the
+// contents of params.FitnessCache from least to most recently used.
+type runCheckpoint[T Ordered] struct {
+	GenerationCount     int
+	Evaluations         int
+	CacheHits           int
+	PopulationSize      int
+	BestFitness         float64
+	StagnationBaseline  float64
+	StagnantGenerations int
+	Restarts            int
+	StopReason          StopReason
+	Population          []scoredCodeCheckpoint[T]
+	HallOfFame          []scoredCodeCheckpoint[T]
+	RandState           []uint64
+	MutationRate        float64
+	SuccessRatio        float64
+	Penalty             float64
+	Species             []speciesCheckpoint[T]
+	NextSpecies         int
+	Cache               []cacheEntryCheckpoint
+	Elapsed             time.Duration
+}
+
+func checkpointGene[T Ordered](g *Gene[T]) geneCheckpoint[T] {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	bases := make([]T, len(g.Bases))
+	copy(bases, g.Bases)
+	return geneCheckpoint[T]{Name: g.Name, Bases: bases}
+}
+
+func checkpointNucleosome[T Ordered](n *Nucleosome[T]) nucleosomeCheckpoint[T] {
+	n.Mu.RLock()
+	defer n.Mu.RUnlock()
+	cp := nucleosomeCheckpoint[T]{Name: n.Name}
+	for _, gene := range n.Genes {
+		cp.Genes = append(cp.Genes, checkpointGene(gene))
+	}
+	return cp
+}
+
+func checkpointChromosome[T Ordered](c *Chromosome[T]) chromosomeCheckpoint[T] {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	cp := chromosomeCheckpoint[T]{Name: c.Name}
+	for _, nucleosome := range c.Nucleosomes {
+		cp.Nucleosomes = append(cp.Nucleosomes, checkpointNucleosome(nucleosome))
+	}
+	return cp
+}
+
+func checkpointGenome[T Ordered](g *Genome[T]) genomeCheckpoint[T] {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	cp := genomeCheckpoint[T]{Name: g.Name}
+	for _, chromosome := range g.Chromosomes {
+		cp.Chromosomes = append(cp.Chromosomes, checkpointChromosome(chromosome))
+	}
+	return cp
+}
+
+func checkpointCode[T Ordered](code Code[T]) codeCheckpoint[T] {
+	cp := codeCheckpoint[T]{}
+	if code.Gene.Ok() {
+		gene := checkpointGene(code.Gene.Val)
+		cp.Gene = &gene
+	}
+	if code.Nucleosome.Ok() {
+		nucleosome := checkpointNucleosome(code.Nucleosome.Val)
+		cp.Nucleosome = &nucleosome
+	}
+	if code.Chromosome.Ok() {
+		chromosome := checkpointChromosome(code.Chromosome.Val)
+		cp.Chromosome = &chromosome
+	}
+	if code.Genome.Ok() {
+		genome := checkpointGenome(code.Genome.Val)
+		cp.Genome = &genome
+	}
+	if code.Strategy.Ok() {
+		cp.Strategy = append([]float64{}, code.Strategy.Val...)
+	}
+	return cp
+}
+
+func checkpointScoredCodes[T Ordered](scores []*ScoredCode[T]) []scoredCodeCheckpoint[T] {
+	cp := make([]scoredCodeCheckpoint[T], len(scores))
+	for i, score := range scores {
+		cp[i] = scoredCodeCheckpoint[T]{
+			Code: checkpointCode(score.Code), Score: score.Score, Violation: score.Violation,
+			Age: score.Age, Species: score.Species,
+		}
+	}
+	return cp
+}
+
+func (cp geneCheckpoint[T]) restore() *Gene[T] {
+	bases := make([]T, len(cp.Bases))
+	copy(bases, cp.Bases)
+	return &Gene[T]{Name: cp.Name, Bases: bases}
+}
+
+func (cp nucleosomeCheckpoint[T]) restore() *Nucleosome[T] {
+	n := &Nucleosome[T]{Name: cp.Name, Genes: []*Gene[T]{}}
+	for _, gene := range cp.Genes {
+		n.Genes = append(n.Genes, gene.restore())
+	}
+	return n
+}
+
+func (cp chromosomeCheckpoint[T]) restore() *Chromosome[T] {
+	c := &Chromosome[T]{Name: cp.Name, Nucleosomes: []*Nucleosome[T]{}}
+	for _, nucleosome := range cp.Nucleosomes {
+		c.Nucleosomes = append(c.Nucleosomes, nucleosome.restore())
+	}
+	return c
+}
+
+func (cp genomeCheckpoint[T]) restore() *Genome[T] {
+	g := &Genome[T]{Name: cp.Name, Chromosomes: []*Chromosome[T]{}}
+	for _, chromosome := range cp.Chromosomes {
+		g.Chromosomes = append(g.Chromosomes, chromosome.restore())
+	}
+	return g
+}
+
+func (cp codeCheckpoint[T]) restore() Code[T] {
+	code := Code[T]{}
+	if cp.Gene != nil {
+		code.Gene = NewOption(cp.Gene.restore())
+	}
+	if cp.Nucleosome != nil {
+		code.Nucleosome = NewOption(cp.Nucleosome.restore())
+	}
+	if cp.Chromosome != nil {
+		code.Chromosome = NewOption(cp.Chromosome.restore())
+	}
+	if cp.Genome != nil {
+		code.Genome = NewOption(cp.Genome.restore())
+	}
+	if cp.Strategy != nil {
+		code.Strategy = NewOption(append([]float64{}, cp.Strategy...))
+	}
+	return code
+}
+
+func restoreScoredCodes[T Ordered](cp []scoredCodeCheckpoint[T]) []*ScoredCode[T] {
+	scores := make([]*ScoredCode[T], len(cp))
+	for i, score := range cp {
+		scores[i] = &ScoredCode[T]{
+			Code: score.Code.restore(), Score: score.Score, Violation: score.Violation,
+			Age: score.Age, Species: score.Species,
+		}
+	}
+	return scores
+}
+
+func (r *optimizationRun[T]) checkpointSpecies() []speciesCheckpoint[T] {
+	cp := []speciesCheckpoint[T]{}
+	for _, id := range r.speciesIDs() {
+		record := r.species[id]
+		cp = append(cp, speciesCheckpoint[T]{
+			ID: id, Representative: checkpointCode(record.representative),
+			Best: record.best, Stagnant: record.stagnant,
+		})
+	}
+	return cp
+}
+
+func restoreSpecies[T Ordered](cp []speciesCheckpoint[T]) map[int]*species[T] {
+	records := map[int]*species[T]{}
+	for _, record := range cp {
+		records[record.ID] = &species[T]{
+			representative: record.Representative.restore(), best: record.Best, stagnant: record.Stagnant,
+		}
+	}
+	return records
+}
+
+func (r *optimizationRun[T]) checkpointCache() []cacheEntryCheckpoint {
+	cp := []cacheEntryCheckpoint{}
+	if !r.params.FitnessCache.Ok() || r.params.FitnessCache.Val == nil {
+		return cp
+	}
+	for _, entry := range r.params.FitnessCache.Val.entriesByAge() {
+		cp = append(cp, cacheEntryCheckpoint{Key: entry.key, Score: entry.score})
+	}
+	return cp
+}
+
+func (r *optimizationRun[T]) checkpoint() runCheckpoint[T] {
+	return runCheckpoint[T]{
+		GenerationCount:     r.generationCount,
+		Evaluations:         r.evaluations,
+		CacheHits:           r.cacheHits,
+		PopulationSize:      r.params.PopulationSize.Val,
+		BestFitness:         r.bestFitness,
+		StagnationBaseline:  r.stagnationBaseline,
+		StagnantGenerations: r.stagnantGenerations,
+		Restarts:            r.restarts,
+		StopReason:          r.stopReason,
+		Population:          checkpointScoredCodes(r.scores),
+		HallOfFame:          checkpointScoredCodes(r.hallOfFame),
+		RandState:           r.rng.state(),
+		MutationRate:        r.mutationRate,
+		SuccessRatio:        r.successRatio,
+		Penalty:             r.penalty,
+		Species:             r.checkpointSpecies(),
+		NextSpecies:         r.nextSpecies,
+		Cache:               r.checkpointCache(),
+		Elapsed:             time.Since(r.started),
+	}
+}
+
+// Writes a checkpoint to the writer returned by params.CheckpointWriter,
+// closing it afterwards if it is an io.Closer.
+func (r *optimizationRun[T]) writeCheckpoint() error {
+	w, err := r.params.CheckpointWriter.Val(r.generationCount)
+	if err != nil {
+		return err
+	}
+	err = gob.NewEncoder(w).Encode(r.checkpoint())
+	if closer, ok := w.(io.Closer); ok {
+		if close_err := closer.Close(); err == nil {
+			err = close_err
+		}
+	}
+	return err
+}
+
+// Returns true if a checkpoint is due after the current generation.
+func (r *optimizationRun[T]) checkpointDue() bool {
+	return r.params.CheckpointWriter.Ok() &&
+		r.generationCount%r.params.CheckpointInterval.Val == 0
+}
+
+// Sets up a run from a checkpoint without rescoring the population.
+func restoreOptimizationRun[T Ordered](params OptimizationParams[T], breed breeder[T],
+	cp runCheckpoint[T]) *optimizationRun[T] {
+	if len(cp.RandState) == 4 {
+		params.Rand = NewOption(newRandFromState(cp.RandState))
+	}
+	params.PopulationSize.Val = cp.PopulationSize
+	if params.FitnessCache.Ok() && params.FitnessCache.Val != nil {
+		for _, entry := range cp.Cache {
+			params.FitnessCache.Val.put(entry.Key, entry.Score)
+		}
+	}
+	r := &optimizationRun[T]{
+		params:              params,
+		breed:               breed,
+		rng:                 params.Rand.Val,
+		scores:              restoreScoredCodes(cp.Population),
+		generationCount:     cp.GenerationCount,
+		evaluations:         cp.Evaluations,
+		cacheHits:           cp.CacheHits,
+		bestFitness:         cp.BestFitness,
+		stagnationBaseline:  cp.StagnationBaseline,
+		stagnantGenerations: cp.StagnantGenerations,
+		restarts:            cp.Restarts,
+		hallOfFame:          restoreScoredCodes(cp.HallOfFame),
+		stopReason:          cp.StopReason,
+		started:             time.Now().Add(-cp.Elapsed),
+		mutationRate:        cp.MutationRate,
+		successRatio:        cp.SuccessRatio,
+		penalty:             cp.Penalty,
+		species:             restoreSpecies(cp.Species),
+		nextSpecies:         cp.NextSpecies,
+	}
+	for len(r.pool)+len(r.scores) < params.PopulationSize.Val {
+		r.putScoredCode(&ScoredCode[T]{})
+	}
+	return r
+}
+
+func ResumeOptimization[T Ordered](params OptimizationParams[T], checkpoint io.Reader) (int, []*ScoredCode[T], error) {
+	return ResumeOptimizationContext(context.Background(), params, checkpoint)
+}
+
+// Continues a run from a checkpoint written by params.CheckpointWriter. The
+// params must supply the same functions as the original run; InitialPopulation
+// is optional and defaults to the checkpointed population. The checkpointed
+// scores are added to params.FitnessCache if it is set, and the time already
+// spent counts against params.TimeLimit.
+func ResumeOptimizationContext[T Ordered](ctx context.Context, params OptimizationParams[T],
+	checkpoint io.Reader) (int, []*ScoredCode[T], error) {
+	cp := runCheckpoint[T]{}
+	if err := gob.NewDecoder(checkpoint).Decode(&cp); err != nil {
+		return 0, []*ScoredCode[T]{}, err
+	}
+	if len(cp.Population) < 1 {
+		return 0, []*ScoredCode[T]{}, anError{"checkpoint has an empty population"}
+	}
+	if !params.InitialPopulation.Ok() {
+		params.InitialPopulation = NewOption([]Code[T]{cp.Population[0].Code.restore()})
+	}
+	params, err := prepareOptimizationParams(params)
+	if err != nil {
+		return cp.GenerationCount, restoreScoredCodes(cp.Population), err
+	}
+	if params.TimeLimit.Ok() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.TimeLimit.Val-cp.Elapsed)
+		defer cancel()
+	}
+
+	return restoreOptimizationRun(params, breederFor(params), cp).run(ctx)
+}
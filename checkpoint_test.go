@@ -0,0 +1,186 @@
+package bluegenes
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+// Mutates deterministically so that runs depend only on the seeded Rand.
+func mutateCodeDeterministic(code *Code[int]) {
+	sum := 0
+	for _, base := range code.Gene.Val.Bases {
+		sum += base
+	}
+	for i := range code.Gene.Val.Bases {
+		code.Gene.Val.Bases[i] += (sum+i)%7 - 3
+	}
+}
+
+func checkpointParams(parallel_count int, checkpoints map[int]*bytes.Buffer) OptimizationParams[int] {
+	return OptimizationParams[int]{
+		InitialPopulation:  NewOption(geneInitialPopulation(10)),
+		MeasureFitness:     NewOption(measureCodeFitness),
+		Mutate:             NewOption(mutateCodeDeterministic),
+		MaxIterations:      NewOption(30),
+		PopulationSize:     NewOption(20),
+		FitnessTarget:      NewOption(2.0),
+		ParallelCount:      NewOption(parallel_count),
+		StagnationLimit:    NewOption(4),
+		HallOfFameSize:     NewOption(3),
+		MaxRestarts:        NewOption(1),
+		RestartOptions:     NewOption(MakeOptions[int]{NBases: NewOption(uint(5)), BaseFactory: NewOption(func() int { return 1 })}),
+		Rand:               NewOption(NewRand(99)),
+		CheckpointInterval: NewOption(10),
+		CheckpointWriter: NewOption(func(generation int) (io.Writer, error) {
+			checkpoints[generation] = &bytes.Buffer{}
+			return checkpoints[generation], nil
+		}),
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	t.Run("checkpointCode", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(2, 3, 4, 0, 5, "genome")
+		code := Code[int]{Gene: NewOption(firstGene()), Genome: NewOption(genome)}
+		restored := checkpointCode(code).restore()
+		expected := fmt.Sprint(code.Gene.Val.ToMap(), code.Genome.Val.ToMap())
+		observed := fmt.Sprint(restored.Gene.Val.ToMap(), restored.Genome.Val.ToMap())
+		if restored.Hash() != code.Hash() || observed != expected {
+			t.Errorf("checkpointCode failed to round trip: expected %v, observed %v", expected, observed)
+		}
+		if restored.Nucleosome.Ok() || restored.Chromosome.Ok() {
+			t.Error("checkpointCode failed: unset levels were restored")
+		}
+		if restored.Genome.Val.Chromosomes[0] == genome.Chromosomes[0] {
+			t.Error("checkpointCode failed: restored Code shares genetic material")
+		}
+	})
+
+	for _, parallel_count := range []int{1, 4} {
+		parallel_count := parallel_count
+		t.Run(fmt.Sprintf("ResumeOptimization/%d", parallel_count), func(t *testing.T) {
+			t.Parallel()
+			checkpoints := map[int]*bytes.Buffer{}
+			n_iterations, final_population, err := Optimize(checkpointParams(parallel_count, checkpoints))
+			if err != nil {
+				t.Fatalf("Optimize failed with error: %v", err)
+			}
+			if len(checkpoints) != 3 || checkpoints[10] == nil {
+				t.Fatalf("Optimize failed to write checkpoints every 10 generations: observed %d", len(checkpoints))
+			}
+
+			params := checkpointParams(parallel_count, map[int]*bytes.Buffer{})
+			params.InitialPopulation = Option[[]Code[int]]{}
+			params.Rand = Option[*Rand]{}
+			resumed_iterations, resumed_population, err := ResumeOptimization(params, checkpoints[10])
+			if err != nil {
+				t.Fatalf("ResumeOptimization failed with error: %v", err)
+			}
+			if resumed_iterations != n_iterations || len(resumed_population) != len(final_population) {
+				t.Fatalf("ResumeOptimization failed: expected %d iterations and %d members, observed %d and %d",
+					n_iterations, len(final_population), resumed_iterations, len(resumed_population))
+			}
+			for i := range final_population {
+				if final_population[i].Score != resumed_population[i].Score ||
+					final_population[i].Code.Hash() != resumed_population[i].Code.Hash() {
+					t.Fatalf("ResumeOptimization failed to continue identically at member %d", i)
+				}
+			}
+		})
+	}
+
+	t.Run("ResumeOptimization/FitnessCache", func(t *testing.T) {
+		t.Parallel()
+		checkpoints := map[int]*bytes.Buffer{}
+		params := checkpointParams(1, checkpoints)
+		params.FitnessCache = NewOption(NewFitnessCache(1000))
+		params.MaxEvaluations = NewOption(200)
+		report, err := OptimizeWithReport(params)
+		if err != nil {
+			t.Fatalf("OptimizeWithReport failed with error: %v", err)
+		}
+		if report.CacheHits == 0 || report.StopReason != StopMaxEvaluations || checkpoints[10] == nil {
+			t.Fatalf("OptimizeWithReport failed to set up the test: %d cache hits, stopped by %v, %d checkpoints",
+				report.CacheHits, report.StopReason, len(checkpoints))
+		}
+
+		params = checkpointParams(1, map[int]*bytes.Buffer{})
+		params.InitialPopulation = Option[[]Code[int]]{}
+		params.Rand = Option[*Rand]{}
+		params.FitnessCache = NewOption(NewFitnessCache(1000))
+		params.MaxEvaluations = NewOption(200)
+		resumed_iterations, resumed_population, err := ResumeOptimization(params, checkpoints[10])
+		if err != nil {
+			t.Fatalf("ResumeOptimization failed with error: %v", err)
+		}
+		if resumed_iterations != report.Generations || len(resumed_population) != len(report.Population) {
+			t.Fatalf("ResumeOptimization failed: expected %d iterations and %d members, observed %d and %d",
+				report.Generations, len(report.Population), resumed_iterations, len(resumed_population))
+		}
+		for i := range report.Population {
+			if report.Population[i].Score != resumed_population[i].Score ||
+				report.Population[i].Code.Hash() != resumed_population[i].Code.Hash() {
+				t.Fatalf("ResumeOptimization failed to continue identically at member %d", i)
+			}
+		}
+	})
+
+	t.Run("ResumeOptimization/TimeLimit", func(t *testing.T) {
+		t.Parallel()
+		checkpoints := map[int]*bytes.Buffer{}
+		if _, _, err := Optimize(checkpointParams(1, checkpoints)); err != nil {
+			t.Fatalf("Optimize failed with error: %v", err)
+		}
+		cp := runCheckpoint[int]{}
+		if err := gob.NewDecoder(checkpoints[10]).Decode(&cp); err != nil {
+			t.Fatalf("decoding the checkpoint failed with error: %v", err)
+		}
+		if cp.Elapsed <= 0 {
+			t.Errorf("checkpoint failed to record the elapsed time: observed %v", cp.Elapsed)
+		}
+		cp.Elapsed = time.Hour
+		buf := &bytes.Buffer{}
+		if err := gob.NewEncoder(buf).Encode(cp); err != nil {
+			t.Fatalf("encoding the checkpoint failed with error: %v", err)
+		}
+
+		params := checkpointParams(1, map[int]*bytes.Buffer{})
+		params.TimeLimit = NewOption(time.Minute)
+		n_iterations, _, err := ResumeOptimization(params, buf)
+		if err != context.DeadlineExceeded || n_iterations != 10 {
+			t.Errorf("ResumeOptimization failed to count the elapsed time against TimeLimit: observed %d iterations, %v",
+				n_iterations, err)
+		}
+	})
+
+	t.Run("CheckpointWriter/error", func(t *testing.T) {
+		t.Parallel()
+		params := checkpointParams(1, map[int]*bytes.Buffer{})
+		params.CheckpointWriter = NewOption(func(generation int) (io.Writer, error) {
+			return nil, anError{"disk full"}
+		})
+		n_iterations, final_population, err := Optimize(params)
+		if err == nil || err.Error() != "disk full" {
+			t.Errorf("Optimize failed to return the CheckpointWriter error: observed %v", err)
+		}
+		if n_iterations != 10 || len(final_population) == 0 {
+			t.Errorf("Optimize failed to return the population at the failed checkpoint: %d iterations, %d members",
+				n_iterations, len(final_population))
+		}
+	})
+
+	t.Run("ResumeOptimizationContext/invalid", func(t *testing.T) {
+		t.Parallel()
+		params := checkpointParams(1, map[int]*bytes.Buffer{})
+		_, _, err := ResumeOptimizationContext(context.Background(), params, bytes.NewBufferString("nope"))
+		if err == nil {
+			t.Error("ResumeOptimizationContext failed to return an error for an invalid checkpoint")
+		}
+	})
+}
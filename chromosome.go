@@ -1,8 +1,6 @@
 package bluegenes
 
 import (
-	"math"
-	"sort"
 	"sync"
 )
 
@@ -22,6 +20,13 @@ func (c *Chromosome[T]) Copy() *Chromosome[T] {
 	return &another
 }
 
+// Copies the Chromosome along with each of its Nucleosomes and their Genes.
+func (c *Chromosome[T]) DeepCopy() *Chromosome[T] {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	return &Chromosome[T]{Name: c.Name, Nucleosomes: copySubunits(c.Nucleosomes, (*Nucleosome[T]).DeepCopy)}
+}
+
 func (c *Chromosome[T]) Insert(index int, nucleosome *Nucleosome[T]) error {
 	c.Mu.Lock()
 	defer c.Mu.Unlock()
@@ -86,15 +91,15 @@ func (c *Chromosome[T]) Recombine(other *Chromosome[T], indices []int,
 	min_size, _ := min(len(c.Nucleosomes), len(other.Nucleosomes))
 	max_size, _ := max(len(c.Nucleosomes), len(other.Nucleosomes))
 
-	if len(indices) == 0 && min_size > 1 {
-		max_swaps := math.Ceil(math.Log(float64(min_size)))
-		swaps, _ := max(RandomInt(0, int(max_swaps)), 1)
-		idxSet := newSet[int]()
-		for i := 0; i < swaps; i++ {
-			idxSet.add(RandomInt(0, min_size))
+	aligned := len(indices) == 0 && options.AlignNucleosomes.Ok() &&
+		options.AlignNucleosomes.Val != PositionalAlignment
+	skip := len(indices) == 0 && options.ChromosomeCrossover.Val.skip(options.Rand.Val)
+	if len(indices) == 0 && !skip && !aligned {
+		var err error
+		indices, err = options.ChromosomeCrossover.Val.indices(options.Rand.Val, min_size)
+		if err != nil {
+			return err
 		}
-		indices = idxSet.toSlice()
-		sort.Ints(indices)
 	}
 	for _, i := range indices {
 		if 0 > i || i >= min_size {
@@ -103,18 +108,37 @@ func (c *Chromosome[T]) Recombine(other *Chromosome[T], indices []int,
 	}
 
 	name := c.Name
-	if name != other.Name {
+	if name != other.Name && !skip && !options.InheritNames.Val {
 		name_size, err := min(len(name), len(other.Name))
 		if err != nil {
 			return err
 		}
 		if name_size > 2 {
-			name_swap := RandomInt(1, name_size-1)
+			name_swap := randomInt(options.Rand.Val, 1, name_size-1)
 			name = c.Name[:name_swap] + other.Name[name_swap:]
 		}
 	}
 	child.Name = name
 
+	if aligned {
+		nucleosomes, err := recombineAligned(c.Nucleosomes, other.Nucleosomes,
+			func(nucleosome *Nucleosome[T]) string { return nucleosome.Name }, options.AlignNucleosomes.Val,
+			options.ChromosomeCrossover.Val, options, skip, (*Nucleosome[T]).DeepCopy,
+			func(dad, mom *Nucleosome[T]) (*Nucleosome[T], error) {
+				if (!options.RecombineNucleosomes.Ok() || options.RecombineNucleosomes.Val) &&
+					(!options.MatchNucleosomes.Val || dad.Name == mom.Name) {
+					nucleosome := &Nucleosome[T]{}
+					return nucleosome, dad.Recombine(mom, []int{}, nucleosome, options)
+				}
+				return dad.DeepCopy(), nil
+			})
+		if err != nil {
+			return err
+		}
+		child.Nucleosomes = nucleosomes
+		return nil
+	}
+
 	for len(child.Nucleosomes) < max_size {
 		child.Nucleosomes = append(child.Nucleosomes, &Nucleosome[T]{})
 	}
@@ -0,0 +1,158 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+// How Optimize ranks a population when OptimizationParams.Constraints is set.
+type ConstraintHandling int
+
+const (
+	FeasibilityRules ConstraintHandling = iota
+	AdaptivePenalty
+	StochasticRanking
+)
+
+func (h ConstraintHandling) String() string {
+	switch h {
+	case FeasibilityRules:
+		return "feasibility rules"
+	case AdaptivePenalty:
+		return "adaptive penalty"
+	case StochasticRanking:
+		return "stochastic ranking"
+	default:
+		return "unknown"
+	}
+}
+
+// Constraints on the Codes of an optimization. A Code is feasible if every
+// Inequality function returns a value <= 0 and every Equality function returns
+// a value within Tolerance (default 1e-4) of 0; the amounts by which it misses
+// are summed into ScoredCode.Violation. Handling chooses how the population is
+// ranked:
+//   - FeasibilityRules (Deb): feasible Codes by Score, then infeasible Codes
+//     by ascending Violation.
+//   - AdaptivePenalty: by Score worsened by penalty*Violation, where the
+//     penalty starts at Penalty (default 1) and is multiplied by PenaltyFactor
+//     (default 2) after each generation whose best member is infeasible and
+//     divided by it after each generation whose best member is feasible.
+//   - StochasticRanking (Runarsson and Yao): a bubble sort that compares
+//     adjacent Codes by Score if both are feasible or with probability
+//     RankingProbability (default 0.45), and by Violation otherwise.
+type Constraints[T Ordered] struct {
+	Inequality         []func(Code[T]) float64
+	Equality           []func(Code[T]) float64
+	Tolerance          float64
+	Handling           ConstraintHandling
+	Penalty            float64
+	PenaltyFactor      float64
+	RankingProbability float64
+}
+
+// Returns the total amount by which the Code violates the Constraints, which
+// is 0 if it is feasible.
+func (c Constraints[T]) violation(code Code[T]) float64 {
+	tolerance := c.Tolerance
+	if tolerance <= 0.0 {
+		tolerance = 1e-4
+	}
+	total := 0.0
+	for _, constraint := range c.Inequality {
+		if value := constraint(code); value > 0.0 {
+			total += value
+		}
+	}
+	for _, constraint := range c.Equality {
+		if value := math.Abs(constraint(code)); value > tolerance {
+			total += value - tolerance
+		}
+	}
+	return total
+}
+
+// Returns the initial penalty coefficient for AdaptivePenalty.
+func (c Constraints[T]) initialPenalty() float64 {
+	if c.Penalty <= 0.0 {
+		return 1.0
+	}
+	return c.Penalty
+}
+
+// Returns the penalty coefficient for the next generation given the ranked
+// population of the last one.
+func (c Constraints[T]) adaptPenalty(penalty float64, scores []*ScoredCode[T]) float64 {
+	if c.Handling != AdaptivePenalty || len(scores) == 0 {
+		return penalty
+	}
+	factor := c.PenaltyFactor
+	if factor <= 1.0 {
+		factor = 2.0
+	}
+	if scores[0].Violation > 0.0 {
+		return penalty * factor
+	}
+	return penalty / factor
+}
+
+// Returns true if a is better than b according to Deb's feasibility rules.
+func feasiblyBetter[T Ordered](a, b *ScoredCode[T], direction Direction) bool {
+	if a.Violation != b.Violation {
+		return a.Violation < b.Violation
+	}
+	return direction.better(a.Score, b.Score)
+}
+
+// Sorts the population according to Deb's feasibility rules.
+func sortFeasible[T Ordered](scores []*ScoredCode[T], direction Direction) {
+	sort.SliceStable(scores, func(i, j int) bool {
+		return feasiblyBetter(scores[i], scores[j], direction)
+	})
+}
+
+// Orders the population best first according to Handling.
+func (c Constraints[T]) rank(scores []*ScoredCode[T], penalty float64, direction Direction, rng *Rand) {
+	switch c.Handling {
+	case AdaptivePenalty:
+		penalized := func(score *ScoredCode[T]) float64 {
+			return direction.fitness(score.Score) - penalty*score.Violation
+		}
+		sort.SliceStable(scores, func(i, j int) bool {
+			return penalized(scores[i]) > penalized(scores[j])
+		})
+	case StochasticRanking:
+		probability := c.RankingProbability
+		if probability <= 0.0 {
+			probability = 0.45
+		}
+		for sweep := 0; sweep < len(scores); sweep++ {
+			swapped := false
+			for j := 0; j+1 < len(scores); j++ {
+				a, b := scores[j], scores[j+1]
+				by_score := (a.Violation == 0.0 && b.Violation == 0.0) || rng.Float64() < probability
+				if (by_score && direction.better(b.Score, a.Score)) || (!by_score && a.Violation > b.Violation) {
+					scores[j], scores[j+1] = b, a
+					swapped = true
+				}
+			}
+			if !swapped {
+				break
+			}
+		}
+	default:
+		sortFeasible(scores, direction)
+	}
+}
+
+// Returns the best Score of any feasible member of the population, or the
+// worst possible Score (-Inf or +Inf) if there is none.
+func bestFeasibleScore[T Ordered](scores []*ScoredCode[T], direction Direction) float64 {
+	best := direction.worst()
+	for _, score := range scores {
+		if score.Violation == 0.0 && direction.better(score.Score, best) {
+			best = score.Score
+		}
+	}
+	return best
+}
@@ -0,0 +1,159 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func scoredCodes(pairs ...[2]float64) []*ScoredCode[int] {
+	scores := []*ScoredCode[int]{}
+	for i, pair := range pairs {
+		gene := &Gene[int]{Name: "g", Bases: []int{i}}
+		scores = append(scores, &ScoredCode[int]{Code: Code[int]{Gene: NewOption(gene)}, Score: pair[0], Violation: pair[1]})
+	}
+	return scores
+}
+
+func rankedBases(scores []*ScoredCode[int]) []int {
+	bases := []int{}
+	for _, score := range scores {
+		bases = append(bases, score.Code.Gene.Val.Bases[0])
+	}
+	return bases
+}
+
+func sumConstraint(code Code[float64]) float64 {
+	total := 0.0
+	for _, base := range code.Gene.Val.Bases {
+		total += base
+	}
+	return total - 2.0
+}
+
+func TestConstraints(t *testing.T) {
+	t.Run("violation", func(t *testing.T) {
+		t.Parallel()
+		constraints := Constraints[float64]{
+			Inequality: []func(Code[float64]) float64{sumConstraint},
+			Equality: []func(Code[float64]) float64{func(code Code[float64]) float64 {
+				return code.Gene.Val.Bases[0] - code.Gene.Val.Bases[1]
+			}},
+			Tolerance: 0.5,
+		}
+		expected := map[[2]float64]float64{{0.0, 0.0}: 0.0, {1.0, 0.75}: 0.0, {2.0, 0.5}: 1.5, {3.0, 1.0}: 3.5}
+		for bases, violation := range expected {
+			code := Code[float64]{Gene: NewOption(&Gene[float64]{Bases: []float64{bases[0], bases[1]}})}
+			if observed := constraints.violation(code); math.Abs(observed-violation) > 1e-9 {
+				t.Errorf("Constraints.violation failed for %v: expected %f, observed %f", bases, violation, observed)
+			}
+		}
+	})
+
+	t.Run("FeasibilityRules", func(t *testing.T) {
+		t.Parallel()
+		scores := scoredCodes([2]float64{0.9, 2.0}, [2]float64{0.1, 0.0}, [2]float64{0.5, 1.0}, [2]float64{0.3, 0.0})
+		Constraints[int]{}.rank(scores, 1.0, Maximize, nil)
+		if bases := rankedBases(scores); !equal(bases, []int{3, 1, 2, 0}) {
+			t.Errorf("FeasibilityRules failed: expected [3 1 2 0], observed %v", bases)
+		}
+		if best := bestFeasibleScore(scores, Maximize); best != 0.3 {
+			t.Errorf("bestFeasibleScore failed: expected 0.3, observed %f", best)
+		}
+		if best := bestFeasibleScore(scores[2:], Maximize); !math.IsInf(best, -1) {
+			t.Errorf("bestFeasibleScore failed: expected -Inf, observed %f", best)
+		}
+	})
+
+	t.Run("AdaptivePenalty", func(t *testing.T) {
+		t.Parallel()
+		constraints := Constraints[int]{Handling: AdaptivePenalty, PenaltyFactor: 4.0}
+		scores := scoredCodes([2]float64{0.9, 0.2}, [2]float64{0.5, 0.0})
+		constraints.rank(scores, 1.0, Maximize, nil)
+		if bases := rankedBases(scores); !equal(bases, []int{0, 1}) {
+			t.Errorf("AdaptivePenalty failed with a small penalty: expected [0 1], observed %v", bases)
+		}
+		if penalty := constraints.adaptPenalty(1.0, scores); penalty != 4.0 {
+			t.Errorf("AdaptivePenalty failed to increase the penalty: observed %f", penalty)
+		}
+		constraints.rank(scores, 4.0, Maximize, nil)
+		if bases := rankedBases(scores); !equal(bases, []int{1, 0}) {
+			t.Errorf("AdaptivePenalty failed with a large penalty: expected [1 0], observed %v", bases)
+		}
+		if penalty := constraints.adaptPenalty(4.0, scores); penalty != 1.0 {
+			t.Errorf("AdaptivePenalty failed to decrease the penalty: observed %f", penalty)
+		}
+		if penalty := (Constraints[int]{}).adaptPenalty(4.0, scores); penalty != 4.0 {
+			t.Errorf("FeasibilityRules changed the penalty: observed %f", penalty)
+		}
+	})
+
+	t.Run("StochasticRanking", func(t *testing.T) {
+		t.Parallel()
+		constraints := Constraints[int]{Handling: StochasticRanking, RankingProbability: 1e-12}
+		scores := scoredCodes([2]float64{0.9, 2.0}, [2]float64{0.1, 0.0}, [2]float64{0.5, 1.0}, [2]float64{0.3, 0.0})
+		constraints.rank(scores, 1.0, Maximize, NewRand(3))
+		if bases := rankedBases(scores); !equal(bases, []int{3, 1, 2, 0}) {
+			t.Errorf("StochasticRanking failed: expected [3 1 2 0], observed %v", bases)
+		}
+		constraints.RankingProbability = 1.0
+		constraints.rank(scores, 1.0, Maximize, NewRand(3))
+		if bases := rankedBases(scores); !equal(bases, []int{0, 2, 3, 1}) {
+			t.Errorf("StochasticRanking failed to rank by Score: expected [0 2 3 1], observed %v", bases)
+		}
+	})
+
+	t.Run("TournamentSelector", func(t *testing.T) {
+		t.Parallel()
+		scores := scoredCodes([2]float64{0.9, 1.0}, [2]float64{0.1, 0.0})
+		selected := TournamentSelector[int]{K: 20}.Select(scores, 10, NewRand(5))
+		for _, code := range selected {
+			if code.Gene.Val.Bases[0] != 1 {
+				t.Fatalf("TournamentSelector failed to prefer the feasible Code")
+			}
+		}
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		t.Parallel()
+		scores := scoredCodes([2]float64{0.9, 1.5})
+		restored := restoreScoredCodes(checkpointScoredCodes(scores))
+		if restored[0].Violation != 1.5 {
+			t.Errorf("checkpoint failed to restore Violation: observed %f", restored[0].Violation)
+		}
+	})
+
+	handlings := []ConstraintHandling{FeasibilityRules, AdaptivePenalty, StochasticRanking}
+	for _, handling := range handlings {
+		handling := handling
+		t.Run("Optimize/"+handling.String(), func(t *testing.T) {
+			t.Parallel()
+			rng := NewRand(59)
+			report, err := OptimizeWithReport(OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+				MeasureFitness:    NewOption(measureSphereFitness),
+				Mutate: NewOption(func(code *Code[float64]) {
+					GaussianMutation[float64](0.5, 0.3)(code.Gene.Val, rng)
+				}),
+				Constraints: NewOption(Constraints[float64]{
+					Inequality: []func(Code[float64]) float64{sumConstraint},
+					Handling:   handling,
+				}),
+				MaxIterations: NewOption(300),
+				Rand:          NewOption(rng),
+			})
+			if err != nil {
+				t.Fatalf("Optimize with %s failed with error: %v", handling, err)
+			}
+			best := report.Population[0]
+			if handling == FeasibilityRules && best.Violation != 0.0 {
+				t.Errorf("Optimize with %s failed: best Code is infeasible (%f)", handling, best.Violation)
+			}
+			if score := bestFeasibleScore(report.Population, Maximize); score < 0.45 || score > 0.5+1e-9 {
+				t.Errorf("Optimize with %s failed to approach the constrained optimum 0.5: observed %f", handling, score)
+			}
+			if last := report.History[len(report.History)-1]; last.FeasibleRatio == 0.0 {
+				t.Errorf("Optimize with %s failed to report FeasibleRatio", handling)
+			}
+		})
+	}
+}
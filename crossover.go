@@ -0,0 +1,448 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+// The method used to combine the Bases of two Genes in Gene.Recombine.
+type CrossoverMethod int
+
+const (
+	// Swaps runs of bases between the parents at random indices (default).
+	NPointCrossover CrossoverMethod = iota
+	// Simulated binary crossover (SBX) for Float genes.
+	SimulatedBinaryCrossover
+	// Blend crossover (BLX-alpha) for Float genes.
+	BlendCrossover
+	// Whole arithmetic crossover for Float genes.
+	ArithmeticCrossover
+	// Extended line crossover for Float genes.
+	LineCrossover
+	// Partially mapped crossover (PMX) for permutations.
+	PartiallyMappedCrossover
+	// Order crossover (OX1) for permutations.
+	OrderCrossover
+	// Cycle crossover (CX) for permutations.
+	CycleCrossover
+	// Edge recombination crossover for permutations.
+	EdgeRecombinationCrossover
+	// Takes each subunit from either parent with CrossoverOptions.SwapProbability.
+	UniformCrossover
+	// Swaps a single contiguous segment of subunits between the parents.
+	SegmentCrossover
+)
+
+// Configures how the subunits at one level of genetic code (the Bases of a
+// Gene, the Genes of a Nucleosome, etc) are crossed over by Recombine.
+type CrossoverOptions struct {
+	Method          Option[CrossoverMethod]
+	Points          Option[int]
+	SwapProbability Option[float64]
+	SegmentLength   Option[int]
+	Rate            Option[float64]
+}
+
+// Returns true if the method only applies to Float genes.
+func (m CrossoverMethod) realValued() bool {
+	return m == SimulatedBinaryCrossover || m == BlendCrossover ||
+		m == ArithmeticCrossover || m == LineCrossover
+}
+
+// Returns true if the method only applies to permutations.
+func (m CrossoverMethod) permutation() bool {
+	return m == PartiallyMappedCrossover || m == OrderCrossover ||
+		m == CycleCrossover || m == EdgeRecombinationCrossover
+}
+
+// Returns true if the method can be used at every level of genetic code.
+func (m CrossoverMethod) structural() bool {
+	return m == NPointCrossover || m == UniformCrossover || m == SegmentCrossover
+}
+
+// Returns the CrossoverMethod used for the Bases of a Gene: GeneCrossover.Method
+// if it is set, otherwise BaseCrossover.
+func (o RecombineOptions) baseCrossover() CrossoverMethod {
+	if o.GeneCrossover.Ok() && o.GeneCrossover.Val.Method.Ok() {
+		return o.GeneCrossover.Val.Method.Val
+	}
+	return o.BaseCrossover.Val
+}
+
+// Returns true if the crossover should be skipped, i.e. the child should be a
+// copy of the first parent, according to Rate.
+func (o CrossoverOptions) skip(rng *Rand) bool {
+	return o.Rate.Ok() && rng.Float64() >= o.Rate.Val
+}
+
+// Returns the sorted indices at which Recombine switches between the parents
+// for a level with size subunits in both parents.
+func (o CrossoverOptions) indices(rng *Rand, size int) ([]int, error) {
+	if size < 2 {
+		return nil, nil
+	}
+	indices := []int{}
+	switch o.Method.Val {
+	case NPointCrossover:
+		if !o.Points.Ok() {
+			max_swaps := math.Ceil(math.Log(float64(size)))
+			swaps, _ := max(randomInt(rng, 0, int(max_swaps)), 1)
+			idxSet := newSet[int]()
+			for i := 0; i < swaps; i++ {
+				idxSet.add(randomInt(rng, 0, size))
+			}
+			indices = idxSet.toSlice()
+			break
+		}
+		points, _ := min(o.Points.Val, size-1)
+		cuts := rng.Perm(size - 1)
+		for i := 0; i < points; i++ {
+			indices = append(indices, cuts[i]+1)
+		}
+	case UniformCrossover:
+		probability := 0.5
+		if o.SwapProbability.Ok() {
+			probability = o.SwapProbability.Val
+		}
+		from_other := false
+		for i := 0; i < size; i++ {
+			if (rng.Float64() < probability) != from_other {
+				indices = append(indices, i)
+				from_other = !from_other
+			}
+		}
+	case SegmentCrossover:
+		length := randomInt(rng, 1, size)
+		if o.SegmentLength.Ok() {
+			length, _ = min(o.SegmentLength.Val, size)
+			length, _ = max(length, 1)
+		}
+		start := randomInt(rng, 0, size-length+1)
+		indices = append(indices, start)
+		if start+length < size {
+			indices = append(indices, start+length)
+		}
+	default:
+		return nil, anError{"crossover method cannot be used for this level"}
+	}
+	sort.Ints(indices)
+	return indices, nil
+}
+
+// Returns the Bounds for the base at index i: options.BaseBounds[i], or the
+// last of them if there are fewer BaseBounds than bases.
+func baseBounds(options RecombineOptions, i int) (Bounds[float64], bool) {
+	if !options.BaseBounds.Ok() || len(options.BaseBounds.Val) == 0 {
+		return Bounds[float64]{}, false
+	}
+	bounds := options.BaseBounds.Val
+	if i >= len(bounds) {
+		return bounds[len(bounds)-1], true
+	}
+	return bounds[i], true
+}
+
+func toFloat64s[T Ordered](bases []T) ([]float64, bool) {
+	switch b := any(bases).(type) {
+	case []float64:
+		return b, true
+	case []float32:
+		floats := make([]float64, len(b))
+		for i, base := range b {
+			floats[i] = float64(base)
+		}
+		return floats, true
+	default:
+		return nil, false
+	}
+}
+
+func fromFloat64s[T Ordered](floats []float64) []T {
+	var zero T
+	switch any(zero).(type) {
+	case float32:
+		bases := make([]float32, len(floats))
+		for i, f := range floats {
+			bases[i] = float32(f)
+		}
+		return any(bases).([]T)
+	default:
+		return any(floats).([]T)
+	}
+}
+
+// Creates the bases of a child from the bases of two parents using one of the
+// real-valued CrossoverMethods. Bases past the end of the shorter parent are
+// copied from the longer one.
+func realValuedCrossover[T Ordered](dad, mom []T, options RecombineOptions) ([]T, error) {
+	x1, ok := toFloat64s(dad)
+	if !ok {
+		return nil, anError{"real-valued crossover requires Float bases"}
+	}
+	x2, _ := toFloat64s(mom)
+	rng := options.Rand.Val
+	min_size, _ := min(len(x1), len(x2))
+	child := make([]float64, len(x1))
+	copy(child, x1)
+	if len(x2) > len(x1) {
+		child = append(child, x2[len(x1):]...)
+	}
+
+	alpha := 0.5
+	method := options.baseCrossover()
+	if method == LineCrossover {
+		alpha = 0.25
+	}
+	if options.BlendAlpha.Ok() {
+		alpha = options.BlendAlpha.Val
+	}
+	// the whole-vector methods use a single weight for every base
+	weight := rng.Float64()
+	if method == ArithmeticCrossover && options.ArithmeticWeight.Ok() {
+		weight = options.ArithmeticWeight.Val
+	}
+	if method == LineCrossover {
+		weight = -alpha + rng.Float64()*(1.0+2.0*alpha)
+	}
+
+	for i := 0; i < min_size; i++ {
+		switch method {
+		case SimulatedBinaryCrossover:
+			eta := 15.0
+			if options.CrossoverEta.Ok() {
+				eta = options.CrossoverEta.Val
+			}
+			u := rng.Float64()
+			var beta float64
+			if u <= 0.5 {
+				beta = math.Pow(2.0*u, 1.0/(eta+1.0))
+			} else {
+				beta = math.Pow(1.0/(2.0*(1.0-u)), 1.0/(eta+1.0))
+			}
+			if rng.Float64() < 0.5 {
+				beta = -beta
+			}
+			child[i] = 0.5 * ((1.0+beta)*x1[i] + (1.0-beta)*x2[i])
+		case BlendCrossover:
+			lower, upper := math.Min(x1[i], x2[i]), math.Max(x1[i], x2[i])
+			spread := alpha * (upper - lower)
+			child[i] = lower - spread + rng.Float64()*(upper-lower+2.0*spread)
+		case ArithmeticCrossover, LineCrossover:
+			child[i] = x1[i] + weight*(x2[i]-x1[i])
+		}
+		if bounds, ok := baseBounds(options, i); ok {
+			child[i] = bounds.Clamp(child[i])
+		}
+	}
+
+	return fromFloat64s[T](child), nil
+}
+
+// Returns true if bases contains exactly the items of reference in any order,
+// and the items of reference are unique.
+func IsPermutation[T Ordered](bases []T, reference []T) bool {
+	if len(bases) != len(reference) {
+		return false
+	}
+	counts := make(map[T]int, len(reference))
+	for _, item := range reference {
+		counts[item]++
+		if counts[item] > 1 {
+			return false
+		}
+	}
+	for _, item := range bases {
+		counts[item]--
+		if counts[item] < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Creates the bases of a child from two parents that are permutations of each
+// other using one of the permutation CrossoverMethods.
+func permutationCrossover[T Ordered](dad, mom []T, options RecombineOptions) ([]T, error) {
+	if !IsPermutation(mom, dad) {
+		return nil, anError{"permutation crossover requires parents that are permutations of each other"}
+	}
+	rng := options.Rand.Val
+	if len(dad) < 2 {
+		child := make([]T, len(dad))
+		copy(child, dad)
+		return child, nil
+	}
+	switch options.baseCrossover() {
+	case PartiallyMappedCrossover:
+		start, stop := randomCutPoints(rng, len(dad))
+		return partiallyMappedCrossover(dad, mom, start, stop), nil
+	case OrderCrossover:
+		start, stop := randomCutPoints(rng, len(dad))
+		return orderCrossover(dad, mom, start, stop), nil
+	case CycleCrossover:
+		if rng.Float64() < 0.5 {
+			return cycleCrossover(mom, dad), nil
+		}
+		return cycleCrossover(dad, mom), nil
+	default:
+		return edgeRecombinationCrossover(dad, mom, rng), nil
+	}
+}
+
+// Returns random indices start <= stop.
+func randomCutPoints(rng *Rand, size int) (int, int) {
+	start, stop := randomInt(rng, 0, size), randomInt(rng, 0, size)
+	if start > stop {
+		start, stop = stop, start
+	}
+	return start, stop
+}
+
+func indexMap[T Ordered](items []T) map[T]int {
+	indices := make(map[T]int, len(items))
+	for i, item := range items {
+		indices[item] = i
+	}
+	return indices
+}
+
+// Copies dad[start:stop+1] into the child, places the displaced items of the
+// same segment of mom by following the mapping between the parents, and
+// fills the rest from mom.
+func partiallyMappedCrossover[T Ordered](dad, mom []T, start, stop int) []T {
+	child := make([]T, len(dad))
+	filled := make([]bool, len(dad))
+	in_segment := newSet[T]()
+	for i := start; i <= stop; i++ {
+		child[i] = dad[i]
+		filled[i] = true
+		in_segment.add(dad[i])
+	}
+	mom_indices := indexMap(mom)
+	for i := start; i <= stop; i++ {
+		if in_segment.contains(mom[i]) {
+			continue
+		}
+		position := i
+		for position >= start && position <= stop {
+			position = mom_indices[dad[position]]
+		}
+		child[position] = mom[i]
+		filled[position] = true
+	}
+	for i := range child {
+		if !filled[i] {
+			child[i] = mom[i]
+		}
+	}
+	return child
+}
+
+// Copies dad[start:stop+1] into the child and fills the remaining positions,
+// starting after the segment and wrapping around, with the remaining items in
+// the order they appear in mom after the segment.
+func orderCrossover[T Ordered](dad, mom []T, start, stop int) []T {
+	size := len(dad)
+	child := make([]T, size)
+	in_segment := newSet[T]()
+	for i := start; i <= stop; i++ {
+		child[i] = dad[i]
+		in_segment.add(dad[i])
+	}
+	position := (stop + 1) % size
+	for i := 0; i < size; i++ {
+		item := mom[(stop+1+i)%size]
+		if in_segment.contains(item) {
+			continue
+		}
+		child[position] = item
+		position = (position + 1) % size
+	}
+	return child
+}
+
+// Takes the items of each cycle of positions alternately from dad and mom, so
+// that every item keeps the position it has in one of the parents.
+func cycleCrossover[T Ordered](dad, mom []T) []T {
+	child := make([]T, len(dad))
+	visited := make([]bool, len(dad))
+	dad_indices := indexMap(dad)
+	cycle := 0
+	for start := range dad {
+		if visited[start] {
+			continue
+		}
+		source := dad
+		if cycle%2 == 1 {
+			source = mom
+		}
+		for i := start; !visited[i]; i = dad_indices[mom[i]] {
+			visited[i] = true
+			child[i] = source[i]
+		}
+		cycle++
+	}
+	return child
+}
+
+// Builds a tour from the union of the adjacencies of both parents, always
+// moving to the neighbor with the fewest remaining neighbors (ties broken at
+// random) and to a random unvisited item when there are no neighbors left.
+func edgeRecombinationCrossover[T Ordered](dad, mom []T, rng *Rand) []T {
+	size := len(dad)
+	neighbors := make(map[T][]T, size)
+	for _, parent := range [][]T{dad, mom} {
+		for i, item := range parent {
+			for _, neighbor := range []T{parent[(i+size-1)%size], parent[(i+1)%size]} {
+				if neighbor != item && !contains(neighbors[item], neighbor) {
+					neighbors[item] = append(neighbors[item], neighbor)
+				}
+			}
+		}
+	}
+
+	child := make([]T, 0, size)
+	visited := newSet[T]()
+	current := dad[0]
+	if rng.Float64() < 0.5 {
+		current = mom[0]
+	}
+	for len(child) < size {
+		child = append(child, current)
+		visited.add(current)
+		for item, adjacent := range neighbors {
+			neighbors[item] = removeItem(adjacent, current)
+		}
+		if len(child) == size {
+			break
+		}
+
+		candidates := []T{}
+		fewest := size + 1
+		for _, neighbor := range neighbors[current] {
+			if n := len(neighbors[neighbor]); n < fewest {
+				candidates, fewest = []T{neighbor}, n
+			} else if n == fewest {
+				candidates = append(candidates, neighbor)
+			}
+		}
+		if len(candidates) == 0 {
+			for _, item := range dad {
+				if !visited.contains(item) {
+					candidates = append(candidates, item)
+				}
+			}
+		}
+		current = candidates[randomInt(rng, 0, len(candidates))]
+	}
+	return child
+}
+
+func removeItem[T Ordered](items []T, item T) []T {
+	for i, other := range items {
+		if other == item {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
@@ -0,0 +1,395 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCrossover(t *testing.T) {
+	methods := map[string]CrossoverMethod{
+		"SimulatedBinaryCrossover": SimulatedBinaryCrossover,
+		"BlendCrossover":           BlendCrossover,
+		"ArithmeticCrossover":      ArithmeticCrossover,
+		"LineCrossover":            LineCrossover,
+	}
+	for name, method := range methods {
+		name, method := name, method
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dad := &Gene[float64]{Name: "dad", Bases: []float64{0.0, 1.0, -1.0, 0.5}}
+			mom := &Gene[float64]{Name: "mom", Bases: []float64{1.0, 1.0, 1.0, 0.9, 7.0}}
+			options := RecombineOptions{
+				BaseCrossover: NewOption(method),
+				BaseBounds:    NewOption([]Bounds[float64]{{0.0, 1.0}, {0.0, 0.5}, {-1.0, 1.0}}),
+			}
+			child := &Gene[float64]{}
+			changed := false
+			for i := 0; i < 100; i++ {
+				if err := dad.Recombine(mom, []int{}, child, options); err != nil {
+					t.Fatalf("%s failed with error: %v", name, err)
+				}
+				if len(child.Bases) != 5 || child.Bases[4] != 7.0 {
+					t.Fatalf("%s failed to copy the extra bases: observed %v", name, child.Bases)
+				}
+				if child.Bases[0] < 0.0 || child.Bases[0] > 1.0 || child.Bases[1] != 0.5 ||
+					child.Bases[2] < -1.0 || child.Bases[2] > 1.0 || child.Bases[3] > 1.0 {
+					t.Fatalf("%s failed to respect BaseBounds: observed %v", name, child.Bases)
+				}
+				changed = changed || (child.Bases[0] != 0.0 && child.Bases[0] != 1.0)
+			}
+			if !changed {
+				t.Errorf("%s failed to produce new values", name)
+			}
+		})
+	}
+
+	t.Run("parameters", func(t *testing.T) {
+		t.Parallel()
+		dad := &Gene[float64]{Bases: []float64{0.0, 2.0}}
+		mom := &Gene[float64]{Bases: []float64{1.0, 4.0}}
+		child := &Gene[float64]{}
+
+		dad.Recombine(mom, []int{}, child, RecombineOptions{
+			BaseCrossover:    NewOption(ArithmeticCrossover),
+			ArithmeticWeight: NewOption(0.5),
+		})
+		if !equal(child.Bases, []float64{0.5, 3.0}) {
+			t.Errorf("ArithmeticCrossover failed: expected [0.5 3], observed %v", child.Bases)
+		}
+
+		for i := 0; i < 100; i++ {
+			dad.Recombine(mom, []int{}, child, RecombineOptions{
+				BaseCrossover: NewOption(BlendCrossover), BlendAlpha: NewOption(0.0),
+			})
+			if child.Bases[0] < 0.0 || child.Bases[0] > 1.0 || child.Bases[1] < 2.0 || child.Bases[1] > 4.0 {
+				t.Fatalf("BlendCrossover failed with alpha 0: observed %v", child.Bases)
+			}
+
+			dad.Recombine(mom, []int{}, child, RecombineOptions{
+				BaseCrossover: NewOption(LineCrossover), BlendAlpha: NewOption(0.0),
+			})
+			if math.Abs((child.Bases[1]-2.0)/2.0-child.Bases[0]) > 1e-9 {
+				t.Fatalf("LineCrossover failed to keep the child on the line: observed %v", child.Bases)
+			}
+
+			dad.Recombine(mom, []int{}, child, RecombineOptions{
+				BaseCrossover: NewOption(SimulatedBinaryCrossover), CrossoverEta: NewOption(1e9),
+			})
+			if math.Abs(child.Bases[0]-0.5) < 0.49 || math.Abs(child.Bases[1]-3.0) < 0.99 {
+				t.Fatalf("SimulatedBinaryCrossover failed with large eta: observed %v", child.Bases)
+			}
+		}
+	})
+
+	t.Run("types", func(t *testing.T) {
+		t.Parallel()
+		dad := &Gene[float32]{Bases: []float32{0.0, 2.0}}
+		mom := &Gene[float32]{Bases: []float32{1.0, 4.0}}
+		child := &Gene[float32]{}
+		err := dad.Recombine(mom, []int{}, child, RecombineOptions{
+			BaseCrossover: NewOption(ArithmeticCrossover), ArithmeticWeight: NewOption(0.5),
+		})
+		if err != nil || !equal(child.Bases, []float32{0.5, 3.0}) {
+			t.Errorf("ArithmeticCrossover failed for float32: observed %v (%v)", child.Bases, err)
+		}
+
+		ints := &Gene[int]{Bases: []int{1, 2}}
+		err = ints.Recombine(ints, []int{}, &Gene[int]{}, RecombineOptions{
+			BaseCrossover: NewOption(BlendCrossover),
+		})
+		if err == nil {
+			t.Error("BlendCrossover failed to return an error for int bases")
+		}
+
+		_, _, err = Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+			RecombinationOpts: NewOption(RecombineOptions{BaseCrossover: NewOption(LineCrossover)}),
+		})
+		if err == nil {
+			t.Error("Optimize failed to reject a real-valued BaseCrossover for int bases")
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(11)
+		bounds := Bounds[float64]{-5.0, 5.0}
+		initial_population := []Code[float64]{}
+		for i := 0; i < 20; i++ {
+			gene, _ := MakeGene(MakeOptions[float64]{
+				NBases:      NewOption(uint(4)),
+				BaseFactory: NewOption(func() float64 { return -5.0 + 10.0*rng.Float64() }),
+				Rand:        NewOption(rng),
+			})
+			initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+		}
+		sphere := func(code Code[float64]) float64 {
+			total := 0.0
+			for _, base := range code.Gene.Val.Bases {
+				total += (base - 1.0) * (base - 1.0)
+			}
+			return 1.0 / (1.0 + total)
+		}
+
+		n_iterations, final_population, err := Optimize(OptimizationParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(sphere),
+			Mutate: NewOption(CombineMutators(rng,
+				WeightedMutator[float64]{GaussianMutation(0.25, 0.05, bounds), 1.0},
+			)),
+			RecombinationOpts: NewOption(RecombineOptions{
+				BaseCrossover: NewOption(SimulatedBinaryCrossover),
+				BaseBounds:    NewOption([]Bounds[float64]{bounds}),
+			}),
+			MaxIterations: NewOption(1000),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with SimulatedBinaryCrossover failed with error: %v", err)
+		}
+		if n_iterations >= 1000 || final_population[0].Score < 0.99 {
+			t.Errorf("Optimize with SimulatedBinaryCrossover failed to meet fitness threshold of 0.99: %f after %d iterations",
+				final_population[0].Score, n_iterations)
+		}
+	})
+
+	permutation_methods := map[string]CrossoverMethod{
+		"PartiallyMappedCrossover":   PartiallyMappedCrossover,
+		"OrderCrossover":             OrderCrossover,
+		"CycleCrossover":             CycleCrossover,
+		"EdgeRecombinationCrossover": EdgeRecombinationCrossover,
+	}
+	for name, method := range permutation_methods {
+		name, method := name, method
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			rng := NewRand(3)
+			options := RecombineOptions{BaseCrossover: NewOption(method), Rand: NewOption(rng)}
+			dad, mom, child := permutationGene(10), permutationGene(10), &Gene[int]{}
+			for i := 0; i < 100; i++ {
+				rng.Shuffle(10, func(i, j int) { dad.Bases[i], dad.Bases[j] = dad.Bases[j], dad.Bases[i] })
+				rng.Shuffle(10, func(i, j int) { mom.Bases[i], mom.Bases[j] = mom.Bases[j], mom.Bases[i] })
+				if err := dad.Recombine(mom, []int{}, child, options); err != nil {
+					t.Fatalf("%s failed with error: %v", name, err)
+				}
+				if !IsPermutation(child.Bases, dad.Bases) {
+					t.Fatalf("%s failed to produce a permutation: observed %v", name, child.Bases)
+				}
+			}
+
+			invalid := &Gene[int]{Bases: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 8}}
+			if err := dad.Recombine(invalid, []int{}, child, options); err == nil {
+				t.Errorf("%s failed to reject parents that are not permutations of each other", name)
+			}
+		})
+	}
+
+	t.Run("permutation examples", func(t *testing.T) {
+		t.Parallel()
+		dad := []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+		mom := []int{9, 3, 7, 8, 2, 6, 5, 1, 4}
+		if child := partiallyMappedCrossover(dad, mom, 3, 5); !equal(child, []int{9, 3, 7, 4, 5, 6, 2, 1, 8}) {
+			t.Errorf("partiallyMappedCrossover failed: expected [9 3 7 4 5 6 2 1 8], observed %v", child)
+		}
+		if child := orderCrossover(dad, mom, 3, 5); !equal(child, []int{7, 8, 2, 4, 5, 6, 1, 9, 3}) {
+			t.Errorf("orderCrossover failed: expected [7 8 2 4 5 6 1 9 3], observed %v", child)
+		}
+		dad = []int{1, 2, 3, 4, 5, 6, 7, 8}
+		mom = []int{8, 5, 2, 1, 3, 6, 4, 7}
+		if child := cycleCrossover(dad, mom); !equal(child, []int{1, 5, 2, 4, 3, 6, 7, 8}) {
+			t.Errorf("cycleCrossover failed: expected [1 5 2 4 3 6 7 8], observed %v", child)
+		}
+	})
+
+	t.Run("IsPermutation", func(t *testing.T) {
+		t.Parallel()
+		if !IsPermutation([]string{"b", "c", "a"}, []string{"a", "b", "c"}) {
+			t.Error("IsPermutation failed to accept a permutation")
+		}
+		if IsPermutation([]int{1, 1, 2}, []int{1, 2, 3}) || IsPermutation([]int{1, 2}, []int{1, 2, 3}) ||
+			IsPermutation([]int{1, 1}, []int{1, 1}) {
+			t.Error("IsPermutation failed to reject an invalid permutation")
+		}
+	})
+
+	t.Run("Code.Recombine/invalid permutation", func(t *testing.T) {
+		t.Parallel()
+		dad := Code[int]{Gene: NewOption(&Gene[int]{Name: "dad", Bases: []int{0, 1, 2, 3}})}
+		mom := Code[int]{Gene: NewOption(&Gene[int]{Name: "mom", Bases: []int{0, 1, 1, 3}})}
+		// a pooled child still holding the bases of a previous generation
+		child := Code[int]{Gene: NewOption(&Gene[int]{Name: "old", Bases: []int{9, 9, 9, 9}})}
+		dad.Recombine(mom, &child, RecombineOptions{
+			BaseCrossover: NewOption(OrderCrossover), Rand: NewOption(NewRand(1)),
+		})
+		if child.Gene.Val.Name != "dad" || !equal(child.Gene.Val.Bases, []int{0, 1, 2, 3}) {
+			t.Errorf("Code.Recombine failed to copy the first parent: observed %v", child.Gene.Val.ToMap())
+		}
+		if child.Gene.Val == dad.Gene.Val {
+			t.Errorf("Code.Recombine failed: child shares genetic material with the first parent")
+		}
+	})
+
+	t.Run("Optimize/permutation", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(17)
+		initial_population := []Code[int]{}
+		for i := 0; i < 20; i++ {
+			gene := permutationGene(12)
+			rng.Shuffle(12, func(i, j int) { gene.Bases[i], gene.Bases[j] = gene.Bases[j], gene.Bases[i] })
+			initial_population = append(initial_population, Code[int]{Gene: NewOption(gene)})
+		}
+		// the number of adjacent pairs in ascending order
+		sortedness := func(code Code[int]) float64 {
+			ascending := 0
+			for i := 1; i < len(code.Gene.Val.Bases); i++ {
+				if code.Gene.Val.Bases[i] == code.Gene.Val.Bases[i-1]+1 {
+					ascending++
+				}
+			}
+			return float64(ascending) / 11.0
+		}
+
+		_, final_population, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(sortedness),
+			Mutate:            NewOption(CombineMutators(rng, WeightedMutator[int]{InversionMutation[int](0.3), 1.0})),
+			RecombinationOpts: NewOption(RecombineOptions{BaseCrossover: NewOption(EdgeRecombinationCrossover)}),
+			MaxIterations:     NewOption(200),
+			Rand:              NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with EdgeRecombinationCrossover failed with error: %v", err)
+		}
+		for _, member := range final_population {
+			if !IsPermutation(member.Code.Gene.Val.Bases, permutationGene(12).Bases) {
+				t.Fatalf("Optimize with EdgeRecombinationCrossover produced an invalid permutation: %v",
+					member.Code.Gene.Val.Bases)
+			}
+		}
+		if final_population[0].Score < 0.5 {
+			t.Errorf("Optimize with EdgeRecombinationCrossover failed to improve: %f", final_population[0].Score)
+		}
+	})
+
+	t.Run("CrossoverOptions", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(23)
+		for i := 0; i < 100; i++ {
+			indices, err := CrossoverOptions{Points: NewOption(3)}.indices(rng, 10)
+			if err != nil || len(indices) != 3 || newSet(indices...).len() != 3 || indices[0] < 1 {
+				t.Fatalf("NPointCrossover failed: expected 3 distinct cut points, observed %v (%v)", indices, err)
+			}
+			indices, _ = CrossoverOptions{Points: NewOption(20)}.indices(rng, 10)
+			if !equal(indices, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+				t.Fatalf("NPointCrossover failed to limit the cut points: observed %v", indices)
+			}
+
+			indices, _ = CrossoverOptions{
+				Method: NewOption(SegmentCrossover), SegmentLength: NewOption(3),
+			}.indices(rng, 10)
+			if !(len(indices) == 2 && indices[1]-indices[0] == 3) && !equal(indices, []int{7}) {
+				t.Fatalf("SegmentCrossover failed: expected a segment of 3, observed %v", indices)
+			}
+		}
+
+		indices, _ := CrossoverOptions{
+			Method: NewOption(UniformCrossover), SwapProbability: NewOption(0.0),
+		}.indices(rng, 10)
+		if len(indices) != 0 {
+			t.Errorf("UniformCrossover failed with SwapProbability 0: observed %v", indices)
+		}
+		indices, _ = CrossoverOptions{
+			Method: NewOption(UniformCrossover), SwapProbability: NewOption(1.0),
+		}.indices(rng, 10)
+		if !equal(indices, []int{0}) {
+			t.Errorf("UniformCrossover failed with SwapProbability 1: observed %v", indices)
+		}
+
+		if _, err := (CrossoverOptions{Method: NewOption(OrderCrossover)}).indices(rng, 10); err == nil {
+			t.Error("CrossoverOptions failed to reject a method that cannot be used for every level")
+		}
+	})
+
+	t.Run("CrossoverOptions/Rate", func(t *testing.T) {
+		t.Parallel()
+		dad := &Gene[int]{Name: "dad", Bases: []int{1, 2, 3, 4}}
+		mom := &Gene[int]{Name: "mom", Bases: []int{5, 6, 7, 8}}
+		child := &Gene[int]{}
+		for i := 0; i < 20; i++ {
+			dad.Recombine(mom, []int{}, child, RecombineOptions{
+				GeneCrossover: NewOption(CrossoverOptions{Rate: NewOption(0.0)}),
+			})
+			if child.Name != "dad" || !equal(child.Bases, dad.Bases) {
+				t.Fatalf("Rate 0 failed to copy the first parent: observed %s %v", child.Name, child.Bases)
+			}
+		}
+		dad.Recombine(mom, []int{}, child, RecombineOptions{
+			BaseCrossover: NewOption(UniformCrossover),
+			GeneCrossover: NewOption(CrossoverOptions{SwapProbability: NewOption(1.0), Rate: NewOption(1.0)}),
+		})
+		if !equal(child.Bases, mom.Bases) {
+			t.Errorf("UniformCrossover failed with SwapProbability 1: expected %v, observed %v", mom.Bases, child.Bases)
+		}
+	})
+
+	t.Run("CrossoverOptions/levels", func(t *testing.T) {
+		t.Parallel()
+		dad, _ := rangeChromosome(4, 2, 0, 5)
+		mom, _ := rangeChromosome(4, 2, 10, 15)
+		child := &Chromosome[int]{}
+		err := dad.Recombine(mom, []int{}, child, RecombineOptions{
+			NucleosomeCrossover: NewOption(CrossoverOptions{Rate: NewOption(0.0)}),
+			ChromosomeCrossover: NewOption(CrossoverOptions{
+				Method: NewOption(UniformCrossover), SwapProbability: NewOption(1.0),
+			}),
+			GeneCrossover: NewOption(CrossoverOptions{Rate: NewOption(0.0)}),
+		})
+		if err != nil {
+			t.Fatalf("Chromosome.Recombine failed with error: %v", err)
+		}
+		for i, nucleosome := range child.Nucleosomes {
+			for j, gene := range nucleosome.Genes {
+				if !equal(gene.Bases, mom.Nucleosomes[i].Genes[j].Bases) {
+					t.Fatalf("CrossoverOptions failed to apply per level: expected %v, observed %v",
+						mom.Nucleosomes[i].Genes[j].Bases, gene.Bases)
+				}
+			}
+		}
+
+		_, _, err = Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+			RecombinationOpts: NewOption(RecombineOptions{
+				NucleosomeCrossover: NewOption(CrossoverOptions{Method: NewOption(CycleCrossover)}),
+			}),
+		})
+		if err == nil {
+			t.Error("Optimize failed to reject a permutation method for the Nucleosome level")
+		}
+	})
+
+	t.Run("Optimize/UniformCrossover", func(t *testing.T) {
+		t.Parallel()
+		n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+			MaxIterations:     NewOption(1000),
+			RecombinationOpts: NewOption(RecombineOptions{
+				GeneCrossover: NewOption(CrossoverOptions{
+					Method: NewOption(UniformCrossover), Rate: NewOption(0.9),
+				}),
+			}),
+			Rand: NewOption(NewRand(29)),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with UniformCrossover failed with error: %v", err)
+		}
+		if n_iterations < 1000 && final_population[0].Score < 0.99 {
+			t.Errorf("Optimize with UniformCrossover failed to meet fitness threshold: %f", final_population[0].Score)
+		}
+	})
+}
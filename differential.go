@@ -0,0 +1,377 @@
+package bluegenes
+
+import (
+	"context"
+	"math"
+)
+
+// How OptimizeDifferentialEvolution builds the mutant vector for each member.
+type DEStrategy int
+
+const (
+	// A random member plus F times the difference of two others.
+	DERand1Bin DEStrategy = iota
+	// The best member plus F times the difference of two random members.
+	DEBest1Bin
+	// The member moved F of the way toward one of the best PBest fraction of
+	// the population, plus F times the difference of two random members.
+	DECurrentToBest1Bin
+)
+
+func (s DEStrategy) String() string {
+	switch s {
+	case DERand1Bin:
+		return "rand/1/bin"
+	case DEBest1Bin:
+		return "best/1/bin"
+	case DECurrentToBest1Bin:
+		return "current-to-best/1/bin"
+	default:
+		return "unknown"
+	}
+}
+
+// How OptimizeDifferentialEvolution chooses F and CR for each trial.
+type DEAdaptation int
+
+const (
+	// Every trial uses F and CR.
+	FixedParameters DEAdaptation = iota
+	// JADE (Zhang and Sanderson): F and CR are sampled around means that
+	// move toward the values of successful trials at LearningRate.
+	JADE
+	// SHADE (Tanabe and Fukunaga): F and CR are sampled around one of
+	// MemorySize remembered means, which are replaced in turn by the
+	// improvement-weighted means of successful trials.
+	SHADE
+)
+
+func (a DEAdaptation) String() string {
+	switch a {
+	case FixedParameters:
+		return "fixed"
+	case JADE:
+		return "JADE"
+	case SHADE:
+		return "SHADE"
+	default:
+		return "unknown"
+	}
+}
+
+type DifferentialEvolutionParams[T Float] struct {
+	Optimization OptimizationParams[T]
+	Strategy     Option[DEStrategy]
+	Adaptation   Option[DEAdaptation]
+	F            Option[float64]
+	CR           Option[float64]
+	PBest        Option[float64]
+	LearningRate Option[float64]
+	MemorySize   Option[int]
+}
+
+// The F and CR used for trials, adapted according to a DEAdaptation.
+type deControl struct {
+	adaptation DEAdaptation
+	f, cr      []float64
+	rate       float64
+	next       int
+}
+
+func newDEControl[T Float](params DifferentialEvolutionParams[T]) *deControl {
+	size := 1
+	if params.Adaptation.Val == SHADE {
+		size = params.MemorySize.Val
+	}
+	control := &deControl{
+		adaptation: params.Adaptation.Val,
+		f:          make([]float64, size),
+		cr:         make([]float64, size),
+		rate:       params.LearningRate.Val,
+	}
+	for i := 0; i < size; i++ {
+		control.f[i], control.cr[i] = params.F.Val, params.CR.Val
+	}
+	return control
+}
+
+// Returns the F and CR for one trial.
+func (c *deControl) sample(rng *Rand) (float64, float64) {
+	if c.adaptation == FixedParameters {
+		return c.f[0], c.cr[0]
+	}
+	k := randomInt(rng, 0, len(c.f))
+	cr := math.Min(math.Max(c.cr[k]+0.1*rng.NormFloat64(), 0.0), 1.0)
+	f := 0.0
+	for f <= 0.0 {
+		f = c.f[k] + 0.1*math.Tan(math.Pi*(rng.Float64()-0.5))
+	}
+	return math.Min(f, 1.0), cr
+}
+
+// Adapts the means to the F and CR of the trials that improved on their
+// targets by the given amounts.
+func (c *deControl) update(f, cr, improvements []float64) {
+	if c.adaptation == FixedParameters || len(f) == 0 {
+		return
+	}
+	weights := make([]float64, len(f))
+	total := 0.0
+	for i, improvement := range improvements {
+		weights[i] = improvement
+		if c.adaptation == JADE {
+			weights[i] = 1.0
+		}
+		total += weights[i]
+	}
+	if total <= 0.0 {
+		for i := range weights {
+			weights[i] = 1.0
+		}
+		total = float64(len(weights))
+	}
+	mean_cr, sum_f, sum_f2 := 0.0, 0.0, 0.0
+	for i, w := range weights {
+		mean_cr += w / total * cr[i]
+		sum_f += w / total * f[i]
+		sum_f2 += w / total * f[i] * f[i]
+	}
+	// the Lehmer mean favors larger F
+	mean_f := sum_f2 / sum_f
+
+	if c.adaptation == JADE {
+		c.cr[0] = (1.0-c.rate)*c.cr[0] + c.rate*mean_cr
+		c.f[0] = (1.0-c.rate)*c.f[0] + c.rate*mean_f
+		return
+	}
+	c.cr[c.next], c.f[c.next] = mean_cr, mean_f
+	c.next = (c.next + 1) % len(c.f)
+}
+
+// Returns k distinct random indices below n other than exclude.
+func distinctIndices(rng *Rand, n, exclude, k int) []int {
+	chosen := newSet[int]()
+	chosen.add(exclude)
+	indices := []int{}
+	for len(indices) < k {
+		i := randomInt(rng, 0, n)
+		if !chosen.contains(i) {
+			chosen.add(i)
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// Returns the concatenated bases of the Code as float64s, padded to the length
+// of fallback with its bases.
+func deVector[T Float](code Code[T], fallback []float64) []float64 {
+	vector := []float64{}
+	for _, base := range codeBases(code) {
+		vector = append(vector, float64(base))
+	}
+	for len(vector) < len(fallback) {
+		vector = append(vector, fallback[len(vector)])
+	}
+	return vector
+}
+
+// Overwrites the concatenated bases of the Code with the vector.
+func setCodeBases[T Float](code Code[T], vector []float64) {
+	k := 0
+	for _, gene := range codeGenes(code) {
+		gene.Mu.Lock()
+		for i := range gene.Bases {
+			gene.Bases[i] = T(vector[k])
+			k++
+		}
+		gene.Mu.Unlock()
+	}
+}
+
+// Returns a trial Code for the member at index i of the population, which is
+// ordered best first.
+func deTrial[T Float](params DifferentialEvolutionParams[T], population []*ScoredCode[T],
+	i int, f, cr float64, rng *Rand) Code[T] {
+	target := deVector(population[i].Code, nil)
+	vector := func(j int) []float64 {
+		return deVector(population[j].Code, target)
+	}
+	r := distinctIndices(rng, len(population), i, 3)
+	mutant := make([]float64, len(target))
+	switch params.Strategy.Val {
+	case DEBest1Bin:
+		best, a, b := vector(0), vector(r[0]), vector(r[1])
+		if i == 0 {
+			best = target
+		}
+		for k := range mutant {
+			mutant[k] = best[k] + f*(a[k]-b[k])
+		}
+	case DECurrentToBest1Bin:
+		n_best := int(math.Ceil(params.PBest.Val * float64(len(population))))
+		n_best, _ = max(n_best, 1)
+		best, a, b := vector(randomInt(rng, 0, n_best)), vector(r[0]), vector(r[1])
+		for k := range mutant {
+			mutant[k] = target[k] + f*(best[k]-target[k]) + f*(a[k]-b[k])
+		}
+	default:
+		base, a, b := vector(r[0]), vector(r[1]), vector(r[2])
+		for k := range mutant {
+			mutant[k] = base[k] + f*(a[k]-b[k])
+		}
+	}
+
+	// binomial crossover always takes at least one base from the mutant
+	trial := target
+	if len(target) > 0 {
+		j_rand := randomInt(rng, 0, len(target))
+		for k := range trial {
+			if k == j_rand || rng.Float64() < cr {
+				trial[k] = mutant[k]
+			}
+		}
+	}
+	code := population[i].Code.DeepCopy()
+	setCodeBases(code, trial)
+	if params.Optimization.Schema.Ok() {
+		params.Optimization.Schema.Val.Repair(&code, params.Optimization.RepairMethod.Val)
+	}
+	return code
+}
+
+// Runs differential evolution on Code with float bases, treating the
+// concatenated bases of every level of each Code as a vector. Each generation,
+// every member of params.Optimization.InitialPopulation is challenged by a
+// trial Code built according to params.Strategy (default DERand1Bin) and
+// binomial crossover, and the trial replaces the member if its Score is at
+// least as good. F (default 0.5) scales the difference vectors and CR (default
+// 0.9) is the crossover rate; with JADE or SHADE adaptation they are the
+// starting means. Members and trials are compared by Deb's feasibility rules
+// when params.Optimization.Constraints is set. Returns the number of
+// generations and the final population sorted best first; if the TimeLimit
+// elapses, the population so far is returned along with the context error.
+func OptimizeDifferentialEvolution[T Float](params DifferentialEvolutionParams[T]) (int, []*ScoredCode[T], error) {
+	generation_count := 0
+	population := []*ScoredCode[T]{}
+	opt_params := params.Optimization
+
+	if !opt_params.InitialPopulation.Ok() {
+		return generation_count, population, missingParameterError{"params.Optimization.InitialPopulation"}
+	}
+	if len(opt_params.InitialPopulation.Val) < 4 {
+		return generation_count, population, anError{"params.Optimization.InitialPopulation must have len of at least 4"}
+	}
+	if !opt_params.MeasureFitness.Ok() {
+		return generation_count, population, missingParameterError{"params.Optimization.MeasureFitness"}
+	}
+	if !opt_params.MaxIterations.Ok() {
+		opt_params.MaxIterations.Val = 1000
+	}
+	opt_params.FitnessTarget.Val = fitnessTarget(opt_params.FitnessTarget, opt_params.Direction.Val)
+	if !params.F.Ok() {
+		params.F.Val = 0.5
+	}
+	if params.F.Val <= 0.0 || params.F.Val > 2.0 {
+		return generation_count, population, anError{"params.F must be greater than 0 and at most 2"}
+	}
+	if !params.CR.Ok() {
+		params.CR.Val = 0.9
+	}
+	if params.CR.Val < 0.0 || params.CR.Val > 1.0 {
+		return generation_count, population, anError{"params.CR must be between 0 and 1"}
+	}
+	if !params.PBest.Ok() {
+		params.PBest.Val = 0.1
+	}
+	if params.PBest.Val < 0.0 || params.PBest.Val > 1.0 {
+		return generation_count, population, anError{"params.PBest must be between 0 and 1"}
+	}
+	if !params.LearningRate.Ok() {
+		params.LearningRate.Val = 0.1
+	}
+	if params.LearningRate.Val <= 0.0 || params.LearningRate.Val > 1.0 {
+		return generation_count, population, anError{"params.LearningRate must be greater than 0 and at most 1"}
+	}
+	if !params.MemorySize.Ok() {
+		params.MemorySize.Val = 10
+	}
+	if params.MemorySize.Val < 1 {
+		return generation_count, population, anError{"params.MemorySize must be at least 1"}
+	}
+	if opt_params.MaxEvaluations.Ok() && opt_params.MaxEvaluations.Val < len(opt_params.InitialPopulation.Val) {
+		return generation_count, population, anError{"params.Optimization.MaxEvaluations must be at least len(params.Optimization.InitialPopulation)"}
+	}
+	if opt_params.Constraints.Ok() && opt_params.Constraints.Val.Handling != FeasibilityRules {
+		return generation_count, population, anError{"params.Optimization.Constraints.Handling must be FeasibilityRules"}
+	}
+	params.Optimization = opt_params
+	ctx := context.Background()
+	if opt_params.TimeLimit.Ok() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt_params.TimeLimit.Val)
+		defer cancel()
+	}
+
+	rng := opt_params.Rand.Val
+	direction := opt_params.Direction.Val
+	evaluations := 0
+	measure := func(scores []*ScoredCode[T]) {
+		measureConcurrently(len(scores), opt_params.ParallelCount.Val, func(i int) {
+			scores[i].Score = opt_params.MeasureFitness.Val(scores[i].Code)
+			if opt_params.Constraints.Ok() {
+				scores[i].Violation = opt_params.Constraints.Val.violation(scores[i].Code)
+			}
+		})
+		evaluations += len(scores)
+	}
+	remaining := func() int {
+		if !opt_params.MaxEvaluations.Ok() {
+			return len(population)
+		}
+		n, _ := min(opt_params.MaxEvaluations.Val-evaluations, len(population))
+		return n
+	}
+	for _, code := range opt_params.InitialPopulation.Val {
+		population = append(population, &ScoredCode[T]{Code: code})
+	}
+	measure(population)
+	sortFeasible(population, direction)
+	control := newDEControl(params)
+
+	for generation_count < opt_params.MaxIterations.Val && remaining() > 0 &&
+		direction.fitness(bestFeasibleScore(population, direction)) < direction.fitness(opt_params.FitnessTarget.Val) {
+		if ctx.Err() != nil {
+			return generation_count, population, ctx.Err()
+		}
+		generation_count++
+		// with MaxEvaluations, the last generation may only challenge the best members
+		trials := make([]*ScoredCode[T], remaining())
+		fs, crs := make([]float64, len(trials)), make([]float64, len(trials))
+		for i := range trials {
+			fs[i], crs[i] = control.sample(rng)
+			trials[i] = &ScoredCode[T]{Code: deTrial(params, population, i, fs[i], crs[i], rng)}
+		}
+		measure(trials)
+
+		successful_f, successful_cr, improvements := []float64{}, []float64{}, []float64{}
+		for i, trial := range trials {
+			if feasiblyBetter(trial, population[i], direction) {
+				successful_f = append(successful_f, fs[i])
+				successful_cr = append(successful_cr, crs[i])
+				improvements = append(improvements, math.Abs(trial.Score-population[i].Score))
+			}
+			if !feasiblyBetter(population[i], trial, direction) {
+				population[i] = trial
+			}
+		}
+		control.update(successful_f, successful_cr, improvements)
+		sortFeasible(population, direction)
+
+		if opt_params.IterationHook.Ok() {
+			opt_params.IterationHook.Val(generation_count, population)
+		}
+	}
+
+	return generation_count, population, nil
+}
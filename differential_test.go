@@ -0,0 +1,258 @@
+package bluegenes
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDifferentialEvolution(t *testing.T) {
+	t.Run("deControl", func(t *testing.T) {
+		t.Parallel()
+		jade := newDEControl(DifferentialEvolutionParams[float64]{
+			Adaptation: NewOption(JADE), F: NewOption(0.5), CR: NewOption(0.5), LearningRate: NewOption(0.5),
+		})
+		jade.update([]float64{0.2, 0.6}, []float64{0.1, 0.3}, []float64{1.0, 3.0})
+		if math.Abs(jade.f[0]-0.5*0.5-0.5*0.5) > 1e-9 || math.Abs(jade.cr[0]-0.35) > 1e-9 {
+			t.Errorf("JADE update failed: expected F 0.5 and CR 0.35, observed %v and %v", jade.f[0], jade.cr[0])
+		}
+
+		shade := newDEControl(DifferentialEvolutionParams[float64]{
+			Adaptation: NewOption(SHADE), F: NewOption(0.5), CR: NewOption(0.5), MemorySize: NewOption(2),
+		})
+		shade.update([]float64{0.2, 0.6}, []float64{0.1, 0.3}, []float64{1.0, 3.0})
+		if math.Abs(shade.f[0]-(0.01+0.27)/(0.05+0.45)) > 1e-9 || math.Abs(shade.cr[0]-0.25) > 1e-9 ||
+			shade.f[1] != 0.5 || shade.next != 1 {
+			t.Errorf("SHADE update failed: observed F %v and CR %v", shade.f, shade.cr)
+		}
+
+		rng := NewRand(97)
+		for i := 0; i < 100; i++ {
+			f, cr := shade.sample(rng)
+			if f <= 0.0 || f > 1.0 || cr < 0.0 || cr > 1.0 {
+				t.Fatalf("sample failed: observed F %v and CR %v", f, cr)
+			}
+		}
+		fixed := newDEControl(DifferentialEvolutionParams[float64]{F: NewOption(0.7), CR: NewOption(0.2)})
+		fixed.update([]float64{0.1}, []float64{0.1}, []float64{1.0})
+		if f, cr := fixed.sample(rng); f != 0.7 || cr != 0.2 {
+			t.Errorf("sample failed with FixedParameters: expected 0.7 and 0.2, observed %v and %v", f, cr)
+		}
+	})
+
+	t.Run("distinctIndices", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(101)
+		for i := 0; i < 100; i++ {
+			indices := distinctIndices(rng, 4, 2, 3)
+			if !newSet(indices...).equal(newSet(0, 1, 3)) {
+				t.Fatalf("distinctIndices failed: expected 0, 1, and 3, observed %v", indices)
+			}
+		}
+	})
+
+	t.Run("deVector/setCodeBases", func(t *testing.T) {
+		t.Parallel()
+		code := Code[float64]{
+			Gene: NewOption(&Gene[float64]{Bases: []float64{1, 2}}),
+			Nucleosome: NewOption(&Nucleosome[float64]{Genes: []*Gene[float64]{
+				{Bases: []float64{3}}, {Bases: []float64{4, 5}},
+			}}),
+		}
+		if vector := deVector(code, []float64{0, 0, 0, 0, 0, 6}); !equal(vector, []float64{1, 2, 3, 4, 5, 6}) {
+			t.Errorf("deVector failed: expected [1 2 3 4 5 6], observed %v", vector)
+		}
+		setCodeBases(code, []float64{6, 5, 4, 3, 2})
+		if bases := codeBases(code); !equal(bases, []float64{6, 5, 4, 3, 2}) {
+			t.Errorf("setCodeBases failed: expected [6 5 4 3 2], observed %v", bases)
+		}
+	})
+
+	strategies := []DEStrategy{DERand1Bin, DEBest1Bin, DECurrentToBest1Bin}
+	adaptations := []DEAdaptation{FixedParameters, JADE, SHADE}
+	for _, strategy := range strategies {
+		for _, adaptation := range adaptations {
+			strategy, adaptation := strategy, adaptation
+			t.Run("Optimize/"+strategy.String()+"/"+adaptation.String(), func(t *testing.T) {
+				t.Parallel()
+				rng := NewRand(103)
+				hook_calls := 0
+				n_iterations, population, err := OptimizeDifferentialEvolution(DifferentialEvolutionParams[float64]{
+					Optimization: OptimizationParams[float64]{
+						InitialPopulation: NewOption(sphereInitialPopulation(rng, 30)),
+						MeasureFitness: NewOption(func(code Code[float64]) float64 {
+							return 1.0/measureSphereFitness(code) - 1.0
+						}),
+						Direction:     NewOption(Minimize),
+						FitnessTarget: NewOption(1e-6),
+						MaxIterations: NewOption(500),
+						ParallelCount: NewOption(2),
+						IterationHook: NewOption(func(generation int, scores []*ScoredCode[float64]) {
+							hook_calls++
+						}),
+						Rand: NewOption(rng),
+					},
+					Strategy:   NewOption(strategy),
+					Adaptation: NewOption(adaptation),
+					PBest:      NewOption(0.1),
+				})
+				if err != nil {
+					t.Fatalf("OptimizeDifferentialEvolution failed with error: %v", err)
+				}
+				if len(population) != 30 || hook_calls != n_iterations {
+					t.Errorf("OptimizeDifferentialEvolution failed: expected 30 members and %d hook calls, "+
+						"observed %d and %d", n_iterations, len(population), hook_calls)
+				}
+				if population[0].Score > 1e-6 || n_iterations == 500 {
+					t.Errorf("OptimizeDifferentialEvolution failed to reach the target: observed %v after %d generations",
+						population[0].Score, n_iterations)
+				}
+				for i := 1; i < len(population); i++ {
+					if population[i].Score < population[i-1].Score {
+						t.Fatalf("OptimizeDifferentialEvolution failed to sort the population")
+					}
+				}
+			})
+		}
+	}
+
+	t.Run("Schema", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(107)
+		bounds := Bounds[float64]{Lower: -5.0, Upper: 0.5}
+		schema := Schema[float64]{Gene: GeneSchema[float64]{Bounds: []Bounds[float64]{bounds}}}
+		initial_population := sphereInitialPopulation(rng, 20)
+		for i := range initial_population {
+			schema.Repair(&initial_population[i], ClampRepair)
+		}
+		_, population, err := OptimizeDifferentialEvolution(DifferentialEvolutionParams[float64]{
+			Optimization: OptimizationParams[float64]{
+				InitialPopulation: NewOption(initial_population),
+				MeasureFitness:    NewOption(measureSphereFitness),
+				FitnessTarget:     NewOption(2.0),
+				MaxIterations:     NewOption(50),
+				Schema:            NewOption(schema),
+				Rand:              NewOption(rng),
+			},
+		})
+		if err != nil {
+			t.Fatalf("OptimizeDifferentialEvolution with Schema failed with error: %v", err)
+		}
+		for _, base := range population[0].Code.Gene.Val.Bases {
+			if base < bounds.Lower || base > bounds.Upper || base < 0.45 {
+				t.Errorf("OptimizeDifferentialEvolution failed to repair trials: expected 0.5, observed %v", base)
+			}
+		}
+	})
+
+	t.Run("MaxEvaluations", func(t *testing.T) {
+		t.Parallel()
+		evaluations := 0
+		n_iterations, population, err := OptimizeDifferentialEvolution(DifferentialEvolutionParams[float64]{
+			Optimization: OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(NewRand(113), 10)),
+				MeasureFitness: NewOption(func(code Code[float64]) float64 {
+					evaluations++
+					return measureSphereFitness(code)
+				}),
+				FitnessTarget:  NewOption(2.0),
+				MaxEvaluations: NewOption(25),
+				Rand:           NewOption(NewRand(113)),
+			},
+		})
+		if err != nil {
+			t.Fatalf("OptimizeDifferentialEvolution with MaxEvaluations failed with error: %v", err)
+		}
+		if evaluations != 25 || n_iterations != 2 || len(population) != 10 {
+			t.Errorf("OptimizeDifferentialEvolution failed to respect MaxEvaluations: expected 25 evaluations "+
+				"in 2 generations, observed %d in %d", evaluations, n_iterations)
+		}
+	})
+
+	t.Run("TimeLimit", func(t *testing.T) {
+		t.Parallel()
+		n_iterations, population, err := OptimizeDifferentialEvolution(DifferentialEvolutionParams[float64]{
+			Optimization: OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(NewRand(127), 10)),
+				MeasureFitness: NewOption(func(code Code[float64]) float64 {
+					time.Sleep(time.Millisecond)
+					return measureSphereFitness(code)
+				}),
+				FitnessTarget: NewOption(2.0),
+				MaxIterations: NewOption(1000),
+				TimeLimit:     NewOption(30 * time.Millisecond),
+				Rand:          NewOption(NewRand(127)),
+			},
+		})
+		if err != context.DeadlineExceeded {
+			t.Fatalf("OptimizeDifferentialEvolution failed to respect TimeLimit: expected %v, observed %v",
+				context.DeadlineExceeded, err)
+		}
+		if n_iterations >= 1000 || len(population) != 10 {
+			t.Errorf("OptimizeDifferentialEvolution failed to return the population so far: observed %d members after %d",
+				len(population), n_iterations)
+		}
+	})
+
+	t.Run("Constraints", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(131)
+		_, population, err := OptimizeDifferentialEvolution(DifferentialEvolutionParams[float64]{
+			Optimization: OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+				MeasureFitness:    NewOption(measureSphereError),
+				Direction:         NewOption(Minimize),
+				MaxIterations:     NewOption(300),
+				Constraints: NewOption(Constraints[float64]{Inequality: []func(Code[float64]) float64{
+					func(code Code[float64]) float64 { return 1.0 - code.Gene.Val.Bases[0] },
+				}}),
+				Rand: NewOption(rng),
+			},
+		})
+		if err != nil {
+			t.Fatalf("OptimizeDifferentialEvolution with Constraints failed with error: %v", err)
+		}
+		if population[0].Violation != 0.0 || population[0].Score > 1.01 {
+			t.Errorf("OptimizeDifferentialEvolution failed to find the constrained optimum: observed %v with violation %v",
+				population[0].Score, population[0].Violation)
+		}
+	})
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		valid := DifferentialEvolutionParams[float64]{Optimization: OptimizationParams[float64]{
+			InitialPopulation: NewOption(sphereInitialPopulation(NewRand(109), 10)),
+			MeasureFitness:    NewOption(measureSphereFitness),
+		}}
+		invalid := map[string]DifferentialEvolutionParams[float64]{
+			"empty": {},
+		}
+		small := valid
+		small.Optimization.InitialPopulation = NewOption(sphereInitialPopulation(NewRand(109), 3))
+		invalid["InitialPopulation"] = small
+		f := valid
+		f.F = NewOption(0.0)
+		invalid["F"] = f
+		cr := valid
+		cr.CR = NewOption(1.5)
+		invalid["CR"] = cr
+		pbest := valid
+		pbest.PBest = NewOption(-0.1)
+		invalid["PBest"] = pbest
+		memory := valid
+		memory.MemorySize = NewOption(0)
+		invalid["MemorySize"] = memory
+		evaluations := valid
+		evaluations.Optimization.MaxEvaluations = NewOption(9)
+		invalid["MaxEvaluations"] = evaluations
+		handling := valid
+		handling.Optimization.Constraints = NewOption(Constraints[float64]{Handling: AdaptivePenalty})
+		invalid["Constraints"] = handling
+		for name, params := range invalid {
+			if _, _, err := OptimizeDifferentialEvolution(params); err == nil {
+				t.Errorf("OptimizeDifferentialEvolution failed to reject invalid %s", name)
+			}
+		}
+	})
+}
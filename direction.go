@@ -0,0 +1,50 @@
+package bluegenes
+
+import (
+	"math"
+)
+
+// Whether Optimize maximizes or minimizes the Score returned by MeasureFitness.
+type Direction int
+
+const (
+	Maximize Direction = iota
+	Minimize
+)
+
+func (d Direction) String() string {
+	if d == Minimize {
+		return "minimize"
+	}
+	return "maximize"
+}
+
+// Returns the score oriented so that higher is better.
+func (d Direction) fitness(score float64) float64 {
+	if d == Minimize {
+		return -score
+	}
+	return score
+}
+
+// Returns true if score a is better than score b.
+func (d Direction) better(a, b float64) bool {
+	return d.fitness(a) > d.fitness(b)
+}
+
+// Returns the worst possible score.
+func (d Direction) worst() float64 {
+	return d.fitness(math.Inf(-1))
+}
+
+// Returns the FitnessTarget to use: the target if it is set, otherwise 0.99
+// when maximizing and no target (-Inf) when minimizing.
+func fitnessTarget(target Option[float64], direction Direction) float64 {
+	if target.Ok() {
+		return target.Val
+	}
+	if direction == Minimize {
+		return math.Inf(-1)
+	}
+	return 0.99
+}
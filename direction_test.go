@@ -0,0 +1,124 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func measureSphereError(code Code[float64]) float64 {
+	return 1.0/measureSphereFitness(code) - 1.0
+}
+
+func TestDirection(t *testing.T) {
+	t.Run("sortScoredCodes", func(t *testing.T) {
+		t.Parallel()
+		scores := scoredCodes([2]float64{0.5, 0.0}, [2]float64{-3.0, 0.0}, [2]float64{7.0, 0.0})
+		sortScoredCodes(scores, Minimize)
+		if bases := rankedBases(scores); !equal(bases, []int{1, 0, 2}) {
+			t.Errorf("sortScoredCodes failed to minimize: expected [1 0 2], observed %v", bases)
+		}
+		sortScoredCodes(scores, Maximize)
+		if bases := rankedBases(scores); !equal(bases, []int{2, 0, 1}) {
+			t.Errorf("sortScoredCodes failed to maximize: expected [2 0 1], observed %v", bases)
+		}
+		if Minimize.worst() != math.Inf(1) || Maximize.worst() != math.Inf(-1) {
+			t.Errorf("Direction.worst failed: observed %f and %f", Minimize.worst(), Maximize.worst())
+		}
+	})
+
+	t.Run("converged", func(t *testing.T) {
+		t.Parallel()
+		r := &optimizationRun[int]{params: OptimizationParams[int]{
+			Direction: NewOption(Minimize), StagnationLimit: NewOption(2), StagnationEpsilon: NewOption(0.5),
+		}}
+		r.stagnationBaseline = 10.0
+		reasons := []StopReason{}
+		for _, best := range []float64{9.0, 8.8, 8.7, 8.6} {
+			r.bestFitness = best
+			reasons = append(reasons, r.converged())
+		}
+		if !equal(reasons, []StopReason{StopNone, StopNone, StopStagnation, StopStagnation}) {
+			t.Errorf("converged failed to minimize: observed %v", reasons)
+		}
+	})
+
+	t.Run("Optimize/Minimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(61)
+		hooked := true
+		report, err := OptimizeWithReport(OptimizationParams[float64]{
+			InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+			MeasureFitness:    NewOption(measureSphereError),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](0.5, 0.3)(code.Gene.Val, rng)
+			}),
+			Direction:     NewOption(Minimize),
+			FitnessTarget: NewOption(0.001),
+			IterationHook: NewOption(func(generation int, scores []*ScoredCode[float64]) {
+				hooked = hooked && sort.SliceIsSorted(scores, func(i, j int) bool {
+					return scores[i].Score < scores[j].Score
+				})
+			}),
+			MaxIterations: NewOption(1000),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with Minimize failed with error: %v", err)
+		}
+		if !hooked {
+			t.Errorf("Optimize with Minimize failed to pass ascending scores to IterationHook")
+		}
+		if report.StopReason != StopFitnessTarget || report.Population[0].Score > 0.001 {
+			t.Errorf("Optimize with Minimize failed to reach the target: %s, %f", report.StopReason, report.Population[0].Score)
+		}
+		for i := 1; i < len(report.Population); i++ {
+			if report.Population[i].Score < report.Population[i-1].Score {
+				t.Fatalf("Optimize with Minimize failed to return ascending scores")
+			}
+		}
+		for _, stats := range report.History {
+			if stats.Best > stats.Worst {
+				t.Fatalf("Optimize with Minimize failed: Best %f is worse than Worst %f", stats.Best, stats.Worst)
+			}
+		}
+	})
+
+	t.Run("Optimize/raw objective", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(67)
+		report, err := OptimizeWithReport(OptimizationParams[float64]{
+			InitialPopulation: NewOption(sphereInitialPopulation(rng, 10)),
+			MeasureFitness: NewOption(func(code Code[float64]) float64 {
+				return 100.0 - measureSphereError(code)
+			}),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](0.5, 0.3)(code.Gene.Val, rng)
+			}),
+			Direction:     NewOption(Maximize),
+			FitnessTarget: NewOption(math.Inf(1)),
+			MaxIterations: NewOption(20),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with a raw objective failed with error: %v", err)
+		}
+		if report.StopReason != StopMaxIterations || report.Generations != 20 {
+			t.Errorf("Optimize with a raw objective failed to run without a FitnessTarget: %s after %d",
+				report.StopReason, report.Generations)
+		}
+	})
+
+	t.Run("fitnessTarget", func(t *testing.T) {
+		t.Parallel()
+		if observed := fitnessTarget(Option[float64]{}, Maximize); observed != 0.99 {
+			t.Errorf("fitnessTarget failed: expected 0.99 for Maximize, observed %v", observed)
+		}
+		if observed := fitnessTarget(Option[float64]{}, Minimize); !math.IsInf(observed, -1) {
+			t.Errorf("fitnessTarget failed: expected -Inf for Minimize, observed %v", observed)
+		}
+		if observed := fitnessTarget(NewOption(0.5), Minimize); observed != 0.5 {
+			t.Errorf("fitnessTarget failed: expected 0.5, observed %v", observed)
+		}
+	})
+}
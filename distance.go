@@ -0,0 +1,332 @@
+package bluegenes
+
+import (
+	"math"
+)
+
+// How the distance between two sequences of bases is measured.
+type DistanceMetric int
+
+const (
+	HammingMetric DistanceMetric = iota
+	EuclideanMetric
+	LevenshteinMetric
+)
+
+func (m DistanceMetric) String() string {
+	switch m {
+	case HammingMetric:
+		return "hamming"
+	case EuclideanMetric:
+		return "euclidean"
+	case LevenshteinMetric:
+		return "levenshtein"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns the number of positions at which the two sequences differ, counting
+// every position past the end of the shorter one as different.
+func HammingDistance[T Ordered](a, b []T) int {
+	shorter, longer := a, b
+	if len(a) > len(b) {
+		shorter, longer = b, a
+	}
+	distance := len(longer) - len(shorter)
+	for i := range shorter {
+		if shorter[i] != longer[i] {
+			distance++
+		}
+	}
+	return distance
+}
+
+// Returns the difference between the bases at index i of the two sequences,
+// treating a missing numeric base as 0. Non-numeric bases differ by 1 if they
+// are not equal.
+func baseDifference[T Ordered](a, b []T, i int) float64 {
+	var x, y T
+	if i < len(a) {
+		x = a[i]
+	}
+	if i < len(b) {
+		y = b[i]
+	}
+	xf, ok := baseFloat(x)
+	yf, _ := baseFloat(y)
+	if !ok {
+		if i >= len(a) || i >= len(b) || x != y {
+			return 1.0
+		}
+		return 0.0
+	}
+	return xf - yf
+}
+
+// Returns the Euclidean distance between the two sequences, padding the
+// shorter one with zeroes. Non-numeric bases differ by 1 if they are not
+// equal.
+func EuclideanDistance[T Ordered](a, b []T) float64 {
+	length, _ := max(len(a), len(b))
+	total := 0.0
+	for i := 0; i < length; i++ {
+		difference := baseDifference(a, b, i)
+		total += difference * difference
+	}
+	return math.Sqrt(total)
+}
+
+// Returns the minimum number of insertions, deletions, and substitutions that
+// turn one sequence into the other.
+func LevenshteinDistance[T Ordered](a, b []T) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j], _ = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// Returns the distance between the two sequences according to the metric.
+func SequenceDistance[T Ordered](a, b []T, metric DistanceMetric) float64 {
+	switch metric {
+	case EuclideanMetric:
+		return EuclideanDistance(a, b)
+	case LevenshteinMetric:
+		return float64(LevenshteinDistance(a, b))
+	default:
+		return float64(HammingDistance(a, b))
+	}
+}
+
+// Returns the distance between the Bases of the Genes according to the metric.
+// Names are not compared.
+func (g *Gene[T]) Distance(other *Gene[T], metric DistanceMetric) float64 {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	other.Mu.RLock()
+	defer other.Mu.RUnlock()
+	return SequenceDistance(g.Bases, other.Bases, metric)
+}
+
+// Returns the sum of the distances between the Genes paired by position. A Gene
+// without a counterpart is compared against an empty Gene.
+func (n *Nucleosome[T]) Distance(other *Nucleosome[T], metric DistanceMetric) float64 {
+	n.Mu.RLock()
+	defer n.Mu.RUnlock()
+	other.Mu.RLock()
+	defer other.Mu.RUnlock()
+	length, _ := max(len(n.Genes), len(other.Genes))
+	total := 0.0
+	for i := 0; i < length; i++ {
+		a, b := &Gene[T]{}, &Gene[T]{}
+		if i < len(n.Genes) {
+			a = n.Genes[i]
+		}
+		if i < len(other.Genes) {
+			b = other.Genes[i]
+		}
+		total += a.Distance(b, metric)
+	}
+	return total
+}
+
+// Returns the sum of the distances between the Nucleosomes paired by position.
+// A Nucleosome without a counterpart is compared against an empty Nucleosome.
+func (c *Chromosome[T]) Distance(other *Chromosome[T], metric DistanceMetric) float64 {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	other.Mu.RLock()
+	defer other.Mu.RUnlock()
+	length, _ := max(len(c.Nucleosomes), len(other.Nucleosomes))
+	total := 0.0
+	for i := 0; i < length; i++ {
+		a, b := &Nucleosome[T]{}, &Nucleosome[T]{}
+		if i < len(c.Nucleosomes) {
+			a = c.Nucleosomes[i]
+		}
+		if i < len(other.Nucleosomes) {
+			b = other.Nucleosomes[i]
+		}
+		total += a.Distance(b, metric)
+	}
+	return total
+}
+
+// Returns the sum of the distances between the Chromosomes paired by position.
+// A Chromosome without a counterpart is compared against an empty Chromosome.
+func (g *Genome[T]) Distance(other *Genome[T], metric DistanceMetric) float64 {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	other.Mu.RLock()
+	defer other.Mu.RUnlock()
+	length, _ := max(len(g.Chromosomes), len(other.Chromosomes))
+	total := 0.0
+	for i := 0; i < length; i++ {
+		a, b := &Chromosome[T]{}, &Chromosome[T]{}
+		if i < len(g.Chromosomes) {
+			a = g.Chromosomes[i]
+		}
+		if i < len(other.Chromosomes) {
+			b = other.Chromosomes[i]
+		}
+		total += a.Distance(b, metric)
+	}
+	return total
+}
+
+// Returns the sum of the distances between every level of genetic material
+// that is set in either Code. A level that is only set in one of them is
+// compared against an empty one. Strategy is not compared.
+func (c Code[T]) Distance(other Code[T], metric DistanceMetric) float64 {
+	total := 0.0
+	if c.Gene.Ok() || other.Gene.Ok() {
+		a, b := &Gene[T]{}, &Gene[T]{}
+		if c.Gene.Ok() {
+			a = c.Gene.Val
+		}
+		if other.Gene.Ok() {
+			b = other.Gene.Val
+		}
+		total += a.Distance(b, metric)
+	}
+	if c.Nucleosome.Ok() || other.Nucleosome.Ok() {
+		a, b := &Nucleosome[T]{}, &Nucleosome[T]{}
+		if c.Nucleosome.Ok() {
+			a = c.Nucleosome.Val
+		}
+		if other.Nucleosome.Ok() {
+			b = other.Nucleosome.Val
+		}
+		total += a.Distance(b, metric)
+	}
+	if c.Chromosome.Ok() || other.Chromosome.Ok() {
+		a, b := &Chromosome[T]{}, &Chromosome[T]{}
+		if c.Chromosome.Ok() {
+			a = c.Chromosome.Val
+		}
+		if other.Chromosome.Ok() {
+			b = other.Chromosome.Val
+		}
+		total += a.Distance(b, metric)
+	}
+	if c.Genome.Ok() || other.Genome.Ok() {
+		a, b := &Genome[T]{}, &Genome[T]{}
+		if c.Genome.Ok() {
+			a = c.Genome.Val
+		}
+		if other.Genome.Ok() {
+			b = other.Genome.Val
+		}
+		total += a.Distance(b, metric)
+	}
+	return total
+}
+
+// Diversity measures of a population.
+type Diversity struct {
+	MeanDistance    float64
+	MinDistance     float64
+	MeanEntropy     float64
+	UniqueGenotypes int
+	UniqueRatio     float64
+}
+
+// Returns the mean distance between every pair of Code in the population
+// according to the metric.
+func MeanDistance[T Ordered](scores []*ScoredCode[T], metric DistanceMetric) float64 {
+	if len(scores) < 2 {
+		return 0.0
+	}
+	total, pairs := 0.0, 0
+	for i := range scores {
+		for j := i + 1; j < len(scores); j++ {
+			total += scores[i].Code.Distance(scores[j].Code, metric)
+			pairs++
+		}
+	}
+	return total / float64(pairs)
+}
+
+// Returns the Shannon entropy in bits of the bases at each position of the
+// concatenated bases of every level of the population's Code. Members that
+// are too short to have a base at a position are not counted for it.
+func LocusEntropy[T Ordered](scores []*ScoredCode[T]) []float64 {
+	counts := []map[T]int{}
+	totals := []int{}
+	for _, score := range scores {
+		for i, base := range codeBases(score.Code) {
+			if i == len(counts) {
+				counts = append(counts, map[T]int{})
+				totals = append(totals, 0)
+			}
+			counts[i][base]++
+			totals[i]++
+		}
+	}
+	entropy := make([]float64, len(counts))
+	for i, locus := range counts {
+		for _, count := range locus {
+			p := float64(count) / float64(totals[i])
+			entropy[i] -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// Returns the number of unique genotypes in the population according to
+// Code.Hash.
+func UniqueGenotypes[T Ordered](scores []*ScoredCode[T]) int {
+	fingerprints := newSet[uint64]()
+	for _, score := range scores {
+		fingerprints.add(score.Code.Hash())
+	}
+	return fingerprints.len()
+}
+
+// Measures the Diversity of the population, using the metric for the mean and
+// minimum pairwise distances. This is quadratic in the population size.
+func MeasureDiversity[T Ordered](scores []*ScoredCode[T], metric DistanceMetric) Diversity {
+	diversity := Diversity{}
+	if len(scores) == 0 {
+		return diversity
+	}
+	diversity.MinDistance = math.Inf(1)
+	if len(scores) < 2 {
+		diversity.MinDistance = 0.0
+	}
+	total, pairs := 0.0, 0
+	for i := range scores {
+		for j := i + 1; j < len(scores); j++ {
+			distance := scores[i].Code.Distance(scores[j].Code, metric)
+			diversity.MinDistance, _ = min(diversity.MinDistance, distance)
+			total += distance
+			pairs++
+		}
+	}
+	if pairs > 0 {
+		diversity.MeanDistance = total / float64(pairs)
+	}
+	entropy := LocusEntropy(scores)
+	for _, value := range entropy {
+		diversity.MeanEntropy += value
+	}
+	if len(entropy) > 0 {
+		diversity.MeanEntropy /= float64(len(entropy))
+	}
+	diversity.UniqueGenotypes = UniqueGenotypes(scores)
+	diversity.UniqueRatio = float64(diversity.UniqueGenotypes) / float64(len(scores))
+	return diversity
+}
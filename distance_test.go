@@ -0,0 +1,100 @@
+package bluegenes
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDistance(t *testing.T) {
+	t.Run("HammingDistance", func(t *testing.T) {
+		t.Parallel()
+		if d := HammingDistance([]int{1, 2, 3}, []int{1, 5, 3, 4, 5}); d != 3 {
+			t.Errorf("HammingDistance failed: expected 3, observed %d", d)
+		}
+		if d := HammingDistance([]int{}, []int{}); d != 0 {
+			t.Errorf("HammingDistance failed: expected 0, observed %d", d)
+		}
+	})
+
+	t.Run("EuclideanDistance", func(t *testing.T) {
+		t.Parallel()
+		if d := EuclideanDistance([]int{0, 0}, []int{3, 4}); d != 5.0 {
+			t.Errorf("EuclideanDistance failed: expected 5, observed %f", d)
+		}
+		if d := EuclideanDistance([]float64{1.0}, []float64{1.0, -2.0}); d != 2.0 {
+			t.Errorf("EuclideanDistance failed to pad with zeroes: expected 2, observed %f", d)
+		}
+		if d := EuclideanDistance([]string{"a", "b", "c"}, []string{"a", "x"}); d != math.Sqrt(2.0) {
+			t.Errorf("EuclideanDistance failed for strings: expected %f, observed %f", math.Sqrt(2.0), d)
+		}
+	})
+
+	t.Run("LevenshteinDistance", func(t *testing.T) {
+		t.Parallel()
+		expected := map[[2]string]int{
+			{"kitten", "sitting"}: 3,
+			{"", "abc"}:           3,
+			{"flaw", "lawn"}:      2,
+			{"same", "same"}:      0,
+		}
+		for words, distance := range expected {
+			a, b := strings.Split(words[0], ""), strings.Split(words[1], "")
+			if observed := LevenshteinDistance(a, b); observed != distance {
+				t.Errorf("LevenshteinDistance(%s, %s) failed: expected %d, observed %d", words[0], words[1], distance, observed)
+			}
+		}
+		if d := SequenceDistance([]int{1, 2, 3, 4}, []int{2, 3, 4}, LevenshteinMetric); d != 1.0 {
+			t.Errorf("SequenceDistance failed: expected 1, observed %f", d)
+		}
+	})
+
+	t.Run("structure", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(2, 2, 2, 0, 3)
+		other := genome.DeepCopy()
+		if d := genome.Distance(other, HammingMetric); d != 0.0 {
+			t.Errorf("Genome.Distance failed for a copy: expected 0, observed %f", d)
+		}
+		other.Chromosomes[1].Nucleosomes[0].Genes[1].Bases[2] = 10
+		if d := genome.Distance(other, HammingMetric); d != 1.0 {
+			t.Errorf("Genome.Distance failed: expected 1, observed %f", d)
+		}
+		if d := genome.Distance(other, EuclideanMetric); d != 8.0 {
+			t.Errorf("Genome.Distance failed with EuclideanMetric: expected 8, observed %f", d)
+		}
+		other.Chromosomes = other.Chromosomes[:1]
+		if d := genome.Distance(other, HammingMetric); d != 16.0 {
+			t.Errorf("Genome.Distance failed for a missing Chromosome: expected 16, observed %f", d)
+		}
+
+		gene, _ := rangeGene(0, 4)
+		a := Code[int]{Gene: NewOption(gene), Genome: NewOption(genome)}
+		b := Code[int]{Genome: NewOption(genome)}
+		if d := a.Distance(b, LevenshteinMetric); d != 5.0 {
+			t.Errorf("Code.Distance failed for a missing Gene: expected 5, observed %f", d)
+		}
+	})
+
+	t.Run("diversity", func(t *testing.T) {
+		t.Parallel()
+		scores := scoredCodes([2]float64{}, [2]float64{}, [2]float64{})
+		if d := MeanDistance(scores, EuclideanMetric); math.Abs(d-4.0/3.0) > 1e-9 {
+			t.Errorf("MeanDistance failed: expected %f, observed %f", 4.0/3.0, d)
+		}
+		if entropy := LocusEntropy(scores); len(entropy) != 1 || math.Abs(entropy[0]-math.Log2(3.0)) > 1e-9 {
+			t.Errorf("LocusEntropy failed: expected [%f], observed %v", math.Log2(3.0), entropy)
+		}
+
+		scores = append(scores, scoredCodes([2]float64{})...)
+		diversity := MeasureDiversity(scores, HammingMetric)
+		expected := Diversity{MeanDistance: 5.0 / 6.0, MinDistance: 0.0, MeanEntropy: 1.5, UniqueGenotypes: 3, UniqueRatio: 0.75}
+		if math.Abs(diversity.MeanDistance-expected.MeanDistance) > 1e-9 || diversity.MinDistance != 0.0 ||
+			diversity.MeanEntropy != 1.5 || diversity.UniqueGenotypes != 3 || diversity.UniqueRatio != 0.75 {
+			t.Errorf("MeasureDiversity failed: expected %+v, observed %+v", expected, diversity)
+		}
+		if empty := MeasureDiversity([]*ScoredCode[int]{}, HammingMetric); empty != (Diversity{}) {
+			t.Errorf("MeasureDiversity failed for an empty population: observed %+v", empty)
+		}
+	})
+}
@@ -1,8 +1,6 @@
 package bluegenes
 
 import (
-	"math"
-	"sort"
 	"sync"
 )
 
@@ -28,11 +26,8 @@ func (g *Gene[T]) Insert(index int, base T) error {
 	if 0 > index || index > len(g.Bases) {
 		return indexError{}
 	}
-	if len(g.Bases) == 0 {
-		g.Bases = append(g.Bases[:], base)
-	} else {
-		g.Bases = append(g.Bases[:index+1], g.Bases[index:]...)
-	}
+	g.Bases = append(g.Bases, base)
+	copy(g.Bases[index+1:], g.Bases[index:])
 	g.Bases[index] = base
 	return nil
 }
@@ -109,16 +104,17 @@ func (g *Gene[T]) Recombine(other *Gene[T], indices []int, child *Gene[T],
 	defer other.Mu.RUnlock()
 	min_size, _ := min(len(g.Bases), len(other.Bases))
 	max_size, _ := max(len(g.Bases), len(other.Bases))
-
-	if len(indices) == 0 && min_size > 1 {
-		max_swaps := math.Ceil(math.Log(float64(min_size)))
-		swaps, _ := max(RandomInt(0, int(max_swaps)), 1)
-		idxSet := newSet[int]()
-		for i := 0; i < swaps; i++ {
-			idxSet.add(RandomInt(0, min_size))
+	method := options.baseCrossover()
+
+	skip := len(indices) == 0 && options.GeneCrossover.Val.skip(options.Rand.Val)
+	if len(indices) == 0 && !skip && !method.realValued() && !method.permutation() {
+		crossover := options.GeneCrossover.Val
+		crossover.Method = NewOption(method)
+		var err error
+		indices, err = crossover.indices(options.Rand.Val, min_size)
+		if err != nil {
+			return err
 		}
-		indices = idxSet.toSlice()
-		sort.Ints(indices)
 	}
 	for _, i := range indices {
 		if 0 > i || i >= min_size {
@@ -127,18 +123,42 @@ func (g *Gene[T]) Recombine(other *Gene[T], indices []int, child *Gene[T],
 	}
 
 	name := g.Name
-	if name != other.Name {
+	if name != other.Name && !skip && !options.InheritNames.Val {
 		name_size, err := min(len(name), len(other.Name))
 		if err != nil {
 			return err
 		}
 		if name_size > 2 {
-			name_swap := RandomInt(1, name_size-1)
+			name_swap := randomInt(options.Rand.Val, 1, name_size-1)
 			name = g.Name[:name_swap] + other.Name[name_swap:]
 		}
 	}
 	child.Name = name
 
+	if skip {
+		child.Bases = make([]T, len(g.Bases))
+		copy(child.Bases, g.Bases)
+		return nil
+	}
+
+	if method.realValued() {
+		bases, err := realValuedCrossover(g.Bases, other.Bases, options)
+		if err != nil {
+			return err
+		}
+		child.Bases = bases
+		return nil
+	}
+
+	if method.permutation() {
+		bases, err := permutationCrossover(g.Bases, other.Bases, options)
+		if err != nil {
+			return err
+		}
+		child.Bases = bases
+		return nil
+	}
+
 	bases := make([]T, max_size)
 	copy(bases, g.Bases)
 	swapped := false
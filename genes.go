@@ -1,9 +1,5 @@
 package bluegenes
 
-import (
-	"math/rand"
-)
-
 var alphanumerics = []rune{
 	'a', 'b', 'c', 'd', 'e', 'f', 'g', 'h', 'i', 'j', 'k', 'l', 'm', 'n', 'o',
 	'p', 'q', 'r', 's', 't', 'u', 'v', 'w', 'x', 'y', 'z', 'A', 'B', 'C', 'D',
@@ -13,6 +9,10 @@ var alphanumerics = []rune{
 }
 
 func RandomName(size int) (string, error) {
+	return randomName(nil, size)
+}
+
+func randomName(rng *Rand, size int) (string, error) {
 	if size < 0 {
 		return "", anError{"size Must be > 0"}
 	}
@@ -20,14 +20,18 @@ func RandomName(size int) (string, error) {
 	s := ""
 	l := len(alphanumerics) - 1
 	for i := 0; i < size; i++ {
-		s = s + string(alphanumerics[RandomInt(0, l)])
+		s = s + string(alphanumerics[randomInt(rng, 0, l)])
 	}
 
 	return s, nil
 }
 
 func RandomInt(min, max int) int {
-	return min + rand.Intn(max-min)
+	return randomInt(nil, min, max)
+}
+
+func randomInt(rng *Rand, min, max int) int {
+	return min + rng.Intn(max-min)
 }
 
 type MakeOptions[T Ordered] struct {
@@ -37,6 +41,8 @@ type MakeOptions[T Ordered] struct {
 	NChromosomes Option[uint]
 	Name         Option[string]
 	BaseFactory  Option[func() T]
+	Rand         Option[*Rand]
+	Schema       Option[Schema[T]]
 }
 
 type RecombineOptions struct {
@@ -47,6 +53,21 @@ type RecombineOptions struct {
 	RecombineChromosomes Option[bool]
 	MatchChromosomes     Option[bool]
 	RecombineGenomes     Option[bool]
+	Rand                 Option[*Rand]
+	BaseCrossover        Option[CrossoverMethod]
+	CrossoverEta         Option[float64]
+	BlendAlpha           Option[float64]
+	ArithmeticWeight     Option[float64]
+	BaseBounds           Option[[]Bounds[float64]]
+	GeneCrossover        Option[CrossoverOptions]
+	NucleosomeCrossover  Option[CrossoverOptions]
+	ChromosomeCrossover  Option[CrossoverOptions]
+	GenomeCrossover      Option[CrossoverOptions]
+	AlignGenes           Option[AlignmentMethod]
+	AlignNucleosomes     Option[AlignmentMethod]
+	AlignChromosomes     Option[AlignmentMethod]
+	UnmatchedSubunits    Option[UnmatchedPolicy]
+	InheritNames         Option[bool]
 }
 
 func MakeGene[T Ordered](options MakeOptions[T]) (*Gene[T], error) {
@@ -54,9 +75,12 @@ func MakeGene[T Ordered](options MakeOptions[T]) (*Gene[T], error) {
 	if !options.NBases.Ok() {
 		return g, missingParameterError{"options.NBases"}
 	}
-	if !options.BaseFactory.Ok() {
+	if !options.BaseFactory.Ok() && !options.Schema.Ok() {
 		return g, missingParameterError{"options.BaseFactory"}
 	}
+	if options.Schema.Ok() {
+		return g, makeSchemaGene(g, options)
+	}
 	for i := 0; i < int(options.NBases.Val); i++ {
 		b := options.BaseFactory.Val()
 		g.Append(b)
@@ -64,11 +88,37 @@ func MakeGene[T Ordered](options MakeOptions[T]) (*Gene[T], error) {
 	if options.Name.Ok() {
 		g.Name = options.Name.Val
 	} else {
-		g.Name, _ = RandomName(4)
+		g.Name, _ = randomName(options.Rand.Val, 4)
 	}
 	return g, nil
 }
 
+// Names the Gene and fills it with NBases (limited to the Schema's Length)
+// bases from the BaseFactory, or drawn at random from the Schema if there is
+// no BaseFactory, and then repairs it to fit the Schema.
+func makeSchemaGene[T Ordered](g *Gene[T], options MakeOptions[T]) error {
+	if options.Name.Ok() {
+		g.Name = options.Name.Val
+	} else {
+		g.Name, _ = randomName(options.Rand.Val, 4)
+	}
+	schema := options.Schema.Val.gene(g.Name)
+	n_bases := schemaLength(int(options.NBases.Val), schema.Length)
+	for i := 0; i < n_bases; i++ {
+		if options.BaseFactory.Ok() {
+			g.Append(options.BaseFactory.Val())
+			continue
+		}
+		b, ok := schema.randomBase(i, options.Rand.Val)
+		if !ok {
+			return missingParameterError{"options.BaseFactory"}
+		}
+		g.Append(b)
+	}
+	schema.repair(g, ClampRepair)
+	return nil
+}
+
 func MakeNucleosome[T Ordered](options MakeOptions[T]) (*Nucleosome[T], error) {
 	a := &Nucleosome[T]{}
 	if !options.NGenes.Ok() {
@@ -77,10 +127,14 @@ func MakeNucleosome[T Ordered](options MakeOptions[T]) (*Nucleosome[T], error) {
 	if !options.NBases.Ok() {
 		return a, missingParameterError{"options.NBases"}
 	}
-	if !options.BaseFactory.Ok() {
+	if !options.BaseFactory.Ok() && !options.Schema.Ok() {
 		return a, missingParameterError{"options.BaseFactory"}
 	}
-	for i := 0; i < int(options.NGenes.Val); i++ {
+	n_genes := int(options.NGenes.Val)
+	if options.Schema.Ok() {
+		n_genes = schemaLength(n_genes, options.Schema.Val.NucleosomeLength)
+	}
+	for i := 0; i < n_genes; i++ {
 		g, err := MakeGene(options)
 		if err != nil {
 			return a, err
@@ -90,7 +144,10 @@ func MakeNucleosome[T Ordered](options MakeOptions[T]) (*Nucleosome[T], error) {
 	if options.Name.Ok() {
 		a.Name = options.Name.Val
 	} else {
-		a.Name, _ = RandomName(3)
+		a.Name, _ = randomName(options.Rand.Val, 3)
+	}
+	if options.Schema.Ok() {
+		options.Schema.Val.repairNucleosome(a, ClampRepair)
 	}
 	return a, nil
 }
@@ -106,10 +163,14 @@ func MakeChromosome[T Ordered](options MakeOptions[T]) (*Chromosome[T], error) {
 	if !options.NBases.Ok() {
 		return c, missingParameterError{"options.NBases"}
 	}
-	if !options.BaseFactory.Ok() {
+	if !options.BaseFactory.Ok() && !options.Schema.Ok() {
 		return c, missingParameterError{"options.BaseFactory"}
 	}
-	for i := 0; i < int(options.NNucleosomes.Val); i++ {
+	n_nucleosomes := int(options.NNucleosomes.Val)
+	if options.Schema.Ok() {
+		n_nucleosomes = schemaLength(n_nucleosomes, options.Schema.Val.ChromosomeLength)
+	}
+	for i := 0; i < n_nucleosomes; i++ {
 		a, err := MakeNucleosome(options)
 		if err != nil {
 			return c, err
@@ -119,7 +180,10 @@ func MakeChromosome[T Ordered](options MakeOptions[T]) (*Chromosome[T], error) {
 	if options.Name.Ok() {
 		c.Name = options.Name.Val
 	} else {
-		c.Name, _ = RandomName(2)
+		c.Name, _ = randomName(options.Rand.Val, 2)
+	}
+	if options.Schema.Ok() {
+		options.Schema.Val.repairChromosome(c, ClampRepair)
 	}
 	return c, nil
 }
@@ -127,7 +191,7 @@ func MakeChromosome[T Ordered](options MakeOptions[T]) (*Chromosome[T], error) {
 func MakeGenome[T Ordered](options MakeOptions[T]) (*Genome[T], error) {
 	g := &Genome[T]{}
 	if !options.NChromosomes.Ok() {
-		return g, missingParameterError{"options.NNucleosomes"}
+		return g, missingParameterError{"options.NChromosomes"}
 	}
 	if !options.NNucleosomes.Ok() {
 		return g, missingParameterError{"options.NNucleosomes"}
@@ -138,10 +202,14 @@ func MakeGenome[T Ordered](options MakeOptions[T]) (*Genome[T], error) {
 	if !options.NBases.Ok() {
 		return g, missingParameterError{"options.NBases"}
 	}
-	if !options.BaseFactory.Ok() {
+	if !options.BaseFactory.Ok() && !options.Schema.Ok() {
 		return g, missingParameterError{"options.BaseFactory"}
 	}
-	for i := 0; i < int(options.NNucleosomes.Val); i++ {
+	n_chromosomes := int(options.NChromosomes.Val)
+	if options.Schema.Ok() {
+		n_chromosomes = schemaLength(n_chromosomes, options.Schema.Val.GenomeLength)
+	}
+	for i := 0; i < n_chromosomes; i++ {
 		c, err := MakeChromosome(options)
 		if err != nil {
 			return g, err
@@ -151,11 +219,49 @@ func MakeGenome[T Ordered](options MakeOptions[T]) (*Genome[T], error) {
 	if options.Name.Ok() {
 		g.Name = options.Name.Val
 	} else {
-		g.Name, _ = RandomName(3)
+		g.Name, _ = randomName(options.Rand.Val, 3)
+	}
+	if options.Schema.Ok() {
+		options.Schema.Val.repairGenome(g, ClampRepair)
 	}
 	return g, nil
 }
 
+// Creates a randomized Code containing a newly made Gene, Nucleosome,
+// Chromosome, and/or Genome for each level that is set in the template.
+func MakeCode[T Ordered](options MakeOptions[T], template Code[T]) (Code[T], error) {
+	code := Code[T]{}
+	if template.Gene.Ok() {
+		gene, err := MakeGene(options)
+		if err != nil {
+			return code, err
+		}
+		code.Gene = NewOption(gene)
+	}
+	if template.Nucleosome.Ok() {
+		nucleosome, err := MakeNucleosome(options)
+		if err != nil {
+			return code, err
+		}
+		code.Nucleosome = NewOption(nucleosome)
+	}
+	if template.Chromosome.Ok() {
+		chromosome, err := MakeChromosome(options)
+		if err != nil {
+			return code, err
+		}
+		code.Chromosome = NewOption(chromosome)
+	}
+	if template.Genome.Ok() {
+		genome, err := MakeGenome(options)
+		if err != nil {
+			return code, err
+		}
+		code.Genome = NewOption(genome)
+	}
+	return code, nil
+}
+
 func breakSequence[T Ordered](sequence []T, separator []T) ([]T, []T) {
 	var part []T
 	for i, l1, l2 := 0, len(sequence), len(separator); i < l1-l2; i++ {
@@ -167,6 +273,15 @@ func breakSequence[T Ordered](sequence []T, separator []T) ([]T, []T) {
 	return []T{}, sequence
 }
 
+// Returns a copy of the subunits made with copy_subunit.
+func copySubunits[S any](subunits []S, copy_subunit func(S) S) []S {
+	copied := make([]S, len(subunits))
+	for i, subunit := range subunits {
+		copied[i] = copy_subunit(subunit)
+	}
+	return copied
+}
+
 func inverseSequence[T Ordered](separator []T) []T {
 	result := []T{}
 	var v interface{}
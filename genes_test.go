@@ -217,6 +217,24 @@ func TestGene(t *testing.T) {
 		}
 	})
 
+	t.Run("Insert/end", func(t *testing.T) {
+		t.Parallel()
+		g := firstGene()
+		if err := g.Insert(len(g.Bases), 15); err != nil {
+			t.Fatalf("Gene[int].Insert at the end failed with error: %v", err)
+		}
+		if !equal(g.Bases, []int{1, 2, 3, 15}) {
+			t.Errorf("Gene[int].Insert at the end failed: expected [1 2 3 15], observed %v", g.Bases)
+		}
+		empty := &Gene[int]{}
+		if err := empty.Insert(0, 7); err != nil || !equal(empty.Bases, []int{7}) {
+			t.Errorf("Gene[int].Insert into an empty Gene failed: observed %v, error %v", empty.Bases, err)
+		}
+		if err := g.Insert(len(g.Bases)+1, 0); err == nil {
+			t.Errorf("Gene[int].Insert failed to reject an index past the end")
+		}
+	})
+
 	t.Run("Append", func(t *testing.T) {
 		t.Parallel()
 		g := firstGene()
@@ -434,6 +452,25 @@ func TestGene(t *testing.T) {
 }
 
 func TestNucleosome(t *testing.T) {
+	t.Run("DeepCopy", func(t *testing.T) {
+		t.Parallel()
+		a := firstNucleosome()
+		c := a.DeepCopy()
+
+		if c == a || c.Name != a.Name || len(c.Genes) != len(a.Genes) {
+			t.Fatal("Nucleosome[int].DeepCopy failed to copy Name and Genes")
+		}
+
+		for i, gene := range c.Genes {
+			if gene == a.Genes[i] {
+				t.Errorf("Nucleosome[int].DeepCopy failed; Gene %d is shared", i)
+			}
+			if gene.Name != a.Genes[i].Name || !equal(gene.Bases, a.Genes[i].Bases) {
+				t.Errorf("Nucleosome[int].DeepCopy failed to copy Genes: got %v, expected %v", gene.ToMap(), a.Genes[i].ToMap())
+			}
+		}
+	})
+
 	t.Run("Copy", func(t *testing.T) {
 		t.Parallel()
 		a := firstNucleosome()
@@ -662,6 +699,27 @@ func TestNucleosome(t *testing.T) {
 }
 
 func TestChromosome(t *testing.T) {
+	t.Run("DeepCopy", func(t *testing.T) {
+		t.Parallel()
+		c := firstChromosome()
+		p := c.DeepCopy()
+
+		if p == c || p.Name != c.Name || len(p.Nucleosomes) != len(c.Nucleosomes) {
+			t.Fatal("Chromosome[int].DeepCopy failed to copy Name and Nucleosomes")
+		}
+
+		for i, nucleosome := range p.Nucleosomes {
+			if nucleosome == c.Nucleosomes[i] {
+				t.Errorf("Chromosome[int].DeepCopy failed; Nucleosome %d is shared", i)
+			}
+			for k, gene := range nucleosome.Genes {
+				if gene == c.Nucleosomes[i].Genes[k] || !equal(gene.Bases, c.Nucleosomes[i].Genes[k].Bases) {
+					t.Errorf("Chromosome[int].DeepCopy failed to copy Genes: got %v, expected %v", gene.ToMap(), c.Nucleosomes[i].Genes[k].ToMap())
+				}
+			}
+		}
+	})
+
 	t.Run("Copy", func(t *testing.T) {
 		t.Parallel()
 		c := firstChromosome()
@@ -978,6 +1036,29 @@ func TestChromosome(t *testing.T) {
 }
 
 func TestGenome(t *testing.T) {
+	t.Run("DeepCopy", func(t *testing.T) {
+		t.Parallel()
+		g := firstGenome()
+		p := g.DeepCopy()
+
+		if p == g || p.Name != g.Name || len(p.Chromosomes) != len(g.Chromosomes) {
+			t.Fatal("Genome[int].DeepCopy failed to copy Name and Chromosomes")
+		}
+
+		for i, chromosome := range p.Chromosomes {
+			if chromosome == g.Chromosomes[i] {
+				t.Errorf("Genome[int].DeepCopy failed; Chromosome %d is shared", i)
+			}
+			if !equal(chromosome.Sequence([]int{0}), g.Chromosomes[i].Sequence([]int{0})) {
+				t.Errorf("Genome[int].DeepCopy failed to copy Chromosome %d", i)
+			}
+			chromosome.Nucleosomes[0].Genes[0].Bases[0] = 1000
+			if g.Chromosomes[i].Nucleosomes[0].Genes[0].Bases[0] == 1000 {
+				t.Errorf("Genome[int].DeepCopy failed; Bases of Chromosome %d are shared", i)
+			}
+		}
+	})
+
 	t.Run("Copy", func(t *testing.T) {
 		t.Parallel()
 		g := firstGenome()
@@ -1275,3 +1356,37 @@ func TestGenome(t *testing.T) {
 		}
 	})
 }
+
+func TestMakeCode(t *testing.T) {
+	opts := MakeOptions[int]{
+		NBases:       NewOption(uint(3)),
+		NGenes:       NewOption(uint(2)),
+		NNucleosomes: NewOption(uint(2)),
+		NChromosomes: NewOption(uint(2)),
+		BaseFactory:  NewOption(factory),
+	}
+	template := Code[int]{
+		Gene:       NewOption(firstGene()),
+		Chromosome: NewOption(firstChromosome()),
+	}
+
+	code, err := MakeCode(opts, template)
+	if err != nil {
+		t.Fatalf("MakeCode[int] failed with error: %v", err)
+	}
+
+	if !code.Gene.Ok() || !code.Chromosome.Ok() {
+		t.Errorf("MakeCode[int] failed to make the levels set in the template")
+	}
+	if code.Nucleosome.Ok() || code.Genome.Ok() {
+		t.Errorf("MakeCode[int] made levels not set in the template")
+	}
+	if len(code.Gene.Val.Bases) != 3 || len(code.Chromosome.Val.Nucleosomes) != 2 {
+		t.Errorf("MakeCode[int] failed to follow MakeOptions")
+	}
+
+	_, err = MakeCode(MakeOptions[int]{}, template)
+	if err == nil {
+		t.Errorf("MakeCode[int] failed to return error for missing options")
+	}
+}
@@ -1,8 +1,6 @@
 package bluegenes
 
 import (
-	"math"
-	"sort"
 	"sync"
 )
 
@@ -22,6 +20,13 @@ func (g *Genome[T]) Copy() *Genome[T] {
 	return &another
 }
 
+// Copies the Genome along with all of its underlying genetic material.
+func (g *Genome[T]) DeepCopy() *Genome[T] {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	return &Genome[T]{Name: g.Name, Chromosomes: copySubunits(g.Chromosomes, (*Chromosome[T]).DeepCopy)}
+}
+
 func (g *Genome[T]) Insert(index int, chromosome *Chromosome[T]) error {
 	g.Mu.Lock()
 	defer g.Mu.Unlock()
@@ -86,15 +91,15 @@ func (g *Genome[T]) Recombine(other *Genome[T], indices []int,
 	min_size, _ := min(len(g.Chromosomes), len(other.Chromosomes))
 	max_size, _ := max(len(g.Chromosomes), len(other.Chromosomes))
 
-	if len(indices) == 0 && min_size > 1 {
-		max_swaps := math.Ceil(math.Log(float64(min_size)))
-		swaps, _ := max(RandomInt(0, int(max_swaps)), 1)
-		idxSet := newSet[int]()
-		for i := 0; i < swaps; i++ {
-			idxSet.add(RandomInt(0, min_size))
+	aligned := len(indices) == 0 && options.AlignChromosomes.Ok() &&
+		options.AlignChromosomes.Val != PositionalAlignment
+	skip := len(indices) == 0 && options.GenomeCrossover.Val.skip(options.Rand.Val)
+	if len(indices) == 0 && !skip && !aligned {
+		var err error
+		indices, err = options.GenomeCrossover.Val.indices(options.Rand.Val, min_size)
+		if err != nil {
+			return err
 		}
-		indices = idxSet.toSlice()
-		sort.Ints(indices)
 	}
 	for _, i := range indices {
 		if 0 > i || i >= min_size {
@@ -102,18 +107,37 @@ func (g *Genome[T]) Recombine(other *Genome[T], indices []int,
 		}
 	}
 	name := g.Name
-	if name != other.Name {
+	if name != other.Name && !skip && !options.InheritNames.Val {
 		name_size, err := min(len(name), len(other.Name))
 		if err != nil {
 			return err
 		}
 		if name_size > 2 {
-			name_swap := RandomInt(1, name_size-1)
+			name_swap := randomInt(options.Rand.Val, 1, name_size-1)
 			name = g.Name[:name_swap] + other.Name[name_swap:]
 		}
 	}
 	child.Name = name
 
+	if aligned {
+		chromosomes, err := recombineAligned(g.Chromosomes, other.Chromosomes,
+			func(chromosome *Chromosome[T]) string { return chromosome.Name }, options.AlignChromosomes.Val,
+			options.GenomeCrossover.Val, options, skip, (*Chromosome[T]).DeepCopy,
+			func(dad, mom *Chromosome[T]) (*Chromosome[T], error) {
+				if (!options.RecombineChromosomes.Ok() || options.RecombineChromosomes.Val) &&
+					(!options.MatchChromosomes.Val || dad.Name == mom.Name) {
+					chromosome := &Chromosome[T]{}
+					return chromosome, dad.Recombine(mom, []int{}, chromosome, options)
+				}
+				return dad.DeepCopy(), nil
+			})
+		if err != nil {
+			return err
+		}
+		child.Chromosomes = chromosomes
+		return nil
+	}
+
 	for len(child.Chromosomes) < max_size {
 		child.Chromosomes = append(child.Chromosomes, &Chromosome[T]{})
 	}
@@ -0,0 +1,178 @@
+package bluegenes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Markers that separate the levels of genetic material in a hash so that,
+// e.g., a Nucleosome with one Gene does not hash the same as that Gene.
+const (
+	hashGene byte = iota + 1
+	hashNucleosome
+	hashChromosome
+	hashGenome
+)
+
+func hashUint64(h io.Writer, value uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], value)
+	h.Write(buf[:])
+}
+
+func hashBase[T Ordered](h io.Writer, base T) {
+	switch b := any(base).(type) {
+	case int:
+		hashUint64(h, uint64(b))
+	case int64:
+		hashUint64(h, uint64(b))
+	case uint64:
+		hashUint64(h, b)
+	case float64:
+		hashUint64(h, math.Float64bits(b))
+	case string:
+		hashUint64(h, uint64(len(b)))
+		h.Write([]byte(b))
+	default:
+		v := reflect.ValueOf(base)
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			hashUint64(h, uint64(v.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			hashUint64(h, v.Uint())
+		case reflect.Float32, reflect.Float64:
+			hashUint64(h, math.Float64bits(v.Float()))
+		default:
+			hashUint64(h, uint64(v.Len()))
+			h.Write([]byte(v.String()))
+		}
+	}
+}
+
+func hashName(h io.Writer, name string) {
+	hashUint64(h, uint64(len(name)))
+	h.Write([]byte(name))
+}
+
+// Writes the Bases and structure of the Gene to h, and its Name if names is
+// true.
+func (g *Gene[T]) writeHash(h io.Writer, names bool) {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	h.Write([]byte{hashGene})
+	if names {
+		hashName(h, g.Name)
+	}
+	hashUint64(h, uint64(len(g.Bases)))
+	for _, base := range g.Bases {
+		hashBase(h, base)
+	}
+}
+
+func (n *Nucleosome[T]) writeHash(h io.Writer, names bool) {
+	n.Mu.RLock()
+	defer n.Mu.RUnlock()
+	h.Write([]byte{hashNucleosome})
+	if names {
+		hashName(h, n.Name)
+	}
+	hashUint64(h, uint64(len(n.Genes)))
+	for _, gene := range n.Genes {
+		gene.writeHash(h, names)
+	}
+}
+
+func (c *Chromosome[T]) writeHash(h io.Writer, names bool) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+	h.Write([]byte{hashChromosome})
+	if names {
+		hashName(h, c.Name)
+	}
+	hashUint64(h, uint64(len(c.Nucleosomes)))
+	for _, nucleosome := range c.Nucleosomes {
+		nucleosome.writeHash(h, names)
+	}
+}
+
+func (g *Genome[T]) writeHash(h io.Writer, names bool) {
+	g.Mu.RLock()
+	defer g.Mu.RUnlock()
+	h.Write([]byte{hashGenome})
+	if names {
+		hashName(h, g.Name)
+	}
+	hashUint64(h, uint64(len(g.Chromosomes)))
+	for _, chromosome := range g.Chromosomes {
+		chromosome.writeHash(h, names)
+	}
+}
+
+// Returns a 64-bit FNV-1a hash of the Bases. Names are not included, so Genes
+// with the same Bases have the same Hash.
+func (g *Gene[T]) Hash() uint64 {
+	h := fnv.New64a()
+	g.writeHash(h, false)
+	return h.Sum64()
+}
+
+// Returns a 64-bit FNV-1a hash of the Bases and structure of the Genes. Names
+// are not included.
+func (n *Nucleosome[T]) Hash() uint64 {
+	h := fnv.New64a()
+	n.writeHash(h, false)
+	return h.Sum64()
+}
+
+// Returns a 64-bit FNV-1a hash of the Bases and structure of the Nucleosomes.
+// Names are not included.
+func (c *Chromosome[T]) Hash() uint64 {
+	h := fnv.New64a()
+	c.writeHash(h, false)
+	return h.Sum64()
+}
+
+// Returns a 64-bit FNV-1a hash of the Bases and structure of the Chromosomes.
+// Names are not included.
+func (g *Genome[T]) Hash() uint64 {
+	h := fnv.New64a()
+	g.writeHash(h, false)
+	return h.Sum64()
+}
+
+// Returns a 64-bit FNV-1a hash of every level of genetic material that is set.
+// Code with identical Bases and structure has the same Hash. Strategy is not
+// included.
+func (c Code[T]) Hash() uint64 {
+	h := fnv.New64a()
+	c.writeHash(h, false)
+	return h.Sum64()
+}
+
+func (c Code[T]) writeHash(h io.Writer, names bool) {
+	if c.Gene.Ok() {
+		c.Gene.Val.writeHash(h, names)
+	}
+	if c.Nucleosome.Ok() {
+		c.Nucleosome.Val.writeHash(h, names)
+	}
+	if c.Chromosome.Ok() {
+		c.Chromosome.Val.writeHash(h, names)
+	}
+	if c.Genome.Ok() {
+		c.Genome.Val.writeHash(h, names)
+	}
+}
+
+// Returns the exact Bases, structure, and names of every level of genetic
+// material that is set, for use as a key where hash collisions are not
+// acceptable.
+func (c Code[T]) key() string {
+	var buf bytes.Buffer
+	c.writeHash(&buf, true)
+	return buf.String()
+}
@@ -0,0 +1,165 @@
+package bluegenes
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+)
+
+type baseName string
+
+func TestHash(t *testing.T) {
+	t.Run("Gene", func(t *testing.T) {
+		t.Parallel()
+		g1 := &Gene[int]{Name: "one", Bases: []int{1, 2, 3}}
+		g2 := &Gene[int]{Name: "two", Bases: []int{1, 2, 3}}
+		g3 := &Gene[int]{Name: "one", Bases: []int{1, 2, 4}}
+		if g1.Hash() != g2.Hash() {
+			t.Error("Gene.Hash failed: identical Bases produced different hashes")
+		}
+		if g1.Hash() == g3.Hash() {
+			t.Error("Gene.Hash failed: different Bases produced the same hash")
+		}
+		if g1.Hash() != g1.Copy().Hash() {
+			t.Error("Gene.Hash failed: copy produced a different hash")
+		}
+	})
+
+	t.Run("types", func(t *testing.T) {
+		t.Parallel()
+		f1 := &Gene[float64]{Bases: []float64{0.5, 1.5}}
+		f2 := &Gene[float64]{Bases: []float64{0.5, 1.25}}
+		s1 := &Gene[string]{Bases: []string{"ab", "c"}}
+		s2 := &Gene[string]{Bases: []string{"a", "bc"}}
+		n1 := &Gene[baseName]{Bases: []baseName{"ab", "c"}}
+		n2 := &Gene[baseName]{Bases: []baseName{"a", "bc"}}
+		u1 := &Gene[uint8]{Bases: []uint8{1, 2}}
+		u2 := &Gene[uint8]{Bases: []uint8{2, 1}}
+		if f1.Hash() == f2.Hash() || s1.Hash() == s2.Hash() || n1.Hash() == n2.Hash() || u1.Hash() == u2.Hash() {
+			t.Error("Gene.Hash failed to distinguish Bases")
+		}
+		if s1.Hash() != n1.Hash() {
+			t.Error("Gene.Hash failed: string types with identical Bases produced different hashes")
+		}
+	})
+
+	t.Run("structure", func(t *testing.T) {
+		t.Parallel()
+		n1 := &Nucleosome[int]{Genes: []*Gene[int]{{Bases: []int{1, 2}}, {Bases: []int{3}}}}
+		n2 := &Nucleosome[int]{Genes: []*Gene[int]{{Bases: []int{1}}, {Bases: []int{2, 3}}}}
+		if n1.Hash() == n2.Hash() {
+			t.Error("Nucleosome.Hash failed: different structures produced the same hash")
+		}
+		if n1.Hash() != n1.DeepCopy().Hash() {
+			t.Error("Nucleosome.Hash failed: copy produced a different hash")
+		}
+
+		gene := &Gene[int]{Bases: []int{1, 2}}
+		nucleosome := &Nucleosome[int]{Genes: []*Gene[int]{gene}}
+		if gene.Hash() == nucleosome.Hash() {
+			t.Error("Nucleosome.Hash failed: matched the hash of its only Gene")
+		}
+
+		genome, _ := rangeGenome(2, 2, 2, 0, 4)
+		other, _ := rangeGenome(2, 2, 2, 0, 4, "other")
+		if genome.Hash() != other.Hash() {
+			t.Error("Genome.Hash failed: identical Bases produced different hashes")
+		}
+		other.Chromosomes[1].Nucleosomes[1].Genes[1].Bases[3]++
+		if genome.Hash() == other.Hash() {
+			t.Error("Genome.Hash failed: different Bases produced the same hash")
+		}
+	})
+
+	t.Run("Code", func(t *testing.T) {
+		t.Parallel()
+		gene := firstGene()
+		c1 := Code[int]{Gene: NewOption(gene)}
+		c2 := Code[int]{Gene: NewOption(gene), Nucleosome: NewOption(firstNucleosome())}
+		if c1.Hash() == c2.Hash() || c1.Hash() != c1.DeepCopy().Hash() {
+			t.Error("Code.Hash failed")
+		}
+	})
+}
+
+func TestFitnessCache(t *testing.T) {
+	t.Run("LRU", func(t *testing.T) {
+		t.Parallel()
+		cache := NewFitnessCache(2)
+		cache.put("1", 0.1)
+		cache.put("2", 0.2)
+		if score, ok := cache.get("1"); !ok || score != 0.1 {
+			t.Fatalf("FitnessCache failed: expected 0.1, observed %f", score)
+		}
+		cache.put("3", 0.3)
+		if _, ok := cache.get("2"); ok {
+			t.Error("FitnessCache failed to evict the least recently used score")
+		}
+		if _, ok := cache.get("1"); !ok {
+			t.Error("FitnessCache evicted a recently used score")
+		}
+		if cache.Len() != 2 || cache.Hits() != 2 || cache.Misses() != 1 {
+			t.Errorf("FitnessCache failed: expected len 2, 2 hits, 1 miss; observed %d, %d, %d",
+				cache.Len(), cache.Hits(), cache.Misses())
+		}
+	})
+
+	t.Run("key", func(t *testing.T) {
+		t.Parallel()
+		cache := NewFitnessCache(10)
+		params := OptimizationParams[int]{
+			FitnessCache: NewOption(cache),
+			MeasureFitness: NewOption(func(code Code[int]) float64 {
+				if code.Gene.Val.Name == "b" {
+					return 0.2
+				}
+				return 0.1
+			}),
+		}
+		a := Code[int]{Gene: NewOption(&Gene[int]{Name: "a", Bases: []int{1, 2}})}
+		b := Code[int]{Gene: NewOption(&Gene[int]{Name: "b", Bases: []int{1, 2}})}
+		if a.Hash() != b.Hash() || a.key() == b.key() {
+			t.Fatalf("Code.key failed to distinguish names that Code.Hash ignores")
+		}
+		measureFitness(params, a)
+		if score, cached := measureFitness(params, b); cached || score != 0.2 {
+			t.Errorf("FitnessCache failed: expected uncached 0.2 for renamed Code, observed %v (cached %v)", score, cached)
+		}
+		if score, cached := measureFitness(params, a.DeepCopy()); !cached || score != 0.1 {
+			t.Errorf("FitnessCache failed: expected cached 0.1 for identical Code, observed %v (cached %v)", score, cached)
+		}
+	})
+
+	for _, parallel_count := range []int{1, 4} {
+		parallel_count := parallel_count
+		t.Run(fmt.Sprintf("Optimize/%d", parallel_count), func(t *testing.T) {
+			t.Parallel()
+			var calls int64
+			cache := NewFitnessCache(1000)
+			report, err := OptimizeWithReport(OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(10)),
+				MeasureFitness: NewOption(func(code Code[int]) float64 {
+					atomic.AddInt64(&calls, 1)
+					return measureCodeFitness(code)
+				}),
+				Mutate:        NewOption(func(code *Code[int]) {}),
+				MaxIterations: NewOption(20),
+				FitnessTarget: NewOption(2.0),
+				ParallelCount: NewOption(parallel_count),
+				FitnessCache:  NewOption(cache),
+			})
+
+			if err != nil {
+				t.Fatalf("Optimize with FitnessCache failed with error: %v", err)
+			}
+			if report.CacheHits == 0 || report.CacheHits != cache.Hits() {
+				t.Errorf("Optimize failed to use the FitnessCache: %d hits reported, %d hits in cache",
+					report.CacheHits, cache.Hits())
+			}
+			if int(calls) != cache.Misses() || report.Evaluations != int(calls) {
+				t.Errorf("Optimize failed: %d calls to MeasureFitness, %d misses, %d evaluations",
+					calls, cache.Misses(), report.Evaluations)
+			}
+		})
+	}
+}
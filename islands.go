@@ -0,0 +1,276 @@
+package bluegenes
+
+import (
+	"context"
+	"sync"
+)
+
+type MigrationTopology int
+
+const (
+	// Each island sends migrants to the next island in the ring.
+	RingTopology MigrationTopology = iota
+	// Each island sends migrants to every other island.
+	FullyConnectedTopology
+	// Each island sends migrants to one other island chosen at random for
+	// every migration.
+	RandomTopology
+)
+
+type ReplacementPolicy int
+
+const (
+	// Migrants replace the worst members of the destination island.
+	ReplaceWorst ReplacementPolicy = iota
+	// Migrants replace random members of the destination island other than
+	// its best member.
+	ReplaceRandom
+	// Migrants replace the worst members of the destination island only if
+	// they have a higher Score.
+	ReplaceWorstIfBetter
+)
+
+type IslandParams[T Ordered] struct {
+	Optimization      OptimizationParams[T]
+	Islands           Option[int]
+	Topology          Option[MigrationTopology]
+	MigrationInterval Option[int]
+	MigrantCount      Option[int]
+	Replacement       Option[ReplacementPolicy]
+	MigrationHook     Option[func(int, [][]*ScoredCode[T])]
+	IterationHook     Option[func(int, int, []*ScoredCode[T])]
+	StatsHook         Option[func(int, GenerationStats)]
+}
+
+func OptimizeIslands[T Ordered](params IslandParams[T]) (int, []*ScoredCode[T], error) {
+	return OptimizeIslandsContext(context.Background(), params)
+}
+
+// Runs params.Islands independent populations, each in its own goroutine, and
+// migrates the best params.MigrantCount members of each island along the
+// params.Topology every params.MigrationInterval generations. Each island uses
+// params.Optimization (PopulationSize, MaxIterations and MaxEvaluations are per
+// island). The run stops once every island is done or any island reaches the
+// FitnessTarget, and the combined population of all islands is returned sorted
+// by Score. params.IterationHook and params.StatsHook receive the island index
+// first and are never called concurrently; params.StatsHook is also called with
+// each island's initial population as generation 0. params.Optimization's
+// IterationHook and StatsHook are rejected because they could not tell the
+// islands apart.
+func OptimizeIslandsContext[T Ordered](ctx context.Context, params IslandParams[T]) (int, []*ScoredCode[T], error) {
+	opt_params, err := prepareOptimizationParams(params.Optimization)
+	if err != nil {
+		return 0, []*ScoredCode[T]{}, err
+	}
+	if opt_params.IterationHook.Ok() {
+		return 0, []*ScoredCode[T]{}, anError{"params.Optimization.IterationHook is not supported; use params.IterationHook"}
+	}
+	if opt_params.StatsHook.Ok() {
+		return 0, []*ScoredCode[T]{}, anError{"params.Optimization.StatsHook is not supported; use params.StatsHook"}
+	}
+	if !params.Islands.Ok() {
+		params.Islands.Val = 4
+	}
+	if params.Islands.Val < 1 {
+		return 0, []*ScoredCode[T]{}, anError{"params.Islands must be at least 1"}
+	}
+	if !params.MigrationInterval.Ok() || params.MigrationInterval.Val < 1 {
+		params.MigrationInterval.Val = 10
+	}
+	if !params.MigrantCount.Ok() {
+		params.MigrantCount.Val = 2
+	}
+	if opt_params.TimeLimit.Ok() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt_params.TimeLimit.Val)
+		defer cancel()
+	}
+
+	// the islands share the hooks, so calls to them are serialized
+	var hook_mu sync.Mutex
+	rng := opt_params.Rand.Val
+	runs := make([]*optimizationRun[T], params.Islands.Val)
+	for i, population := range splitPopulation(opt_params.InitialPopulation.Val, params.Islands.Val) {
+		i := i
+		island_params := opt_params
+		island_params.InitialPopulation = NewOption(population)
+		if params.IterationHook.Ok() {
+			island_params.IterationHook = NewOption(func(generation int, scores []*ScoredCode[T]) {
+				hook_mu.Lock()
+				defer hook_mu.Unlock()
+				params.IterationHook.Val(i, generation, scores)
+			})
+		}
+		if params.StatsHook.Ok() {
+			island_params.StatsHook = NewOption(func(stats GenerationStats) {
+				hook_mu.Lock()
+				defer hook_mu.Unlock()
+				params.StatsHook.Val(i, stats)
+			})
+		}
+		// each island has its own stream so that islands stay reproducible
+		island_params.Rand = NewOption(rng.Split())
+		runs[i], err = newOptimizationRun(ctx, island_params, optimizeSequentially[T])
+		if err != nil {
+			count, scores, _ := islandResults(runs[:i+1])
+			return count, scores, err
+		}
+		runs[i].recordStats()
+	}
+
+	for epoch := 1; !islandsDone(runs); epoch++ {
+		var wg sync.WaitGroup
+		errs := make([]error, len(runs))
+		for i, r := range runs {
+			wg.Add(1)
+			go func(i int, r *optimizationRun[T]) {
+				defer wg.Done()
+				for g := 0; g < params.MigrationInterval.Val && !r.done(); g++ {
+					if errs[i] = ctx.Err(); errs[i] != nil {
+						return
+					}
+					if errs[i] = r.step(ctx); errs[i] != nil {
+						return
+					}
+				}
+			}(i, r)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			if err != nil {
+				count, scores, _ := islandResults(runs)
+				return count, scores, err
+			}
+		}
+
+		if len(runs) > 1 && params.MigrantCount.Val > 0 {
+			migrate(runs, params, rng)
+		}
+
+		if params.MigrationHook.Ok() {
+			populations := make([][]*ScoredCode[T], len(runs))
+			for i, r := range runs {
+				populations[i] = r.scores
+			}
+			params.MigrationHook.Val(epoch, populations)
+		}
+	}
+
+	return islandResults(runs)
+}
+
+// Distributes the population among the islands round-robin. If there are
+// fewer members than islands, each island receives a copy of the population.
+func splitPopulation[T Ordered](population []Code[T], islands int) [][]Code[T] {
+	populations := make([][]Code[T], islands)
+	if len(population) < islands {
+		for i := range populations {
+			for _, code := range population {
+				populations[i] = append(populations[i], code.DeepCopy())
+			}
+		}
+		return populations
+	}
+	for i, code := range population {
+		populations[i%islands] = append(populations[i%islands], code)
+	}
+	return populations
+}
+
+// Returns true if every island is done or any island reached the target.
+func islandsDone[T Ordered](runs []*optimizationRun[T]) bool {
+	all_done := true
+	for _, r := range runs {
+		if r.targetReached() {
+			return true
+		}
+		all_done = all_done && r.done()
+	}
+	return all_done
+}
+
+func islandResults[T Ordered](runs []*optimizationRun[T]) (int, []*ScoredCode[T], error) {
+	generation_count := 0
+	scores := []*ScoredCode[T]{}
+	for _, r := range runs {
+		if r == nil {
+			continue
+		}
+		generation_count, _ = max(generation_count, r.generationCount)
+		scores = append(scores, r.scores...)
+	}
+	direction := Maximize
+	if len(runs) > 0 && runs[0] != nil {
+		direction = runs[0].params.Direction.Val
+	}
+	sortFeasible(scores, direction)
+	return generation_count, scores, nil
+}
+
+// Returns the destinations for migrants from the island at index source.
+func migrationDestinations(topology MigrationTopology, source, islands int, rng *Rand) []int {
+	switch topology {
+	case FullyConnectedTopology:
+		destinations := []int{}
+		for i := 0; i < islands; i++ {
+			if i != source {
+				destinations = append(destinations, i)
+			}
+		}
+		return destinations
+	case RandomTopology:
+		destination := randomInt(rng, 0, islands-1)
+		if destination >= source {
+			destination++
+		}
+		return []int{destination}
+	default:
+		return []int{(source + 1) % islands}
+	}
+}
+
+func migrate[T Ordered](runs []*optimizationRun[T], params IslandParams[T], rng *Rand) {
+	// choose all migrants before any island receives them
+	incoming := make([][]*ScoredCode[T], len(runs))
+	for source, r := range runs {
+		count, _ := min(params.MigrantCount.Val, len(r.scores))
+		for _, destination := range migrationDestinations(params.Topology.Val, source, len(runs), rng) {
+			for _, migrant := range r.scores[:count] {
+				incoming[destination] = append(incoming[destination], &ScoredCode[T]{
+					Code: migrant.Code.DeepCopy(), Score: migrant.Score, Violation: migrant.Violation, Age: migrant.Age,
+				})
+			}
+		}
+	}
+	for i, r := range runs {
+		r.receiveMigrants(incoming[i], params.Replacement.Val, rng)
+	}
+}
+
+func (r *optimizationRun[T]) receiveMigrants(migrants []*ScoredCode[T], policy ReplacementPolicy,
+	rng *Rand) {
+	// the best member is never replaced
+	n_replaced, _ := min(len(migrants), len(r.scores)-1)
+	if n_replaced < 1 {
+		return
+	}
+	targets := make([]int, 0, n_replaced)
+	if policy == ReplaceRandom {
+		for _, i := range rng.Perm(len(r.scores) - 1)[:n_replaced] {
+			targets = append(targets, i+1)
+		}
+	} else {
+		for i := 0; i < n_replaced; i++ {
+			targets = append(targets, len(r.scores)-1-i)
+		}
+	}
+
+	for i, target := range targets {
+		if policy == ReplaceWorstIfBetter && !feasiblyBetter(migrants[i], r.scores[target], r.params.Direction.Val) {
+			continue
+		}
+		r.putScoredCode(r.scores[target])
+		r.scores[target] = migrants[i]
+	}
+	r.rank()
+}
@@ -0,0 +1,221 @@
+package bluegenes
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func islandRun(scores ...float64) *optimizationRun[int] {
+	r := &optimizationRun[int]{params: OptimizationParams[int]{FitnessTarget: NewOption(2.0)}}
+	for i, score := range scores {
+		gene := &Gene[int]{Name: "island", Bases: []int{i}}
+		r.scores = append(r.scores, &ScoredCode[int]{
+			Code: Code[int]{Gene: NewOption(gene)}, Score: score,
+		})
+	}
+	sortScoredCodes(r.scores, Maximize)
+	r.bestFitness = r.scores[0].Score
+	return r
+}
+
+func TestIslands(t *testing.T) {
+	t.Run("migrationDestinations", func(t *testing.T) {
+		t.Parallel()
+		if d := migrationDestinations(RingTopology, 3, 4, nil); !equal(d, []int{0}) {
+			t.Errorf("migrationDestinations failed for RingTopology: expected [0], observed %v", d)
+		}
+		if d := migrationDestinations(FullyConnectedTopology, 1, 4, nil); !equal(d, []int{0, 2, 3}) {
+			t.Errorf("migrationDestinations failed for FullyConnectedTopology: expected [0 2 3], observed %v", d)
+		}
+		for i := 0; i < 100; i++ {
+			d := migrationDestinations(RandomTopology, 2, 4, nil)
+			if len(d) != 1 || d[0] == 2 || d[0] < 0 || d[0] > 3 {
+				t.Fatalf("migrationDestinations failed for RandomTopology: observed %v", d)
+			}
+		}
+	})
+
+	t.Run("receiveMigrants", func(t *testing.T) {
+		t.Parallel()
+		migrants := islandRun(0.95, 0.05).scores
+
+		r := islandRun(0.9, 0.5, 0.4, 0.1)
+		r.receiveMigrants(migrants, ReplaceWorst, nil)
+		observed := scoresOf(r.scores)
+		if !equal(observed, []float64{0.95, 0.9, 0.5, 0.05}) {
+			t.Errorf("receiveMigrants failed for ReplaceWorst: observed %v", observed)
+		}
+		if r.bestFitness != 0.95 {
+			t.Errorf("receiveMigrants failed to update bestFitness: observed %f", r.bestFitness)
+		}
+
+		r = islandRun(0.9, 0.5, 0.4, 0.1)
+		r.receiveMigrants(migrants, ReplaceWorstIfBetter, nil)
+		observed = scoresOf(r.scores)
+		if !equal(observed, []float64{0.95, 0.9, 0.5, 0.4}) {
+			t.Errorf("receiveMigrants failed for ReplaceWorstIfBetter: observed %v", observed)
+		}
+
+		r = islandRun(0.9, 0.5, 0.4, 0.1)
+		r.receiveMigrants(migrants, ReplaceRandom, nil)
+		observed = scoresOf(r.scores)
+		if !contains(observed, 0.9) || !contains(observed, 0.95) || len(observed) != 4 {
+			t.Errorf("receiveMigrants failed for ReplaceRandom: observed %v", observed)
+		}
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		t.Parallel()
+		runs := []*optimizationRun[int]{
+			islandRun(0.9, 0.2, 0.1), islandRun(0.8, 0.2, 0.1), islandRun(0.7, 0.2, 0.1),
+		}
+		source_best := runs[0].scores[0]
+		migrate(runs, IslandParams[int]{MigrantCount: NewOption(1)}, nil)
+
+		if runs[1].scores[0].Score != 0.9 || runs[2].scores[0].Score != 0.8 || runs[0].scores[0].Score != 0.9 {
+			t.Fatalf("migrate failed to move the best members along the ring")
+		}
+		if runs[1].scores[0] == source_best || runs[1].scores[0].Code.Gene.Val == source_best.Code.Gene.Val {
+			t.Errorf("migrate failed to copy the migrant")
+		}
+	})
+
+	topologies := map[string]MigrationTopology{
+		"Ring":           RingTopology,
+		"FullyConnected": FullyConnectedTopology,
+		"Random":         RandomTopology,
+	}
+	replacements := map[string]ReplacementPolicy{
+		"ReplaceWorst":         ReplaceWorst,
+		"ReplaceRandom":        ReplaceRandom,
+		"ReplaceWorstIfBetter": ReplaceWorstIfBetter,
+	}
+	for topology_name, topology := range topologies {
+		for replacement_name, replacement := range replacements {
+			topology, replacement := topology, replacement
+			name := fmt.Sprintf("OptimizeIslands/%s/%s", topology_name, replacement_name)
+			t.Run(name, func(t *testing.T) {
+				t.Parallel()
+				epochs := 0
+				n_iterations, final_population, err := OptimizeIslands(IslandParams[int]{
+					Optimization: OptimizationParams[int]{
+						InitialPopulation: NewOption(geneInitialPopulation(20)),
+						MeasureFitness:    NewOption(measureCodeFitness),
+						Mutate:            NewOption(MutateCode),
+						MaxIterations:     NewOption(500),
+						PopulationSize:    NewOption(25),
+					},
+					Islands:           NewOption(4),
+					Topology:          NewOption(topology),
+					Replacement:       NewOption(replacement),
+					MigrationInterval: NewOption(5),
+					MigrationHook: NewOption(func(epoch int, islands [][]*ScoredCode[int]) {
+						epochs = epoch
+						if len(islands) != 4 {
+							t.Errorf("%s failed: expected 4 islands in MigrationHook, observed %d", name, len(islands))
+						}
+					}),
+				})
+
+				if err != nil {
+					t.Fatalf("%s failed with error: %v", name, err)
+				}
+				if len(final_population) != 100 {
+					t.Errorf("%s failed: expected combined population of 100, observed %d", name, len(final_population))
+				}
+				if epochs < 1 || epochs*5 < n_iterations {
+					t.Errorf("%s failed: %d epochs for %d iterations", name, epochs, n_iterations)
+				}
+				if n_iterations < 500 && final_population[0].Score < 0.99 {
+					t.Errorf("%s failed to meet fitness threshold of 0.99: %f reached instead", name, final_population[0].Score)
+				}
+			})
+		}
+	}
+
+	t.Run("OptimizeIslands/hooks", func(t *testing.T) {
+		t.Parallel()
+		iterations, stats, initial, active, overlapped := map[int]int{}, map[int]int{}, map[int]int{}, 0, false
+		enter := func() {
+			active++
+			overlapped = overlapped || active > 1
+		}
+		params := IslandParams[int]{
+			Optimization: OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(20)),
+				MeasureFitness:    NewOption(measureCodeFitness),
+				Mutate:            NewOption(MutateCode),
+				MaxIterations:     NewOption(10),
+				PopulationSize:    NewOption(10),
+				FitnessTarget:     NewOption(2.0),
+			},
+			Islands: NewOption(3),
+			IterationHook: NewOption(func(island, generation int, scores []*ScoredCode[int]) {
+				enter()
+				defer func() { active-- }()
+				iterations[island]++
+			}),
+			StatsHook: NewOption(func(island int, s GenerationStats) {
+				enter()
+				defer func() { active-- }()
+				stats[island]++
+				if s.Generation == 0 {
+					initial[island]++
+				}
+			}),
+		}
+		if _, _, err := OptimizeIslands(params); err != nil {
+			t.Fatalf("OptimizeIslands with hooks failed with error: %v", err)
+		}
+		for island := 0; island < 3; island++ {
+			if iterations[island] != 10 || stats[island] != 11 {
+				t.Errorf("OptimizeIslands failed to call the hooks for island %d: observed %d and %d calls",
+					island, iterations[island], stats[island])
+			}
+			if initial[island] != 1 {
+				t.Errorf("OptimizeIslands failed to report generation 0 for island %d: observed %d calls",
+					island, initial[island])
+			}
+		}
+		if overlapped {
+			t.Errorf("OptimizeIslands failed to serialize the hooks")
+		}
+
+		params.Optimization.IterationHook = NewOption(func(int, []*ScoredCode[int]) {})
+		if _, _, err := OptimizeIslands(params); err == nil {
+			t.Errorf("OptimizeIslands failed to reject params.Optimization.IterationHook")
+		}
+		params.Optimization.IterationHook = Option[func(int, []*ScoredCode[int])]{}
+		params.Optimization.StatsHook = NewOption(func(GenerationStats) {})
+		if _, _, err := OptimizeIslands(params); err == nil {
+			t.Errorf("OptimizeIslands failed to reject params.Optimization.StatsHook")
+		}
+	})
+
+	t.Run("OptimizeIslandsContext/cancel", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		_, final_population, err := OptimizeIslandsContext(ctx, IslandParams[int]{
+			Optimization: OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(20)),
+				MeasureFitness:    NewOption(measureCodeFitness),
+				Mutate:            NewOption(MutateCode),
+				FitnessTarget:     NewOption(2.0),
+			},
+			MigrationHook: NewOption(func(epoch int, islands [][]*ScoredCode[int]) {
+				if epoch == 3 {
+					cancel()
+				}
+			}),
+		})
+
+		if err != context.Canceled {
+			t.Errorf("OptimizeIslandsContext failed: expected context.Canceled, observed %v", err)
+		}
+		if len(final_population) != 400 {
+			t.Errorf("OptimizeIslandsContext failed to return the combined population: observed len %d", len(final_population))
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+type MapElitesParams[T Ordered] struct {
+	MeasureFitness    Option[func(Code[T]) float64]
+	MeasureBehavior   Option[func(Code[T]) []float64]
+	Mutate            Option[func(*Code[T])]
+	InitialPopulation Option[[]Code[T]]
+	Grid              Option[[]GridDimension]
+	MaxIterations     Option[int]
+	BatchSize         Option[int]
+	RecombinationOpts Option[RecombineOptions]
+	ParallelCount     Option[int]
+	IterationHook     Option[func(int, *EliteArchive[T])]
+	Rand              Option[*Rand]
+}
+
+// One dimension of the behavior space, divided into Bins equal cells between
+// Bounds.Lower and Bounds.Upper. Behaviors outside the Bounds fall into the
+// edge cells.
+type GridDimension struct {
+	Bounds Bounds[float64]
+	Bins   int
+}
+
+// The best Code found for a Cell of the grid, with its Score and Behavior.
+type Elite[T Ordered] struct {
+	Code     Code[T]
+	Score    float64
+	Behavior []float64
+	Cell     []int
+}
+
+// A grid archive holding at most one Elite per cell.
+type EliteArchive[T Ordered] struct {
+	Grid  []GridDimension
+	cells map[int]*Elite[T]
+}
+
+// Returns an empty archive with the given grid.
+func NewEliteArchive[T Ordered](grid []GridDimension) *EliteArchive[T] {
+	return &EliteArchive[T]{Grid: grid, cells: map[int]*Elite[T]{}}
+}
+
+// Returns the cell of the grid that the behavior falls into. Missing and NaN
+// dimensions are treated as 0, and infinite ones fall into the edge cells.
+func (a *EliteArchive[T]) Cell(behavior []float64) []int {
+	cell := make([]int, len(a.Grid))
+	for i, dimension := range a.Grid {
+		value := 0.0
+		if i < len(behavior) && !math.IsNaN(behavior[i]) {
+			value = behavior[i]
+		}
+		// clamped first since converting an infinite bin to int is undefined
+		value = math.Max(dimension.Bounds.Lower, math.Min(value, dimension.Bounds.Upper))
+		width := dimension.Bounds.Upper - dimension.Bounds.Lower
+		bin := int(math.Floor((value - dimension.Bounds.Lower) / width * float64(dimension.Bins)))
+		bin, _ = max(bin, 0)
+		cell[i], _ = min(bin, dimension.Bins-1)
+	}
+	return cell
+}
+
+// Returns the flat index of the cell.
+func (a *EliteArchive[T]) index(cell []int) int {
+	index := 0
+	for i, dimension := range a.Grid {
+		index = index*dimension.Bins + cell[i]
+	}
+	return index
+}
+
+// Returns the number of cells in the grid.
+func (a *EliteArchive[T]) Size() int {
+	size := 1
+	for _, dimension := range a.Grid {
+		size *= dimension.Bins
+	}
+	return size
+}
+
+// Places the elite in the cell of its Behavior if the cell is empty or holds
+// a lower or NaN Score. An elite with a NaN Score is never placed. Returns true
+// if the elite was placed.
+func (a *EliteArchive[T]) Insert(elite *Elite[T]) bool {
+	elite.Cell = a.Cell(elite.Behavior)
+	if math.IsNaN(elite.Score) {
+		return false
+	}
+	index := a.index(elite.Cell)
+	if current, ok := a.cells[index]; ok && !math.IsNaN(current.Score) && current.Score >= elite.Score {
+		return false
+	}
+	a.cells[index] = elite
+	return true
+}
+
+// Returns the Elite in the cell, if there is one.
+func (a *EliteArchive[T]) Get(cell []int) (*Elite[T], bool) {
+	if len(cell) != len(a.Grid) {
+		return nil, false
+	}
+	for i, dimension := range a.Grid {
+		if cell[i] < 0 || cell[i] >= dimension.Bins {
+			return nil, false
+		}
+	}
+	elite, ok := a.cells[a.index(cell)]
+	return elite, ok
+}
+
+// Returns the Elites ordered by cell.
+func (a *EliteArchive[T]) Elites() []*Elite[T] {
+	indices := []int{}
+	for index := range a.cells {
+		indices = append(indices, index)
+	}
+	sort.Ints(indices)
+	elites := make([]*Elite[T], len(indices))
+	for i, index := range indices {
+		elites[i] = a.cells[index]
+	}
+	return elites
+}
+
+// Returns the fraction of the cells that hold an Elite.
+func (a *EliteArchive[T]) Coverage() float64 {
+	return float64(len(a.cells)) / float64(a.Size())
+}
+
+// Returns the sum of the Scores of the Elites.
+func (a *EliteArchive[T]) QDScore() float64 {
+	total := 0.0
+	for _, elite := range a.cells {
+		total += elite.Score
+	}
+	return total
+}
+
+// Returns the Elite with the highest Score, if the archive is not empty.
+func (a *EliteArchive[T]) Best() (*Elite[T], bool) {
+	elites := a.Elites()
+	if len(elites) == 0 {
+		return nil, false
+	}
+	best := elites[0]
+	for _, elite := range elites[1:] {
+		if elite.Score > best.Score {
+			best = elite
+		}
+	}
+	return best, true
+}
+
+func measureElites[T Ordered](params MapElitesParams[T], elites []*Elite[T]) {
+	measureConcurrently(len(elites), params.ParallelCount.Val, func(i int) {
+		elites[i].Score = params.MeasureFitness.Val(elites[i].Code)
+		elites[i].Behavior = params.MeasureBehavior.Val(elites[i].Code)
+	})
+}
+
+// Runs MAP-Elites (Mouret and Clune), which keeps the best Code found in each
+// cell of a grid over the behavior space, maximizing params.MeasureFitness.
+// Each generation breeds params.BatchSize children from random pairs of
+// Elites. Returns the number of generations and the filled archive.
+func OptimizeMapElites[T Ordered](params MapElitesParams[T]) (int, *EliteArchive[T], error) {
+	generation_count := 0
+	archive := NewEliteArchive[T](params.Grid.Val)
+
+	if !params.InitialPopulation.Ok() {
+		return generation_count, archive, missingParameterError{"params.InitialPopulation"}
+	}
+	if len(params.InitialPopulation.Val) < 1 {
+		return generation_count, archive, anError{"params.InitialPopulation Must have len > 0"}
+	}
+	if !params.MeasureFitness.Ok() {
+		return generation_count, archive, missingParameterError{"params.MeasureFitness"}
+	}
+	if !params.MeasureBehavior.Ok() {
+		return generation_count, archive, missingParameterError{"params.MeasureBehavior"}
+	}
+	if !params.Mutate.Ok() {
+		return generation_count, archive, missingParameterError{"params.Mutate"}
+	}
+	if !params.Grid.Ok() {
+		return generation_count, archive, missingParameterError{"params.Grid"}
+	}
+	if len(params.Grid.Val) < 1 {
+		return generation_count, archive, anError{"params.Grid Must have len > 0"}
+	}
+	for _, dimension := range params.Grid.Val {
+		if dimension.Bins < 1 {
+			return generation_count, archive, anError{"params.Grid Bins must be at least 1"}
+		}
+		if dimension.Bounds.Upper <= dimension.Bounds.Lower {
+			return generation_count, archive, anError{"params.Grid Bounds.Upper must be greater than Bounds.Lower"}
+		}
+	}
+	if !params.MaxIterations.Ok() {
+		params.MaxIterations.Val = 100
+	}
+	if !params.BatchSize.Ok() {
+		params.BatchSize.Val = 100
+	}
+	if params.BatchSize.Val < 1 {
+		return generation_count, archive, anError{"params.BatchSize must be at least 1"}
+	}
+
+	rng := params.Rand.Val
+	if !params.Rand.Ok() {
+		rng = params.RecombinationOpts.Val.Rand.Val
+	}
+	recombination_opts := params.RecombinationOpts.Val
+	recombination_opts.Rand = NewOption(rng)
+	initial := []*Elite[T]{}
+	for _, code := range params.InitialPopulation.Val {
+		initial = append(initial, &Elite[T]{Code: code})
+	}
+	measureElites(params, initial)
+	for _, elite := range initial {
+		archive.Insert(elite)
+	}
+
+	for generation_count < params.MaxIterations.Val {
+		generation_count++
+		elites := archive.Elites()
+		children := make([]*Elite[T], params.BatchSize.Val)
+		for i := range children {
+			dad := elites[randomInt(rng, 0, len(elites))].Code
+			mom := elites[randomInt(rng, 0, len(elites))].Code
+			child := &Elite[T]{}
+			dad.Recombine(mom, &child.Code, recombination_opts)
+			params.Mutate.Val(&child.Code)
+			children[i] = child
+		}
+		measureElites(params, children)
+		for _, child := range children {
+			archive.Insert(child)
+		}
+
+		if params.IterationHook.Ok() {
+			params.IterationHook.Val(generation_count, archive)
+		}
+	}
+
+	return generation_count, archive, nil
+}
@@ -0,0 +1,175 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func planarGrid() []GridDimension {
+	return []GridDimension{
+		{Bounds: Bounds[float64]{Lower: -1.0, Upper: 1.0}, Bins: 4},
+		{Bounds: Bounds[float64]{Lower: -1.0, Upper: 1.0}, Bins: 4},
+	}
+}
+
+func TestMapElites(t *testing.T) {
+	t.Run("EliteArchive", func(t *testing.T) {
+		t.Parallel()
+		archive := NewEliteArchive[int](planarGrid())
+		cells := map[[2]float64][]int{
+			{-1.0, 0.99}: {0, 3},
+			{-0.4, 0.0}:  {1, 2},
+			{-5.0, 5.0}:  {0, 3},
+		}
+		for behavior, expected := range cells {
+			if cell := archive.Cell(behavior[:]); !equal(cell, expected) {
+				t.Errorf("Cell failed for %v: expected %v, observed %v", behavior, expected, cell)
+			}
+		}
+
+		if !archive.Insert(&Elite[int]{Score: 1.0, Behavior: []float64{-0.9, 0.9}}) {
+			t.Errorf("Insert failed to fill an empty cell")
+		}
+		if archive.Insert(&Elite[int]{Score: 0.5, Behavior: []float64{-0.8, 0.8}}) {
+			t.Errorf("Insert failed: replaced an Elite with a lower Score")
+		}
+		if !archive.Insert(&Elite[int]{Score: 2.0, Behavior: []float64{-0.7, 0.7}}) {
+			t.Errorf("Insert failed to replace an Elite with a higher Score")
+		}
+		archive.Insert(&Elite[int]{Score: 1.5, Behavior: []float64{0.1, 0.1}})
+
+		if elite, ok := archive.Get([]int{0, 3}); !ok || elite.Score != 2.0 || !equal(elite.Cell, []int{0, 3}) {
+			t.Errorf("Get failed: expected the Elite with Score 2 in [0 3], observed %v", elite)
+		}
+		if _, ok := archive.Get([]int{4, 0}); ok {
+			t.Errorf("Get failed: returned an Elite for a cell outside the grid")
+		}
+		if elites := archive.Elites(); len(elites) != 2 || elites[0].Score != 2.0 || elites[1].Score != 1.5 {
+			t.Errorf("Elites failed: expected 2 Elites ordered by cell, observed %d", len(elites))
+		}
+		if coverage := archive.Coverage(); coverage != 2.0/16.0 {
+			t.Errorf("Coverage failed: expected %v, observed %v", 2.0/16.0, coverage)
+		}
+		if qd := archive.QDScore(); qd != 3.5 {
+			t.Errorf("QDScore failed: expected 3.5, observed %v", qd)
+		}
+		if best, ok := archive.Best(); !ok || best.Score != 2.0 {
+			t.Errorf("Best failed: expected the Elite with Score 2, observed %v", best)
+		}
+	})
+
+	t.Run("EliteArchive/NaN", func(t *testing.T) {
+		t.Parallel()
+		archive := NewEliteArchive[int](planarGrid())
+		if archive.Insert(&Elite[int]{Score: math.NaN(), Behavior: []float64{0.5, 0.5}}) {
+			t.Errorf("Insert failed: placed an Elite with a NaN Score")
+		}
+		if archive.Coverage() != 0.0 {
+			t.Errorf("Insert failed: expected an empty archive, observed coverage %v", archive.Coverage())
+		}
+
+		// a NaN Score set after insertion is replaced by any other Score
+		elite := &Elite[int]{Score: 1.0, Behavior: []float64{0.5, 0.5}}
+		archive.Insert(elite)
+		elite.Score = math.NaN()
+		if !archive.Insert(&Elite[int]{Score: -1.0, Behavior: []float64{0.6, 0.6}}) {
+			t.Errorf("Insert failed to replace an Elite with a NaN Score")
+		}
+		if archive.Insert(&Elite[int]{Score: math.NaN(), Behavior: []float64{0.6, 0.6}}) {
+			t.Errorf("Insert failed: replaced an Elite with a NaN Score")
+		}
+		if best, ok := archive.Best(); !ok || best.Score != -1.0 {
+			t.Errorf("Insert failed: expected the Elite with Score -1, observed %v", best)
+		}
+	})
+
+	t.Run("Cell/NaN and Inf", func(t *testing.T) {
+		t.Parallel()
+		archive := NewEliteArchive[int](planarGrid())
+		cells := map[[2]float64][]int{
+			{math.NaN(), 0.9}:           {2, 3},
+			{math.Inf(1), math.Inf(-1)}: {3, 0},
+			{math.Inf(-1), math.NaN()}:  {0, 2},
+		}
+		for behavior, expected := range cells {
+			if cell := archive.Cell(behavior[:]); !equal(cell, expected) {
+				t.Errorf("Cell failed for %v: expected %v, observed %v", behavior, expected, cell)
+			}
+		}
+	})
+
+	t.Run("OptimizeMapElites", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(89)
+		hook_calls := 0
+		n_iterations, archive, err := OptimizeMapElites(MapElitesParams[float64]{
+			InitialPopulation: NewOption(planarInitialPopulation(rng, 10)),
+			MeasureBehavior:   NewOption(measurePlanarBehavior),
+			MeasureFitness: NewOption(func(code Code[float64]) float64 {
+				return -EuclideanDistance(code.Gene.Val.Bases, []float64{0.5, 0.5})
+			}),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](1.0, 0.2)(code.Gene.Val, rng)
+			}),
+			Grid:          NewOption(planarGrid()),
+			BatchSize:     NewOption(20),
+			MaxIterations: NewOption(50),
+			ParallelCount: NewOption(4),
+			IterationHook: NewOption(func(generation int, archive *EliteArchive[float64]) {
+				hook_calls++
+			}),
+			Rand: NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("OptimizeMapElites failed with error: %v", err)
+		}
+		if n_iterations != 50 || hook_calls != 50 {
+			t.Errorf("OptimizeMapElites failed: expected 50 iterations and hook calls, observed %d and %d",
+				n_iterations, hook_calls)
+		}
+		if coverage := archive.Coverage(); coverage < 0.9 {
+			t.Errorf("OptimizeMapElites failed to fill the grid: expected coverage of at least 0.9, observed %v", coverage)
+		}
+		for _, elite := range archive.Elites() {
+			if !equal(archive.Cell(elite.Behavior), elite.Cell) {
+				t.Errorf("OptimizeMapElites failed: Elite with behavior %v is in cell %v", elite.Behavior, elite.Cell)
+			}
+		}
+		if best, ok := archive.Best(); !ok || best.Score < -0.1 {
+			t.Errorf("OptimizeMapElites failed to find the optimum: observed %v", best.Score)
+		}
+	})
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		valid := MapElitesParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			MeasureBehavior: NewOption(func(code Code[int]) []float64 {
+				return []float64{float64(code.Gene.Val.Bases[0])}
+			}),
+			Mutate: NewOption(MutateCode),
+			Grid:   NewOption([]GridDimension{{Bounds: Bounds[float64]{Lower: 0, Upper: 10}, Bins: 10}}),
+		}
+		invalid := map[string]MapElitesParams[int]{
+			"empty": {},
+		}
+		missing := valid
+		missing.Grid = Option[[]GridDimension]{}
+		invalid["Grid"] = missing
+		bins := valid
+		bins.Grid = NewOption([]GridDimension{{Bounds: Bounds[float64]{Lower: 0, Upper: 10}}})
+		invalid["Bins"] = bins
+		bounds := valid
+		bounds.Grid = NewOption([]GridDimension{{Bounds: Bounds[float64]{Lower: 1, Upper: 1}, Bins: 2}})
+		invalid["Bounds"] = bounds
+		batch := valid
+		batch.BatchSize = NewOption(0)
+		invalid["BatchSize"] = batch
+		for name, params := range invalid {
+			if _, _, err := OptimizeMapElites(params); err == nil {
+				t.Errorf("OptimizeMapElites failed to reject invalid %s", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+type MultiObjectiveParams[T Ordered] struct {
+	MeasureFitness    Option[func(Code[T]) []float64]
+	Mutate            Option[func(*Code[T])]
+	InitialPopulation Option[[]Code[T]]
+	MaxIterations     Option[int]
+	PopulationSize    Option[int]
+	RecombinationOpts Option[RecombineOptions]
+	ParallelCount     Option[int]
+	IterationHook     Option[func(int, []*ParetoScoredCode[T])]
+	Rand              Option[*Rand]
+}
+
+// A Code with one Score per objective, its non-domination Rank (0 is the
+// Pareto front), and its Crowding distance within that rank.
+type ParetoScoredCode[T Ordered] struct {
+	Code     Code[T]
+	Scores   []float64
+	Rank     int
+	Crowding float64
+}
+
+// Returns true if a is at least as good as b in every objective and strictly
+// better in at least one. All objectives are maximized.
+func dominates(a, b []float64) bool {
+	better := false
+	for i := range a {
+		if a[i] < b[i] {
+			return false
+		}
+		if a[i] > b[i] {
+			better = true
+		}
+	}
+	return better
+}
+
+// Sorts the population into fronts of mutually non-dominated individuals,
+// setting the Rank of each, and returns the fronts in order.
+func nonDominatedSort[T Ordered](population []*ParetoScoredCode[T]) [][]*ParetoScoredCode[T] {
+	dominated_by := make([][]int, len(population))
+	domination_count := make([]int, len(population))
+	fronts := [][]*ParetoScoredCode[T]{}
+	current := []int{}
+
+	for i := range population {
+		for j := range population {
+			if i == j {
+				continue
+			}
+			if dominates(population[i].Scores, population[j].Scores) {
+				dominated_by[i] = append(dominated_by[i], j)
+			} else if dominates(population[j].Scores, population[i].Scores) {
+				domination_count[i]++
+			}
+		}
+		if domination_count[i] == 0 {
+			current = append(current, i)
+		}
+	}
+
+	for rank := 0; len(current) > 0; rank++ {
+		front := []*ParetoScoredCode[T]{}
+		next := []int{}
+		for _, i := range current {
+			population[i].Rank = rank
+			front = append(front, population[i])
+			for _, j := range dominated_by[i] {
+				domination_count[j]--
+				if domination_count[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		fronts = append(fronts, front)
+		current = next
+	}
+
+	return fronts
+}
+
+// Sets the Crowding distance of each member of the front. Boundary members
+// for each objective have infinite Crowding.
+func crowdingDistance[T Ordered](front []*ParetoScoredCode[T]) {
+	for _, member := range front {
+		member.Crowding = 0.0
+	}
+	if len(front) == 0 {
+		return
+	}
+	for m := range front[0].Scores {
+		sort.SliceStable(front, func(i, j int) bool {
+			return front[i].Scores[m] < front[j].Scores[m]
+		})
+		front[0].Crowding = math.Inf(1)
+		front[len(front)-1].Crowding = math.Inf(1)
+		span := front[len(front)-1].Scores[m] - front[0].Scores[m]
+		// an infinite objective leaves no finite span to normalize by
+		if !(span > 0.0) || math.IsInf(span, 1) {
+			continue
+		}
+		for i := 1; i < len(front)-1; i++ {
+			front[i].Crowding += (front[i+1].Scores[m] - front[i-1].Scores[m]) / span
+		}
+	}
+}
+
+// The crowded-comparison operator: lower Rank wins, then higher Crowding.
+func crowdedLess[T Ordered](a, b *ParetoScoredCode[T]) bool {
+	if a.Rank != b.Rank {
+		return a.Rank < b.Rank
+	}
+	return a.Crowding > b.Crowding
+}
+
+func sortParetoScoredCodes[T Ordered](population []*ParetoScoredCode[T]) {
+	sort.SliceStable(population, func(i, j int) bool {
+		return crowdedLess(population[i], population[j])
+	})
+}
+
+// Ranks and sorts the population, then truncates it to size by keeping whole
+// fronts and the least crowded members of the last front that fits partially.
+func environmentalSelection[T Ordered](population []*ParetoScoredCode[T], size int) []*ParetoScoredCode[T] {
+	survivors := []*ParetoScoredCode[T]{}
+	for _, front := range nonDominatedSort(population) {
+		crowdingDistance(front)
+		if len(survivors)+len(front) <= size {
+			survivors = append(survivors, front...)
+			continue
+		}
+		sortParetoScoredCodes(front)
+		survivors = append(survivors, front[:size-len(survivors)]...)
+		break
+	}
+	sortParetoScoredCodes(survivors)
+	return survivors
+}
+
+func binaryTournament[T Ordered](population []*ParetoScoredCode[T], rng *Rand) Code[T] {
+	a := population[randomInt(rng, 0, len(population))]
+	b := population[randomInt(rng, 0, len(population))]
+	if crowdedLess(b, a) {
+		return b.Code
+	}
+	return a.Code
+}
+
+// Measures the objectives of each member. A NaN objective is replaced by -Inf
+// so that it ranks as the worst possible value. Returns an error unless every
+// member has the same non-zero number of objectives as objectives, or as each
+// other if objectives is 0.
+func measureMultiObjective[T Ordered](params MultiObjectiveParams[T],
+	population []*ParetoScoredCode[T], objectives int) error {
+	parallel_count := 1
+	if params.ParallelCount.Ok() && params.ParallelCount.Val > 1 {
+		parallel_count = params.ParallelCount.Val
+	}
+	var wg sync.WaitGroup
+	chunk_size := int(math.Ceil(float64(len(population)) / float64(parallel_count)))
+	for start := 0; start < len(population); start += chunk_size {
+		stop, _ := min(start+chunk_size, len(population))
+		wg.Add(1)
+		go func(members []*ParetoScoredCode[T]) {
+			defer wg.Done()
+			for _, member := range members {
+				member.Scores = params.MeasureFitness.Val(member.Code)
+			}
+		}(population[start:stop])
+	}
+	wg.Wait()
+	for _, member := range population {
+		if objectives == 0 {
+			objectives = len(member.Scores)
+		}
+		if len(member.Scores) == 0 || len(member.Scores) != objectives {
+			return anError{"params.MeasureFitness must return the same non-zero number of objectives for every Code"}
+		}
+		for i, score := range member.Scores {
+			if math.IsNaN(score) {
+				member.Scores[i] = math.Inf(-1)
+			}
+		}
+	}
+	return nil
+}
+
+// Runs the NSGA-II multi-objective evolutionary algorithm, maximizing every
+// objective returned by params.MeasureFitness. Returns the number of
+// generations and the Pareto front of the final population.
+func OptimizeMultiObjective[T Ordered](params MultiObjectiveParams[T]) (int, []*ParetoScoredCode[T], error) {
+	generation_count := 0
+	front := []*ParetoScoredCode[T]{}
+
+	if !params.InitialPopulation.Ok() {
+		return generation_count, front, missingParameterError{"params.InitialPopulation"}
+	}
+	if len(params.InitialPopulation.Val) < 1 {
+		return generation_count, front, anError{"params.InitialPopulation Must have len > 0"}
+	}
+	if !params.MeasureFitness.Ok() {
+		return generation_count, front, missingParameterError{"params.MeasureFitness"}
+	}
+	if !params.Mutate.Ok() {
+		return generation_count, front, missingParameterError{"params.Mutate"}
+	}
+	if !params.MaxIterations.Ok() {
+		params.MaxIterations.Val = 100
+	}
+	if !params.PopulationSize.Ok() {
+		params.PopulationSize.Val = 100
+	}
+	if params.PopulationSize.Val < 3 {
+		return generation_count, front, anError{"params.PopulationSize must be at least 3"}
+	}
+
+	rng := params.Rand.Val
+	if !params.Rand.Ok() {
+		rng = params.RecombinationOpts.Val.Rand.Val
+	}
+	recombination_opts := params.RecombinationOpts.Val
+	recombination_opts.Rand = NewOption(rng)
+	population := []*ParetoScoredCode[T]{}
+	for _, code := range params.InitialPopulation.Val {
+		population = append(population, &ParetoScoredCode[T]{Code: code})
+	}
+	if err := measureMultiObjective(params, population, 0); err != nil {
+		return generation_count, front, err
+	}
+	objectives := len(population[0].Scores)
+	population = environmentalSelection(population, params.PopulationSize.Val)
+
+	for generation_count < params.MaxIterations.Val {
+		generation_count++
+		offspring := make([]*ParetoScoredCode[T], params.PopulationSize.Val)
+		for i := range offspring {
+			dad, mom := binaryTournament(population, rng), binaryTournament(population, rng)
+			child := &ParetoScoredCode[T]{}
+			dad.Recombine(mom, &child.Code, recombination_opts)
+			params.Mutate.Val(&child.Code)
+			offspring[i] = child
+		}
+		if err := measureMultiObjective(params, offspring, objectives); err != nil {
+			return generation_count, front, err
+		}
+		population = environmentalSelection(append(population, offspring...),
+			params.PopulationSize.Val)
+
+		if params.IterationHook.Ok() {
+			params.IterationHook.Val(generation_count, population)
+		}
+	}
+
+	for _, member := range population {
+		if member.Rank == 0 {
+			front = append(front, member)
+		}
+	}
+	return generation_count, front, nil
+}
@@ -0,0 +1,243 @@
+package bluegenes
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func paretoPopulation(scores ...[]float64) []*ParetoScoredCode[int] {
+	population := []*ParetoScoredCode[int]{}
+	for _, s := range scores {
+		population = append(population, &ParetoScoredCode[int]{Scores: s})
+	}
+	return population
+}
+
+func TestMultiObjective(t *testing.T) {
+	t.Run("dominates", func(t *testing.T) {
+		t.Parallel()
+		if !dominates([]float64{2, 2}, []float64{1, 2}) {
+			t.Error("dominates failed: [2 2] should dominate [1 2]")
+		}
+		if dominates([]float64{2, 1}, []float64{1, 2}) {
+			t.Error("dominates failed: [2 1] should not dominate [1 2]")
+		}
+		if dominates([]float64{1, 1}, []float64{1, 1}) {
+			t.Error("dominates failed: equal scores should not dominate")
+		}
+	})
+
+	t.Run("nonDominatedSort", func(t *testing.T) {
+		t.Parallel()
+		population := paretoPopulation(
+			[]float64{3, 1}, []float64{1, 3}, []float64{2, 2},
+			[]float64{1, 1}, []float64{2, 1}, []float64{0, 0},
+		)
+		fronts := nonDominatedSort(population)
+		expected := []int{0, 0, 0, 2, 1, 3}
+
+		if len(fronts) != 4 {
+			t.Fatalf("nonDominatedSort failed: expected 4 fronts, observed %d", len(fronts))
+		}
+		for i, member := range population {
+			if member.Rank != expected[i] {
+				t.Errorf("nonDominatedSort failed for %v: expected rank %d, observed %d",
+					member.Scores, expected[i], member.Rank)
+			}
+		}
+	})
+
+	t.Run("crowdingDistance", func(t *testing.T) {
+		t.Parallel()
+		front := paretoPopulation(
+			[]float64{0, 4}, []float64{1, 3}, []float64{3, 1}, []float64{4, 0},
+		)
+		crowdingDistance(front)
+
+		for _, member := range front {
+			boundary := member.Scores[0] == 0 || member.Scores[0] == 4
+			if boundary && !math.IsInf(member.Crowding, 1) {
+				t.Errorf("crowdingDistance failed: boundary %v should be +Inf, observed %f", member.Scores, member.Crowding)
+			}
+			if !boundary && math.Abs(member.Crowding-1.5) > 1e-9 {
+				t.Errorf("crowdingDistance failed for %v: expected 1.5, observed %f", member.Scores, member.Crowding)
+			}
+		}
+	})
+
+	t.Run("environmentalSelection", func(t *testing.T) {
+		t.Parallel()
+		population := paretoPopulation(
+			[]float64{0, 4}, []float64{1, 3}, []float64{1.1, 2.9}, []float64{3, 1},
+			[]float64{4, 0}, []float64{0, 0}, []float64{1, 1},
+		)
+		survivors := environmentalSelection(population, 4)
+
+		if len(survivors) != 4 {
+			t.Fatalf("environmentalSelection failed: expected 4 survivors, observed %d", len(survivors))
+		}
+		for _, member := range survivors {
+			if member.Rank != 0 {
+				t.Errorf("environmentalSelection failed: kept %v with rank %d", member.Scores, member.Rank)
+			}
+			if member.Scores[0] == 1 {
+				t.Errorf("environmentalSelection failed: kept most crowded member %v", member.Scores)
+			}
+		}
+	})
+
+	t.Run("OptimizeMultiObjective", func(t *testing.T) {
+		for _, parallel_count := range []int{1, 4} {
+			initial_population := []Code[float64]{}
+			for i := 0; i < 20; i++ {
+				gene := &Gene[float64]{Name: "x", Bases: []float64{rand.Float64()*10 - 5}}
+				initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+			}
+			measure_fitness := func(code Code[float64]) []float64 {
+				x := code.Gene.Val.Bases[0]
+				return []float64{-x * x, -(x - 2) * (x - 2)}
+			}
+			mutate := func(code *Code[float64]) {
+				code.Gene.Val.Bases[0] += rand.NormFloat64() * 0.1
+			}
+			hook_calls := 0
+
+			n_iterations, front, err := OptimizeMultiObjective(MultiObjectiveParams[float64]{
+				InitialPopulation: NewOption(initial_population),
+				MeasureFitness:    NewOption(measure_fitness),
+				Mutate:            NewOption(mutate),
+				MaxIterations:     NewOption(50),
+				PopulationSize:    NewOption(20),
+				ParallelCount:     NewOption(parallel_count),
+				IterationHook: NewOption(func(gc int, pop []*ParetoScoredCode[float64]) {
+					hook_calls++
+				}),
+			})
+
+			if err != nil {
+				t.Fatalf("OptimizeMultiObjective failed with error: %v", err)
+			}
+			if n_iterations != 50 || hook_calls != 50 {
+				t.Errorf("OptimizeMultiObjective failed: expected 50 iterations and hook calls, observed %d and %d",
+					n_iterations, hook_calls)
+			}
+			if len(front) < 10 {
+				t.Errorf("OptimizeMultiObjective failed: expected a front of at least 10, observed %d", len(front))
+			}
+			for _, member := range front {
+				x := member.Code.Gene.Val.Bases[0]
+				if member.Rank != 0 || x < -0.1 || x > 2.1 {
+					t.Errorf("OptimizeMultiObjective failed: front member x=%f with rank %d is not Pareto optimal",
+						x, member.Rank)
+				}
+			}
+		}
+	})
+
+	t.Run("mismatched objectives", func(t *testing.T) {
+		t.Parallel()
+		measures := map[string]func(Code[int]) []float64{
+			"lengths": func(code Code[int]) []float64 {
+				return make([]float64, 1+len(code.Gene.Val.Bases)%2)
+			},
+			"empty": func(code Code[int]) []float64 { return []float64{} },
+		}
+		for name, measure := range measures {
+			initial_population := geneInitialPopulation(10)
+			initial_population[0].Gene.Val.Bases = []int{1, 2}
+			initial_population[1].Gene.Val.Bases = []int{1}
+			_, _, err := OptimizeMultiObjective(MultiObjectiveParams[int]{
+				InitialPopulation: NewOption(initial_population),
+				MeasureFitness:    NewOption(measure),
+				Mutate:            NewOption(MutateCode),
+				MaxIterations:     NewOption(5),
+			})
+			if err == nil {
+				t.Errorf("OptimizeMultiObjective failed to return error for %s objectives", name)
+			}
+		}
+	})
+
+	t.Run("NaN objectives", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(113)
+		initial_population := []Code[float64]{}
+		for i := 0; i < 20; i++ {
+			gene := &Gene[float64]{Name: "x", Bases: []float64{rng.Float64()*10 - 5}}
+			initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+		}
+		crowding_nan := false
+		_, front, err := OptimizeMultiObjective(MultiObjectiveParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness: NewOption(func(code Code[float64]) []float64 {
+				x := code.Gene.Val.Bases[0]
+				if x < 0 {
+					return []float64{math.NaN(), -(x - 2) * (x - 2)}
+				}
+				return []float64{-x * x, -(x - 2) * (x - 2)}
+			}),
+			Mutate: NewOption(func(code *Code[float64]) {
+				code.Gene.Val.Bases[0] += rng.NormFloat64() * 0.5
+			}),
+			MaxIterations:  NewOption(20),
+			PopulationSize: NewOption(20),
+			IterationHook: NewOption(func(gc int, pop []*ParetoScoredCode[float64]) {
+				for _, member := range pop {
+					crowding_nan = crowding_nan || math.IsNaN(member.Crowding)
+				}
+			}),
+			Rand: NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("OptimizeMultiObjective with NaN objectives failed with error: %v", err)
+		}
+		if crowding_nan {
+			t.Errorf("OptimizeMultiObjective with NaN objectives failed: observed NaN Crowding")
+		}
+		for _, member := range front {
+			if math.IsInf(member.Scores[0], -1) {
+				t.Errorf("OptimizeMultiObjective with NaN objectives failed: front member has scores %v", member.Scores)
+			}
+		}
+	})
+
+	t.Run("population of size 1", func(t *testing.T) {
+		t.Parallel()
+		front := paretoPopulation([]float64{1, 2})
+		nonDominatedSort(front)
+		crowdingDistance(front)
+		if front[0].Rank != 0 || !math.IsInf(front[0].Crowding, 1) {
+			t.Errorf("crowdingDistance failed for a single member: observed rank %d and crowding %v",
+				front[0].Rank, front[0].Crowding)
+		}
+		_, front, err := OptimizeMultiObjective(MultiObjectiveParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(1)),
+			MeasureFitness: NewOption(func(code Code[int]) []float64 {
+				return []float64{float64(code.Gene.Val.Bases[0]), -float64(code.Gene.Val.Bases[0])}
+			}),
+			Mutate:         NewOption(MutateCode),
+			MaxIterations:  NewOption(5),
+			PopulationSize: NewOption(3),
+			Rand:           NewOption(NewRand(127)),
+		})
+		if err != nil || len(front) == 0 {
+			t.Errorf("OptimizeMultiObjective failed with a single initial member: front of %d, error %v", len(front), err)
+		}
+	})
+
+	t.Run("missing parameters", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := OptimizeMultiObjective(MultiObjectiveParams[int]{})
+		if err == nil {
+			t.Error("OptimizeMultiObjective failed to return error for missing InitialPopulation")
+		}
+		_, _, err = OptimizeMultiObjective(MultiObjectiveParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(5)),
+			Mutate:            NewOption(MutateCode),
+		})
+		if err == nil {
+			t.Error("OptimizeMultiObjective failed to return error for missing MeasureFitness")
+		}
+	})
+}
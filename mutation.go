@@ -0,0 +1,299 @@
+package bluegenes
+
+import (
+	"math"
+	"reflect"
+)
+
+// A Mutator changes the Bases of a Gene in place, drawing all randomness from
+// rng (which may be nil; see Rand).
+type Mutator[T Ordered] func(gene *Gene[T], rng *Rand)
+
+// A Mutator and its relative probability of being chosen by CombineMutators.
+type WeightedMutator[T Ordered] struct {
+	Mutator Mutator[T]
+	Weight  float64
+}
+
+// An inclusive range of values.
+type Bounds[T Ordered] struct {
+	Lower T
+	Upper T
+}
+
+// Returns the value limited to the Bounds.
+func (b Bounds[T]) Clamp(value T) T {
+	if value < b.Lower {
+		return b.Lower
+	}
+	if value > b.Upper {
+		return b.Upper
+	}
+	return value
+}
+
+// Adds normally distributed noise with standard deviation sigma to each base
+// with probability rate, clamping the result to bounds if supplied.
+func GaussianMutation[T Float](rate, sigma float64, bounds ...Bounds[T]) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		for i, base := range gene.Bases {
+			if rng.Float64() >= rate {
+				continue
+			}
+			base += T(rng.NormFloat64() * sigma)
+			if len(bounds) > 0 {
+				base = bounds[0].Clamp(base)
+			}
+			gene.Bases[i] = base
+		}
+	}
+}
+
+// Deb's polynomial mutation: perturbs each base with probability rate using a
+// polynomial distribution over bounds. Larger eta (the distribution index)
+// produces smaller perturbations; 20 is a common choice.
+func PolynomialMutation[T Float](rate, eta float64, bounds Bounds[T]) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		lower, upper := float64(bounds.Lower), float64(bounds.Upper)
+		span := upper - lower
+		if span <= 0.0 {
+			return
+		}
+		power := 1.0 / (eta + 1.0)
+		for i, base := range gene.Bases {
+			if rng.Float64() >= rate {
+				continue
+			}
+			x := float64(bounds.Clamp(base))
+			u := rng.Float64()
+			var delta float64
+			if u < 0.5 {
+				xy := 1.0 - (x-lower)/span
+				val := 2.0*u + (1.0-2.0*u)*math.Pow(xy, eta+1.0)
+				delta = math.Pow(val, power) - 1.0
+			} else {
+				xy := 1.0 - (upper-x)/span
+				val := 2.0*(1.0-u) + 2.0*(u-0.5)*math.Pow(xy, eta+1.0)
+				delta = 1.0 - math.Pow(val, power)
+			}
+			gene.Bases[i] = bounds.Clamp(T(x + delta*span))
+		}
+	}
+}
+
+// Replaces each base with a new one from factory with probability rate.
+func UniformResetMutation[T Ordered](rate float64, factory func() T) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		for i := range gene.Bases {
+			if rng.Float64() < rate {
+				gene.Bases[i] = factory()
+			}
+		}
+	}
+}
+
+// Flips each of the lowest bits bits of every base with probability rate. If
+// bits is less than 1 or greater than the size of T, every bit may be flipped.
+func BitFlipMutation[T Integer](rate float64, bits int) Mutator[T] {
+	var zero T
+	width := reflect.TypeOf(zero).Bits()
+	if bits < 1 || bits > width {
+		bits = width
+	}
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		for i, base := range gene.Bases {
+			value := uint64(base)
+			for bit := 0; bit < bits; bit++ {
+				if rng.Float64() < rate {
+					value ^= 1 << bit
+				}
+			}
+			gene.Bases[i] = T(value)
+		}
+	}
+}
+
+// Returns two distinct random indices in ascending order.
+func randomSegment(rng *Rand, size int) (int, int) {
+	i := randomInt(rng, 0, size)
+	j := randomInt(rng, 0, size-1)
+	if j >= i {
+		j++
+	} else {
+		i, j = j, i
+	}
+	return i, j
+}
+
+// Swaps two random bases with probability rate. Preserves permutations.
+func SwapMutation[T Ordered](rate float64) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		if len(gene.Bases) < 2 || rng.Float64() >= rate {
+			return
+		}
+		i, j := randomSegment(rng, len(gene.Bases))
+		gene.Bases[i], gene.Bases[j] = gene.Bases[j], gene.Bases[i]
+	}
+}
+
+// Reverses a random segment of the bases with probability rate. Preserves
+// permutations.
+func InversionMutation[T Ordered](rate float64) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		if len(gene.Bases) < 2 || rng.Float64() >= rate {
+			return
+		}
+		i, j := randomSegment(rng, len(gene.Bases))
+		for ; i < j; i, j = i+1, j-1 {
+			gene.Bases[i], gene.Bases[j] = gene.Bases[j], gene.Bases[i]
+		}
+	}
+}
+
+// Shuffles a random segment of the bases with probability rate. Preserves
+// permutations.
+func ScrambleMutation[T Ordered](rate float64) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		if len(gene.Bases) < 2 || rng.Float64() >= rate {
+			return
+		}
+		i, j := randomSegment(rng, len(gene.Bases))
+		segment := gene.Bases[i : j+1]
+		rng.Shuffle(len(segment), func(a, b int) {
+			segment[a], segment[b] = segment[b], segment[a]
+		})
+	}
+}
+
+// Moves a random base to another random position with probability rate.
+// Preserves permutations.
+func InsertionMutation[T Ordered](rate float64) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		gene.Mu.Lock()
+		defer gene.Mu.Unlock()
+		if len(gene.Bases) < 2 || rng.Float64() >= rate {
+			return
+		}
+		from := randomInt(rng, 0, len(gene.Bases))
+		base := gene.Bases[from]
+		gene.Bases = append(gene.Bases[:from], gene.Bases[from+1:]...)
+		to := randomInt(rng, 0, len(gene.Bases)+1)
+		gene.Bases = append(gene.Bases[:to], append([]T{base}, gene.Bases[to:]...)...)
+	}
+}
+
+// With probability rate, either inserts a base from factory at a random
+// position or deletes a random base, keeping the length within length (an
+// Upper less than 1 means there is no maximum). If factory is nil, a random
+// existing base is duplicated instead.
+func IndelMutation[T Ordered](rate float64, factory func() T, length Bounds[int]) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		if rng.Float64() >= rate {
+			return
+		}
+		gene.Mu.RLock()
+		size := len(gene.Bases)
+		gene.Mu.RUnlock()
+
+		can_insert := length.Upper < 1 || size < length.Upper
+		can_delete := size > 0 && size > length.Lower
+		if !can_insert && !can_delete {
+			return
+		}
+		if can_insert && (!can_delete || rng.Float64() < 0.5) {
+			if factory != nil {
+				gene.Insert(randomInt(rng, 0, size+1), factory())
+			} else if size > 0 {
+				gene.Duplicate(randomInt(rng, 0, size))
+			}
+			return
+		}
+		gene.Delete(randomInt(rng, 0, size))
+	}
+}
+
+// Returns a Mutator that applies each of the mutators in order.
+func ChainMutators[T Ordered](mutators ...Mutator[T]) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		for _, mutator := range mutators {
+			mutator(gene, rng)
+		}
+	}
+}
+
+// Returns the Genes of every level of the Code.
+func codeGenes[T Ordered](code Code[T]) []*Gene[T] {
+	genes := []*Gene[T]{}
+	if code.Gene.Ok() {
+		genes = append(genes, code.Gene.Val)
+	}
+	nucleosomeGenes := func(n *Nucleosome[T]) {
+		n.Mu.RLock()
+		defer n.Mu.RUnlock()
+		genes = append(genes, n.Genes...)
+	}
+	chromosomeGenes := func(c *Chromosome[T]) {
+		c.Mu.RLock()
+		defer c.Mu.RUnlock()
+		for _, nucleosome := range c.Nucleosomes {
+			nucleosomeGenes(nucleosome)
+		}
+	}
+	if code.Nucleosome.Ok() {
+		nucleosomeGenes(code.Nucleosome.Val)
+	}
+	if code.Chromosome.Ok() {
+		chromosomeGenes(code.Chromosome.Val)
+	}
+	if code.Genome.Ok() {
+		code.Genome.Val.Mu.RLock()
+		for _, chromosome := range code.Genome.Val.Chromosomes {
+			chromosomeGenes(chromosome)
+		}
+		code.Genome.Val.Mu.RUnlock()
+	}
+	return genes
+}
+
+// Returns a function suitable for OptimizationParams.Mutate that applies one
+// of the mutators, chosen at random according to Weight, to every Gene in the
+// Code. Every call draws from rng, so with OptimizationParams.ParallelCount the
+// order of draws depends on scheduling; use CombineMutatorsWithRand to keep
+// parallel runs reproducible.
+func CombineMutators[T Ordered](rng *Rand, mutators ...WeightedMutator[T]) func(*Code[T]) {
+	mutate := CombineMutatorsWithRand(mutators...)
+	return func(code *Code[T]) {
+		mutate(code, rng)
+	}
+}
+
+// Like CombineMutators, but returns a function suitable for
+// OptimizationParams.MutateWithRand that draws from the Rand it is given.
+func CombineMutatorsWithRand[T Ordered](mutators ...WeightedMutator[T]) func(*Code[T], *Rand) {
+	weights := make([]float64, len(mutators))
+	for i, mutator := range mutators {
+		weights[i] = mutator.Weight
+	}
+	return func(code *Code[T], rng *Rand) {
+		if len(mutators) == 0 {
+			return
+		}
+		for _, gene := range codeGenes(*code) {
+			mutators[rouletteIndices(rng, weights, 1)[0]].Mutator(gene, rng)
+		}
+	}
+}
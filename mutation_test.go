@@ -0,0 +1,240 @@
+package bluegenes
+
+import (
+	"sort"
+	"testing"
+)
+
+func isPermutation(bases []int) bool {
+	sorted := make([]int, len(bases))
+	copy(sorted, bases)
+	sort.Ints(sorted)
+	for i, base := range sorted {
+		if base != i {
+			return false
+		}
+	}
+	return true
+}
+
+func permutationGene(size int) *Gene[int] {
+	gene := &Gene[int]{Name: "perm"}
+	for i := 0; i < size; i++ {
+		gene.Bases = append(gene.Bases, i)
+	}
+	return gene
+}
+
+func TestMutators(t *testing.T) {
+	t.Run("GaussianMutation", func(t *testing.T) {
+		t.Parallel()
+		gene := &Gene[float64]{Bases: []float64{0.5, 0.5, 0.5, 0.5}}
+		GaussianMutation[float64](0.0, 1.0)(gene, nil)
+		if !equal(gene.Bases, []float64{0.5, 0.5, 0.5, 0.5}) {
+			t.Errorf("GaussianMutation failed: rate 0 changed bases %v", gene.Bases)
+		}
+		mutate := GaussianMutation(1.0, 10.0, Bounds[float64]{0.0, 1.0})
+		for i := 0; i < 100; i++ {
+			mutate(gene, nil)
+			for _, base := range gene.Bases {
+				if base < 0.0 || base > 1.0 {
+					t.Fatalf("GaussianMutation failed to respect bounds: observed %f", base)
+				}
+			}
+		}
+	})
+
+	t.Run("PolynomialMutation", func(t *testing.T) {
+		t.Parallel()
+		gene := &Gene[float64]{Bases: []float64{-1.0, 0.0, 1.0, 2.0}}
+		original := gene.Copy()
+		mutate := PolynomialMutation(1.0, 20.0, Bounds[float64]{-1.0, 2.0})
+		mutate(gene, NewRand(1))
+		if equal(gene.Bases, original.Bases) {
+			t.Error("PolynomialMutation failed to change any bases")
+		}
+		for i := 0; i < 100; i++ {
+			mutate(gene, nil)
+			for _, base := range gene.Bases {
+				if base < -1.0 || base > 2.0 {
+					t.Fatalf("PolynomialMutation failed to respect bounds: observed %f", base)
+				}
+			}
+		}
+	})
+
+	t.Run("UniformResetMutation", func(t *testing.T) {
+		t.Parallel()
+		gene := &Gene[string]{Bases: []string{"a", "b", "c"}}
+		UniformResetMutation(1.0, func() string { return "z" })(gene, nil)
+		if !equal(gene.Bases, []string{"z", "z", "z"}) {
+			t.Errorf("UniformResetMutation failed: observed %v", gene.Bases)
+		}
+	})
+
+	t.Run("BitFlipMutation", func(t *testing.T) {
+		t.Parallel()
+		gene := &Gene[int8]{Bases: []int8{0, -1}}
+		BitFlipMutation[int8](1.0, 3)(gene, nil)
+		if !equal(gene.Bases, []int8{7, -8}) {
+			t.Errorf("BitFlipMutation failed: expected [7 -8], observed %v", gene.Bases)
+		}
+		unsigned := &Gene[uint8]{Bases: []uint8{0}}
+		BitFlipMutation[uint8](1.0, 0)(unsigned, nil)
+		if unsigned.Bases[0] != 255 {
+			t.Errorf("BitFlipMutation failed: expected 255, observed %d", unsigned.Bases[0])
+		}
+	})
+
+	permutation_mutators := map[string]Mutator[int]{
+		"SwapMutation":      SwapMutation[int](1.0),
+		"InversionMutation": InversionMutation[int](1.0),
+		"ScrambleMutation":  ScrambleMutation[int](1.0),
+		"InsertionMutation": InsertionMutation[int](1.0),
+	}
+	for name, mutate := range permutation_mutators {
+		name, mutate := name, mutate
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			gene := permutationGene(10)
+			changed := false
+			for i := 0; i < 100; i++ {
+				mutate(gene, nil)
+				if !isPermutation(gene.Bases) || len(gene.Bases) != 10 {
+					t.Fatalf("%s failed to preserve the permutation: observed %v", name, gene.Bases)
+				}
+				changed = changed || !equal(gene.Bases, permutationGene(10).Bases)
+			}
+			if !changed {
+				t.Errorf("%s failed to change the permutation", name)
+			}
+			short := permutationGene(1)
+			mutate(short, nil)
+			if !equal(short.Bases, []int{0}) {
+				t.Errorf("%s failed for a single base: observed %v", name, short.Bases)
+			}
+		})
+	}
+
+	t.Run("IndelMutation", func(t *testing.T) {
+		t.Parallel()
+		gene := permutationGene(5)
+		mutate := IndelMutation(1.0, func() int { return 99 }, Bounds[int]{3, 7})
+		sizes := newSet[int]()
+		for i := 0; i < 200; i++ {
+			mutate(gene, nil)
+			if len(gene.Bases) < 3 || len(gene.Bases) > 7 {
+				t.Fatalf("IndelMutation failed to respect length bounds: observed %d", len(gene.Bases))
+			}
+			sizes.add(len(gene.Bases))
+		}
+		if sizes.len() != 5 {
+			t.Errorf("IndelMutation failed: expected 5 different lengths, observed %d", sizes.len())
+		}
+
+		gene = permutationGene(3)
+		mutate = IndelMutation[int](1.0, nil, Bounds[int]{3, 0})
+		mutate(gene, nil)
+		if len(gene.Bases) != 4 || newSet(gene.Bases...).len() != 3 {
+			t.Errorf("IndelMutation failed to duplicate a base: observed %v", gene.Bases)
+		}
+	})
+
+	t.Run("ChainMutators", func(t *testing.T) {
+		t.Parallel()
+		gene := &Gene[int]{Bases: []int{1, 2, 3}}
+		ChainMutators(
+			UniformResetMutation(1.0, func() int { return 5 }),
+			IndelMutation(1.0, func() int { return 5 }, Bounds[int]{4, 4}),
+		)(gene, nil)
+		if !equal(gene.Bases, []int{5, 5, 5, 5}) {
+			t.Errorf("ChainMutators failed: expected [5 5 5 5], observed %v", gene.Bases)
+		}
+	})
+
+	t.Run("CombineMutators", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(2, 2, 2, 0, 5)
+		code := Code[int]{Gene: NewOption(permutationGene(5)), Genome: NewOption(genome)}
+		mutate := CombineMutators(NewRand(1),
+			WeightedMutator[int]{UniformResetMutation(1.0, func() int { return -1 }), 1.0},
+			WeightedMutator[int]{UniformResetMutation(1.0, func() int { return -2 }), 0.0},
+		)
+		mutate(&code)
+		for _, gene := range codeGenes(code) {
+			for _, base := range gene.Bases {
+				if base != -1 {
+					t.Fatalf("CombineMutators failed to mutate every Gene by weight: observed %v", gene.Bases)
+				}
+			}
+		}
+		if len(codeGenes(code)) != 9 {
+			t.Errorf("codeGenes failed: expected 9 genes, observed %d", len(codeGenes(code)))
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(5)
+		n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate: NewOption(CombineMutators(rng,
+				WeightedMutator[int]{UniformResetMutation(0.2, func() int { return randomInt(rng, -100, 100) }), 1.0},
+				WeightedMutator[int]{BitFlipMutation[int](0.02, 16), 2.0},
+				WeightedMutator[int]{SwapMutation[int](0.5), 1.0},
+			)),
+			MaxIterations: NewOption(1000),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with CombineMutators failed with error: %v", err)
+		}
+		if n_iterations < 1000 && final_population[0].Score < 0.99 {
+			t.Errorf("Optimize with CombineMutators failed to meet fitness threshold: %f", final_population[0].Score)
+		}
+	})
+
+	t.Run("Optimize/MutateWithRand", func(t *testing.T) {
+		t.Parallel()
+		initial_population := geneInitialPopulation(10)
+		run := func() []*ScoredCode[int] {
+			population := []Code[int]{}
+			for _, code := range initial_population {
+				population = append(population, code.DeepCopy())
+			}
+			_, final_population, err := Optimize(OptimizationParams[int]{
+				InitialPopulation: NewOption(population),
+				MeasureFitness:    NewOption(measureCodeFitness),
+				MutateWithRand: NewOption(CombineMutatorsWithRand(
+					WeightedMutator[int]{BitFlipMutation[int](0.05, 16), 2.0},
+					WeightedMutator[int]{SwapMutation[int](0.5), 1.0},
+				)),
+				MaxIterations: NewOption(50),
+				FitnessTarget: NewOption(2.0),
+				ParallelCount: NewOption(4),
+				Rand:          NewOption(NewRand(9)),
+			})
+			if err != nil {
+				t.Fatalf("Optimize with MutateWithRand failed with error: %v", err)
+			}
+			return final_population
+		}
+		first, second := run(), run()
+		for i := range first {
+			if first[i].Score != second[i].Score || first[i].Code.Hash() != second[i].Code.Hash() {
+				t.Fatalf("Optimize with MutateWithRand failed to reproduce the parallel run at index %d", i)
+			}
+		}
+
+		_, _, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+			MutateWithRand:    NewOption(CombineMutatorsWithRand[int]()),
+		})
+		if err == nil {
+			t.Errorf("Optimize failed to reject MutateWithRand with Mutate")
+		}
+	})
+}
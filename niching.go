@@ -0,0 +1,252 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+// How Optimize maintains several niches in the population.
+type NichingMethod int
+
+const (
+	FitnessSharing NichingMethod = iota
+	DeterministicCrowding
+	RestrictedTournament
+	Clearing
+)
+
+func (m NichingMethod) String() string {
+	switch m {
+	case FitnessSharing:
+		return "fitness sharing"
+	case DeterministicCrowding:
+		return "deterministic crowding"
+	case RestrictedTournament:
+		return "restricted tournament"
+	case Clearing:
+		return "clearing"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns true if the method decides which children replace which members
+// rather than how the population is ranked.
+func (m NichingMethod) replaces() bool {
+	return m == DeterministicCrowding || m == RestrictedTournament
+}
+
+// Niching keeps several distinct good solutions in the population. Distance
+// measures how far apart two Codes are, defaulting to Code.Distance with
+// Metric. Method chooses how:
+//   - FitnessSharing (Goldberg and Richardson): members are ranked by their
+//     fitness divided by their niche count, the sum of 1-(d/Sigma)^Alpha over
+//     every member within distance d < Sigma (Alpha defaults to 1).
+//   - Clearing (Petrowski): only the best Capacity (default 1) members within
+//     Sigma of each niche's winner keep their fitness; the rest are ranked
+//     after every uncleared member.
+//   - DeterministicCrowding (Mahfoud): each child replaces the closer of its
+//     two parents unless that parent is better.
+//   - RestrictedTournament (Harik): each child replaces the closest of
+//     WindowSize (default 10) random members unless that member is better.
+//
+// FitnessSharing and Clearing compare every pair of members, so they measure
+// O(n^2) distances per generation for a population of n; Optimize computes the
+// niched fitness once per generation and reuses it when ranking again.
+type Niching[T Ordered] struct {
+	Method     NichingMethod
+	Distance   func(Code[T], Code[T]) float64
+	Metric     DistanceMetric
+	Sigma      float64
+	Alpha      float64
+	Capacity   int
+	WindowSize int
+}
+
+// Returns the distance between the two Codes.
+func (n Niching[T]) distance(a, b Code[T]) float64 {
+	if n.Distance != nil {
+		return n.Distance(a, b)
+	}
+	return a.Distance(b, n.Metric)
+}
+
+// Returns the niche count of each member for FitnessSharing.
+func (n Niching[T]) nicheCounts(scores []*ScoredCode[T]) []float64 {
+	alpha := n.Alpha
+	if alpha <= 0.0 {
+		alpha = 1.0
+	}
+	counts := make([]float64, len(scores))
+	for i := range scores {
+		counts[i] += 1.0
+		for j := i + 1; j < len(scores); j++ {
+			if d := n.distance(scores[i].Code, scores[j].Code); d < n.Sigma {
+				share := 1.0 - math.Pow(d/n.Sigma, alpha)
+				counts[i] += share
+				counts[j] += share
+			}
+		}
+	}
+	return counts
+}
+
+// Returns which members are cleared, given the population ordered by
+// feasibility and then fitness.
+func (n Niching[T]) cleared(scores []*ScoredCode[T]) []bool {
+	capacity := n.Capacity
+	if capacity < 1 {
+		capacity = 1
+	}
+	cleared := make([]bool, len(scores))
+	for i := range scores {
+		if cleared[i] {
+			continue
+		}
+		winners := 1
+		for j := i + 1; j < len(scores); j++ {
+			if cleared[j] || n.distance(scores[i].Code, scores[j].Code) >= n.Sigma {
+				continue
+			}
+			if winners < capacity {
+				winners++
+			} else {
+				cleared[j] = true
+			}
+		}
+	}
+	return cleared
+}
+
+// Orders the population by feasibility and then fitness, and returns the
+// niched fitness of each member with FitnessSharing or Clearing.
+func (n Niching[T]) nichedFitness(scores []*ScoredCode[T], direction Direction) map[*ScoredCode[T]]float64 {
+	sortFeasible(scores, direction)
+	fitness := make([]float64, len(scores))
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for i, score := range scores {
+		fitness[i] = direction.fitness(score.Score)
+		lowest, _ = min(lowest, fitness[i])
+		highest, _ = max(highest, fitness[i])
+	}
+	// sharing divides the fitness, so it must not be negative
+	if lowest < 0.0 {
+		for i := range fitness {
+			fitness[i] -= lowest
+		}
+		highest -= lowest
+	}
+
+	switch n.Method {
+	case Clearing:
+		for i, cleared := range n.cleared(scores) {
+			if cleared {
+				fitness[i] -= highest + 1.0
+			}
+		}
+	default:
+		for i, count := range n.nicheCounts(scores) {
+			fitness[i] /= count
+		}
+	}
+
+	niched := make(map[*ScoredCode[T]]float64, len(scores))
+	for i, score := range scores {
+		niched[score] = fitness[i]
+	}
+	return niched
+}
+
+// Orders the population best first by niched fitness. Feasible members are
+// always ranked ahead of infeasible ones, as with FeasibilityRules.
+func sortNiched[T Ordered](scores []*ScoredCode[T], niched map[*ScoredCode[T]]float64) {
+	sort.SliceStable(scores, func(i, j int) bool {
+		if scores[i].Violation != scores[j].Violation {
+			return scores[i].Violation < scores[j].Violation
+		}
+		return niched[scores[i]] > niched[scores[j]]
+	})
+}
+
+// Orders the population best first by niched fitness with FitnessSharing or
+// Clearing.
+func (n Niching[T]) rank(scores []*ScoredCode[T], direction Direction) {
+	sortNiched(scores, n.nichedFitness(scores, direction))
+}
+
+// Orders the population best first by niched fitness, reusing the niched
+// fitness computed earlier in the same generation unless a member has none.
+func (r *optimizationRun[T]) nicheRank() {
+	fresh := r.niched != nil && r.nichedGeneration == r.generationCount
+	for i := 0; fresh && i < len(r.scores); i++ {
+		_, fresh = r.niched[r.scores[i]]
+	}
+	if !fresh {
+		r.niched = r.params.Niching.Val.nichedFitness(r.scores, r.params.Direction.Val)
+		r.nichedGeneration = r.generationCount
+	}
+	sortNiched(r.scores, r.niched)
+}
+
+// Returns true if params.Niching decides which children replace which
+// members.
+func (r *optimizationRun[T]) nicheReplacement() bool {
+	return r.params.Niching.Ok() && r.params.Niching.Val.Method.replaces()
+}
+
+// Replaces the closer parent of each child with the child unless the parent
+// is better (deterministic crowding). A child whose parent was already
+// replaced by a sibling is discarded rather than compared with that sibling.
+func (r *optimizationRun[T]) crowd(mates []Code[T], children []*ScoredCode[T]) {
+	niching, direction := r.params.Niching.Val, r.params.Direction.Val
+	// the slots still holding each parent genotype
+	indices := map[string][]int{}
+	for i, score := range r.scores {
+		key := score.Code.key()
+		indices[key] = append(indices[key], i)
+	}
+	for i, child := range children {
+		parent := mates[2*i]
+		if niching.distance(child.Code, mates[2*i+1]) < niching.distance(child.Code, parent) {
+			parent = mates[2*i+1]
+		}
+		key := parent.key()
+		slots := indices[key]
+		if len(slots) > 0 && !feasiblyBetter(r.scores[slots[0]], child, direction) {
+			r.putScoredCode(r.scores[slots[0]])
+			r.scores[slots[0]] = child
+			indices[key] = slots[1:]
+		} else {
+			r.putScoredCode(child)
+		}
+	}
+}
+
+// Replaces the closest of WindowSize random members with each child unless
+// that member is better (restricted tournament selection).
+func (r *optimizationRun[T]) restrictedTournament(children []*ScoredCode[T]) {
+	niching, direction := r.params.Niching.Val, r.params.Direction.Val
+	window := niching.WindowSize
+	if window < 1 {
+		window = 10
+	}
+	for _, child := range children {
+		if len(r.scores) == 0 {
+			r.scores = append(r.scores, child)
+			continue
+		}
+		closest, closest_distance := 0, math.Inf(1)
+		for k := 0; k < window; k++ {
+			j := randomInt(r.rng, 0, len(r.scores))
+			if d := niching.distance(child.Code, r.scores[j].Code); d < closest_distance {
+				closest, closest_distance = j, d
+			}
+		}
+		if !feasiblyBetter(r.scores[closest], child, direction) {
+			r.putScoredCode(r.scores[closest])
+			r.scores[closest] = child
+		} else {
+			r.putScoredCode(child)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func nichedCodes(bases []int, scores []float64) []*ScoredCode[int] {
+	population := scoredCodes(make([][2]float64, len(bases))...)
+	for i, score := range population {
+		score.Code.Gene.Val.Bases = []int{i, bases[i]}
+		score.Score = scores[i]
+	}
+	return population
+}
+
+func nichedBases(scores []*ScoredCode[int]) []int {
+	bases := []int{}
+	for _, score := range scores {
+		bases = append(bases, score.Code.Gene.Val.Bases[0])
+	}
+	return bases
+}
+
+func baseDistance(a, b Code[int]) float64 {
+	return math.Abs(float64(a.Gene.Val.Bases[1] - b.Gene.Val.Bases[1]))
+}
+
+func measureTwinPeaks(code Code[float64]) float64 {
+	x := code.Gene.Val.Bases[0]
+	return math.Max(math.Exp(-4.0*(x-2.0)*(x-2.0)), math.Exp(-4.0*(x+2.0)*(x+2.0)))
+}
+
+func TestNiching(t *testing.T) {
+	t.Run("FitnessSharing", func(t *testing.T) {
+		t.Parallel()
+		scores := nichedCodes([]int{0, 0, 0, 10}, []float64{1.0, 1.0, 1.0, 0.5})
+		niching := Niching[int]{Method: FitnessSharing, Distance: baseDistance, Sigma: 1.0}
+		if counts := niching.nicheCounts(scores); !equal(counts, []float64{3.0, 3.0, 3.0, 1.0}) {
+			t.Errorf("nicheCounts failed: expected [3 3 3 1], observed %v", counts)
+		}
+		niching.rank(scores, Maximize)
+		if bases := nichedBases(scores); !equal(bases, []int{3, 0, 1, 2}) {
+			t.Errorf("FitnessSharing failed: expected [3 0 1 2], observed %v", bases)
+		}
+
+		scores = nichedCodes([]int{0, 0, 10}, []float64{-1.0, -1.0, -3.0})
+		niching.rank(scores, Minimize)
+		if bases := nichedBases(scores); !equal(bases, []int{2, 0, 1}) {
+			t.Errorf("FitnessSharing failed to minimize: expected [2 0 1], observed %v", bases)
+		}
+	})
+
+	t.Run("Clearing", func(t *testing.T) {
+		t.Parallel()
+		scores := nichedCodes([]int{0, 0, 1, 10}, []float64{0.9, 1.0, 0.8, 0.5})
+		niching := Niching[int]{Method: Clearing, Distance: baseDistance, Sigma: 2.0}
+		niching.rank(scores, Maximize)
+		if bases := nichedBases(scores); !equal(bases, []int{1, 3, 0, 2}) {
+			t.Errorf("Clearing failed: expected [1 3 0 2], observed %v", bases)
+		}
+		niching.Capacity = 2
+		niching.rank(scores, Maximize)
+		if bases := nichedBases(scores); !equal(bases, []int{1, 0, 3, 2}) {
+			t.Errorf("Clearing failed with Capacity 2: expected [1 0 3 2], observed %v", bases)
+		}
+	})
+
+	t.Run("DeterministicCrowding", func(t *testing.T) {
+		t.Parallel()
+		r := &optimizationRun[int]{params: OptimizationParams[int]{
+			Niching: NewOption(Niching[int]{Method: DeterministicCrowding, Distance: baseDistance}),
+		}}
+		r.scores = nichedCodes([]int{0, 10}, []float64{0.5, 0.5})
+		children := nichedCodes([]int{9, 1}, []float64{0.7, 0.1})
+		mates := []Code[int]{r.scores[0].Code, r.scores[1].Code, r.scores[0].Code, r.scores[1].Code}
+		r.crowd(mates, children)
+		if bases := nichedBases(r.scores); !equal(bases, []int{0, 0}) || r.scores[1] != children[0] {
+			t.Errorf("crowd failed: expected the first child to replace the second parent, observed %v", bases)
+		}
+		if len(r.pool) != 2 {
+			t.Errorf("crowd failed to return the replaced parent and the losing child to the pool")
+		}
+	})
+
+	t.Run("DeterministicCrowding/siblings", func(t *testing.T) {
+		t.Parallel()
+		r := &optimizationRun[int]{params: OptimizationParams[int]{
+			Niching: NewOption(Niching[int]{Method: DeterministicCrowding, Distance: baseDistance}),
+		}}
+		r.scores = nichedCodes([]int{0, 10}, []float64{0.5, 0.5})
+		parents := []*ScoredCode[int]{r.scores[0], r.scores[1]}
+		children := nichedCodes([]int{1, 2}, []float64{0.7, 0.9})
+		mates := []Code[int]{parents[0].Code, parents[1].Code, parents[0].Code, parents[1].Code}
+		r.crowd(mates, children)
+		if r.scores[0] != children[0] || r.scores[1] != parents[1] {
+			t.Errorf("crowd failed: expected the second child to be discarded rather than replace its sibling, observed %v",
+				nichedBases(r.scores))
+		}
+		if len(r.pool) != 2 {
+			t.Errorf("crowd failed to return the replaced parent and the discarded child to the pool")
+		}
+	})
+
+	t.Run("RestrictedTournament", func(t *testing.T) {
+		t.Parallel()
+		r := &optimizationRun[int]{
+			params: OptimizationParams[int]{Niching: NewOption(Niching[int]{
+				Method: RestrictedTournament, Distance: baseDistance, WindowSize: 50,
+			})},
+			rng: NewRand(11),
+		}
+		r.scores = nichedCodes([]int{0, 5, 10}, []float64{0.5, 0.5, 0.5})
+		children := nichedCodes([]int{6, 11}, []float64{0.6, 0.1})
+		r.restrictedTournament(children)
+		if r.scores[1] != children[0] || r.scores[2].Code.Gene.Val.Bases[1] != 10 {
+			t.Errorf("restrictedTournament failed: observed %v", nichedBases(r.scores))
+		}
+	})
+
+	t.Run("Optimize/once per generation", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(79)
+		initial_population := []Code[float64]{}
+		for i := 0; i < 20; i++ {
+			gene := &Gene[float64]{Name: "x", Bases: []float64{-5.0 + 10.0*rng.Float64()}}
+			initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+		}
+		distances := 0
+		_, _, err := Optimize(OptimizationParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(measureTwinPeaks),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](0.5, 0.2)(code.Gene.Val, rng)
+			}),
+			PopulationSize: NewOption(20),
+			Replacement:    NewOption(PlusReplacement),
+			Niching: NewOption(Niching[float64]{
+				Method: FitnessSharing, Sigma: 1.0,
+				Distance: func(a, b Code[float64]) float64 {
+					distances++
+					return math.Abs(a.Gene.Val.Bases[0] - b.Gene.Val.Bases[0])
+				},
+			}),
+			FitnessTarget: NewOption(2.0),
+			MaxIterations: NewOption(5),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with FitnessSharing failed with error: %v", err)
+		}
+		// the initial population, then parents and children once per generation
+		expected := 20*19/2 + 5*40*39/2
+		if distances != expected {
+			t.Errorf("Optimize with FitnessSharing failed: expected %d distances, observed %d", expected, distances)
+		}
+	})
+
+	methods := []NichingMethod{FitnessSharing, DeterministicCrowding, RestrictedTournament, Clearing}
+	for _, method := range methods {
+		method := method
+		t.Run("Optimize/"+method.String(), func(t *testing.T) {
+			t.Parallel()
+			rng := NewRand(73)
+			initial_population := []Code[float64]{}
+			for i := 0; i < 40; i++ {
+				gene := &Gene[float64]{Name: "x", Bases: []float64{-5.0 + 10.0*rng.Float64()}}
+				initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+			}
+			_, final_population, err := Optimize(OptimizationParams[float64]{
+				InitialPopulation: NewOption(initial_population),
+				MeasureFitness:    NewOption(measureTwinPeaks),
+				Mutate: NewOption(func(code *Code[float64]) {
+					GaussianMutation[float64](0.5, 0.2)(code.Gene.Val, rng)
+				}),
+				PopulationSize:       NewOption(40),
+				ParentsPerGeneration: NewOption(20),
+				Niching: NewOption(Niching[float64]{
+					Method: method, Metric: EuclideanMetric, Sigma: 1.0, Capacity: 5,
+				}),
+				FitnessTarget: NewOption(2.0),
+				MaxIterations: NewOption(100),
+				Rand:          NewOption(rng),
+			})
+			if err != nil {
+				t.Fatalf("Optimize with %s failed with error: %v", method, err)
+			}
+			peaks := map[float64]int{}
+			for _, score := range final_population {
+				for _, peak := range []float64{-2.0, 2.0} {
+					if math.Abs(score.Code.Gene.Val.Bases[0]-peak) < 0.2 {
+						peaks[peak]++
+					}
+				}
+			}
+			if peaks[-2.0] == 0 || peaks[2.0] == 0 {
+				t.Errorf("Optimize with %s failed to keep both peaks: observed %v", method, peaks)
+			}
+		})
+	}
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		invalid := map[string]OptimizationParams[int]{
+			"Sigma": {Niching: NewOption(Niching[int]{Method: Clearing})},
+			"Replacement": {
+				Niching:     NewOption(Niching[int]{Method: DeterministicCrowding}),
+				Replacement: NewOption(PlusReplacement),
+			},
+		}
+		for name, params := range invalid {
+			params.InitialPopulation = NewOption(geneInitialPopulation(10))
+			params.MeasureFitness = NewOption(measureCodeFitness)
+			params.Mutate = NewOption(MutateCode)
+			if _, err := prepareOptimizationParams(params); err == nil {
+				t.Errorf("prepareOptimizationParams failed to reject invalid %s", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package bluegenes
+
+import (
+	"sort"
+)
+
+type NoveltyParams[T Ordered] struct {
+	MeasureBehavior   Option[func(Code[T]) []float64]
+	MeasureFitness    Option[func(Code[T]) float64]
+	Mutate            Option[func(*Code[T])]
+	InitialPopulation Option[[]Code[T]]
+	MaxIterations     Option[int]
+	PopulationSize    Option[int]
+	Neighbors         Option[int]
+	ArchiveThreshold  Option[float64]
+	RecombinationOpts Option[RecombineOptions]
+	ParallelCount     Option[int]
+	IterationHook     Option[func(int, []*NoveltyScoredCode[T])]
+	Rand              Option[*Rand]
+}
+
+// A Code with its behavior descriptor, its Novelty (the mean distance from its
+// nearest neighbors in behavior space), and its Score if a fitness function
+// was supplied.
+type NoveltyScoredCode[T Ordered] struct {
+	Code     Code[T]
+	Behavior []float64
+	Novelty  float64
+	Score    float64
+}
+
+func measureBehaviors[T Ordered](params NoveltyParams[T], members []*NoveltyScoredCode[T]) {
+	measureConcurrently(len(members), params.ParallelCount.Val, func(i int) {
+		members[i].Behavior = params.MeasureBehavior.Val(members[i].Code)
+		if params.MeasureFitness.Ok() {
+			members[i].Score = params.MeasureFitness.Val(members[i].Code)
+		}
+	})
+}
+
+// Sets the Novelty of each member to the mean Euclidean distance between its
+// Behavior and those of its k nearest neighbors among the others.
+func measureNovelty[T Ordered](members, others []*NoveltyScoredCode[T], k int) {
+	for _, member := range members {
+		distances := []float64{}
+		for _, other := range others {
+			if other != member {
+				distances = append(distances, EuclideanDistance(member.Behavior, other.Behavior))
+			}
+		}
+		sort.Float64s(distances)
+		n, _ := min(k, len(distances))
+		member.Novelty = 0.0
+		for _, distance := range distances[:n] {
+			member.Novelty += distance
+		}
+		if n > 0 {
+			member.Novelty /= float64(n)
+		}
+	}
+}
+
+func noveltyTournament[T Ordered](population []*NoveltyScoredCode[T], rng *Rand) Code[T] {
+	a := population[randomInt(rng, 0, len(population))]
+	b := population[randomInt(rng, 0, len(population))]
+	if b.Novelty > a.Novelty {
+		return b.Code
+	}
+	return a.Code
+}
+
+// Runs novelty search (Lehman and Stanley), which rewards behavior that differs
+// from what has been seen before instead of fitness. Returns the number of
+// generations and the archive of novel Codes in the order they were added.
+func OptimizeNovelty[T Ordered](params NoveltyParams[T]) (int, []*NoveltyScoredCode[T], error) {
+	generation_count := 0
+	archive := []*NoveltyScoredCode[T]{}
+
+	if !params.InitialPopulation.Ok() {
+		return generation_count, archive, missingParameterError{"params.InitialPopulation"}
+	}
+	if len(params.InitialPopulation.Val) < 1 {
+		return generation_count, archive, anError{"params.InitialPopulation Must have len > 0"}
+	}
+	if !params.MeasureBehavior.Ok() {
+		return generation_count, archive, missingParameterError{"params.MeasureBehavior"}
+	}
+	if !params.Mutate.Ok() {
+		return generation_count, archive, missingParameterError{"params.Mutate"}
+	}
+	if !params.MaxIterations.Ok() {
+		params.MaxIterations.Val = 100
+	}
+	if !params.PopulationSize.Ok() {
+		params.PopulationSize.Val = 100
+	}
+	if params.PopulationSize.Val < 3 {
+		return generation_count, archive, anError{"params.PopulationSize must be at least 3"}
+	}
+	if !params.Neighbors.Ok() {
+		params.Neighbors.Val = 15
+	}
+	if params.Neighbors.Val < 1 {
+		return generation_count, archive, anError{"params.Neighbors must be at least 1"}
+	}
+
+	rng := params.Rand.Val
+	if !params.Rand.Ok() {
+		rng = params.RecombinationOpts.Val.Rand.Val
+	}
+	recombination_opts := params.RecombinationOpts.Val
+	recombination_opts.Rand = NewOption(rng)
+	population := []*NoveltyScoredCode[T]{}
+	for _, code := range params.InitialPopulation.Val {
+		population = append(population, &NoveltyScoredCode[T]{Code: code})
+	}
+	measureBehaviors(params, population)
+	measureNovelty(population, population, params.Neighbors.Val)
+
+	for generation_count < params.MaxIterations.Val {
+		generation_count++
+		offspring := make([]*NoveltyScoredCode[T], params.PopulationSize.Val)
+		for i := range offspring {
+			dad, mom := noveltyTournament(population, rng), noveltyTournament(population, rng)
+			child := &NoveltyScoredCode[T]{}
+			dad.Recombine(mom, &child.Code, recombination_opts)
+			params.Mutate.Val(&child.Code)
+			offspring[i] = child
+		}
+		measureBehaviors(params, offspring)
+
+		combined := append(population, offspring...)
+		measureNovelty(combined, append(append([]*NoveltyScoredCode[T]{}, combined...), archive...),
+			params.Neighbors.Val)
+		if params.ArchiveThreshold.Ok() {
+			for _, child := range offspring {
+				if child.Novelty > params.ArchiveThreshold.Val {
+					archive = append(archive, child)
+				}
+			}
+		} else {
+			most_novel := offspring[0]
+			for _, child := range offspring[1:] {
+				if child.Novelty > most_novel.Novelty {
+					most_novel = child
+				}
+			}
+			archive = append(archive, most_novel)
+		}
+
+		sort.SliceStable(combined, func(i, j int) bool {
+			return combined[i].Novelty > combined[j].Novelty
+		})
+		population = combined[:params.PopulationSize.Val]
+
+		if params.IterationHook.Ok() {
+			params.IterationHook.Val(generation_count, population)
+		}
+	}
+
+	return generation_count, archive, nil
+}
@@ -0,0 +1,127 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func behaviorPopulation(behaviors ...[]float64) []*NoveltyScoredCode[int] {
+	population := []*NoveltyScoredCode[int]{}
+	for _, behavior := range behaviors {
+		population = append(population, &NoveltyScoredCode[int]{Behavior: behavior})
+	}
+	return population
+}
+
+func planarInitialPopulation(rng *Rand, size int) []Code[float64] {
+	initial_population := []Code[float64]{}
+	for i := 0; i < size; i++ {
+		gene := &Gene[float64]{Name: "xy", Bases: []float64{0.1 * rng.Float64(), 0.1 * rng.Float64()}}
+		initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+	}
+	return initial_population
+}
+
+func measurePlanarBehavior(code Code[float64]) []float64 {
+	return append([]float64{}, code.Gene.Val.Bases...)
+}
+
+func TestNovelty(t *testing.T) {
+	t.Run("measureNovelty", func(t *testing.T) {
+		t.Parallel()
+		population := behaviorPopulation([]float64{0, 0}, []float64{1, 0}, []float64{3, 0}, []float64{10, 0})
+		measureNovelty(population, population, 2)
+		expected := []float64{2.0, 1.5, 2.5, 8.0}
+		for i, member := range population {
+			if math.Abs(member.Novelty-expected[i]) > 1e-9 {
+				t.Errorf("measureNovelty failed for %v: expected %v, observed %v",
+					member.Behavior, expected[i], member.Novelty)
+			}
+		}
+	})
+
+	t.Run("OptimizeNovelty", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(83)
+		hook_calls := 0
+		n_iterations, archive, err := OptimizeNovelty(NoveltyParams[float64]{
+			InitialPopulation: NewOption(planarInitialPopulation(rng, 20)),
+			MeasureBehavior:   NewOption(measurePlanarBehavior),
+			MeasureFitness: NewOption(func(code Code[float64]) float64 {
+				return -EuclideanDistance(code.Gene.Val.Bases, []float64{0, 0})
+			}),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](1.0, 0.1)(code.Gene.Val, rng)
+			}),
+			PopulationSize: NewOption(20),
+			Neighbors:      NewOption(5),
+			MaxIterations:  NewOption(40),
+			ParallelCount:  NewOption(4),
+			IterationHook: NewOption(func(generation int, population []*NoveltyScoredCode[float64]) {
+				hook_calls++
+			}),
+			Rand: NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("OptimizeNovelty failed with error: %v", err)
+		}
+		if n_iterations != 40 || hook_calls != 40 || len(archive) != 40 {
+			t.Errorf("OptimizeNovelty failed: expected 40 iterations, hook calls, and archived Codes, "+
+				"observed %d, %d, and %d", n_iterations, hook_calls, len(archive))
+		}
+		farthest := 0.0
+		for _, member := range archive {
+			if member.Score != -EuclideanDistance(member.Behavior, []float64{0, 0}) {
+				t.Errorf("OptimizeNovelty failed to measure the Score of %v", member.Behavior)
+			}
+			farthest, _ = max(farthest, -member.Score)
+		}
+		if farthest < 1.0 {
+			t.Errorf("OptimizeNovelty failed to explore: expected a behavior at least 1 away, observed %v", farthest)
+		}
+
+		_, archive, err = OptimizeNovelty(NoveltyParams[float64]{
+			InitialPopulation: NewOption(planarInitialPopulation(rng, 20)),
+			MeasureBehavior:   NewOption(measurePlanarBehavior),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](1.0, 0.1)(code.Gene.Val, rng)
+			}),
+			PopulationSize:   NewOption(20),
+			ArchiveThreshold: NewOption(math.Inf(1)),
+			MaxIterations:    NewOption(5),
+			Rand:             NewOption(rng),
+		})
+		if err != nil || len(archive) != 0 {
+			t.Errorf("OptimizeNovelty failed to apply ArchiveThreshold: observed %d archived Codes and error %v",
+				len(archive), err)
+		}
+	})
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		valid := NoveltyParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureBehavior: NewOption(func(code Code[int]) []float64 {
+				return []float64{float64(code.Gene.Val.Bases[0])}
+			}),
+			Mutate: NewOption(MutateCode),
+		}
+		invalid := map[string]NoveltyParams[int]{
+			"empty": {},
+		}
+		missing := valid
+		missing.MeasureBehavior = Option[func(Code[int]) []float64]{}
+		invalid["MeasureBehavior"] = missing
+		small := valid
+		small.PopulationSize = NewOption(2)
+		invalid["PopulationSize"] = small
+		neighbors := valid
+		neighbors.Neighbors = NewOption(0)
+		invalid["Neighbors"] = neighbors
+		for name, params := range invalid {
+			if _, _, err := OptimizeNovelty(params); err == nil {
+				t.Errorf("OptimizeNovelty failed to reject invalid %s", name)
+			}
+		}
+	})
+}
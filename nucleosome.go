@@ -1,8 +1,6 @@
 package bluegenes
 
 import (
-	"math"
-	"sort"
 	"sync"
 )
 
@@ -22,6 +20,13 @@ func (n *Nucleosome[T]) Copy() *Nucleosome[T] {
 	return &another
 }
 
+// Copies the Nucleosome along with each of its Genes.
+func (n *Nucleosome[T]) DeepCopy() *Nucleosome[T] {
+	n.Mu.RLock()
+	defer n.Mu.RUnlock()
+	return &Nucleosome[T]{Name: n.Name, Genes: copySubunits(n.Genes, (*Gene[T]).Copy)}
+}
+
 func (n *Nucleosome[T]) Insert(index int, gene *Gene[T]) error {
 	n.Mu.Lock()
 	defer n.Mu.Unlock()
@@ -86,15 +91,15 @@ func (n *Nucleosome[T]) Recombine(other *Nucleosome[T], indices []int,
 	min_size, _ := min(len(n.Genes), len(other.Genes))
 	max_size, _ := max(len(n.Genes), len(other.Genes))
 
-	if len(indices) == 0 && min_size > 1 {
-		max_swaps := math.Ceil(math.Log(float64(min_size)))
-		swaps, _ := max(RandomInt(0, int(max_swaps)), 1)
-		idxSet := newSet[int]()
-		for i := 0; i < swaps; i++ {
-			idxSet.add(RandomInt(0, min_size))
+	aligned := len(indices) == 0 && options.AlignGenes.Ok() &&
+		options.AlignGenes.Val != PositionalAlignment
+	skip := len(indices) == 0 && options.NucleosomeCrossover.Val.skip(options.Rand.Val)
+	if len(indices) == 0 && !skip && !aligned {
+		var err error
+		indices, err = options.NucleosomeCrossover.Val.indices(options.Rand.Val, min_size)
+		if err != nil {
+			return err
 		}
-		indices = idxSet.toSlice()
-		sort.Ints(indices)
 	}
 	for _, i := range indices {
 		if 0 > i || i >= min_size {
@@ -103,18 +108,37 @@ func (n *Nucleosome[T]) Recombine(other *Nucleosome[T], indices []int,
 	}
 
 	name := n.Name
-	if name != other.Name {
+	if name != other.Name && !skip && !options.InheritNames.Val {
 		name_size, err := min(len(name), len(other.Name))
 		if err != nil {
 			return err
 		}
 		if name_size > 2 {
-			name_swap := RandomInt(1, name_size-1)
+			name_swap := randomInt(options.Rand.Val, 1, name_size-1)
 			name = n.Name[:name_swap] + other.Name[name_swap:]
 		}
 	}
 	child.Name = name
 
+	if aligned {
+		genes, err := recombineAligned(n.Genes, other.Genes,
+			func(gene *Gene[T]) string { return gene.Name }, options.AlignGenes.Val,
+			options.NucleosomeCrossover.Val, options, skip, (*Gene[T]).Copy,
+			func(dad, mom *Gene[T]) (*Gene[T], error) {
+				if (!options.RecombineGenes.Ok() || options.RecombineGenes.Val) &&
+					(!options.MatchGenes.Val || dad.Name == mom.Name) {
+					gene := &Gene[T]{}
+					return gene, dad.Recombine(mom, []int{}, gene, options)
+				}
+				return dad.Copy(), nil
+			})
+		if err != nil {
+			return err
+		}
+		child.Genes = genes
+		return nil
+	}
+
 	for len(child.Genes) < max_size {
 		child.Genes = append(child.Genes, &Gene[T]{})
 	}
@@ -1,6 +1,8 @@
 package bluegenes
 
 import (
+	"context"
+	"io"
 	"math"
 	"sort"
 	"sync"
@@ -13,8 +15,13 @@ type Code[T Ordered] struct {
 	Nucleosome Option[*Nucleosome[T]]
 	Chromosome Option[*Chromosome[T]]
 	Genome     Option[*Genome[T]]
+	Strategy   Option[[]float64]
 }
 
+// Recombines each level of genetic material that both Codes have into child,
+// reusing the child's existing material where possible. If a level cannot be
+// recombined (e.g. permutation crossover of parents that are not permutations
+// of each other), the child receives a copy of c's material for that level.
 func (c Code[T]) Recombine(other Code[T], child *Code[T],
 	recombinationOpts RecombineOptions) {
 	if c.Gene.Ok() && other.Gene.Ok() &&
@@ -23,9 +30,12 @@ func (c Code[T]) Recombine(other Code[T], child *Code[T],
 		if !child.Gene.Ok() {
 			child.Gene = NewOption(&Gene[T]{})
 		}
-		_ = c.Gene.Val.Recombine(
+		err := c.Gene.Val.Recombine(
 			other.Gene.Val, []int{}, child.Gene.Val, recombinationOpts,
 		)
+		if err != nil {
+			child.Gene.Val = c.Gene.Val.Copy()
+		}
 		child.Gene.IsSet = true
 	}
 	if c.Nucleosome.Ok() && other.Nucleosome.Ok() &&
@@ -34,9 +44,12 @@ func (c Code[T]) Recombine(other Code[T], child *Code[T],
 		if !child.Nucleosome.Ok() {
 			child.Nucleosome = NewOption(&Nucleosome[T]{})
 		}
-		_ = c.Nucleosome.Val.Recombine(
+		err := c.Nucleosome.Val.Recombine(
 			other.Nucleosome.Val, []int{}, child.Nucleosome.Val, recombinationOpts,
 		)
+		if err != nil {
+			child.Nucleosome.Val = c.Nucleosome.Val.DeepCopy()
+		}
 		child.Nucleosome.IsSet = true
 	}
 	if c.Chromosome.Ok() && other.Chromosome.Ok() &&
@@ -45,9 +58,12 @@ func (c Code[T]) Recombine(other Code[T], child *Code[T],
 		if !child.Chromosome.Ok() {
 			child.Chromosome = NewOption(&Chromosome[T]{})
 		}
-		_ = c.Chromosome.Val.Recombine(
+		err := c.Chromosome.Val.Recombine(
 			other.Chromosome.Val, []int{}, child.Chromosome.Val, recombinationOpts,
 		)
+		if err != nil {
+			child.Chromosome.Val = c.Chromosome.Val.DeepCopy()
+		}
 		child.Chromosome.IsSet = true
 	}
 	if c.Genome.Ok() && other.Genome.Ok() &&
@@ -56,11 +72,26 @@ func (c Code[T]) Recombine(other Code[T], child *Code[T],
 		if !child.Genome.Ok() {
 			child.Genome = NewOption(&Genome[T]{})
 		}
-		_ = c.Genome.Val.Recombine(
+		err := c.Genome.Val.Recombine(
 			other.Genome.Val, []int{}, child.Genome.Val, recombinationOpts,
 		)
+		if err != nil {
+			child.Genome.Val = c.Genome.Val.DeepCopy()
+		}
 		child.Genome.IsSet = true
 	}
+	switch {
+	case c.Strategy.Ok() && other.Strategy.Ok():
+		child.Strategy = NewOption(intermediateStrategy(c.Strategy.Val, other.Strategy.Val))
+	case c.Strategy.Ok() || other.Strategy.Ok():
+		strategy := c.Strategy
+		if !strategy.Ok() {
+			strategy = other.Strategy
+		}
+		child.Strategy = NewOption(append([]float64{}, strategy.Val...))
+	default:
+		child.Strategy = Option[[]float64]{}
+	}
 }
 
 func (c Code[T]) Copy() Code[T] {
@@ -77,20 +108,75 @@ func (c Code[T]) Copy() Code[T] {
 	if c.Genome.Ok() {
 		gm.Genome = NewOption(c.Genome.Val.Copy())
 	}
+	if c.Strategy.Ok() {
+		gm.Strategy = NewOption(append([]float64{}, c.Strategy.Val...))
+	}
+	return gm
+}
+
+// Copies the Code such that no genetic material is shared with the original.
+func (c Code[T]) DeepCopy() Code[T] {
+	gm := Code[T]{}
+	if c.Gene.Ok() {
+		gm.Gene = NewOption(c.Gene.Val.Copy())
+	}
+	if c.Nucleosome.Ok() {
+		gm.Nucleosome = NewOption(c.Nucleosome.Val.DeepCopy())
+	}
+	if c.Chromosome.Ok() {
+		gm.Chromosome = NewOption(c.Chromosome.Val.DeepCopy())
+	}
+	if c.Genome.Ok() {
+		gm.Genome = NewOption(c.Genome.Val.DeepCopy())
+	}
+	if c.Strategy.Ok() {
+		gm.Strategy = NewOption(append([]float64{}, c.Strategy.Val...))
+	}
 	return gm
 }
 
 type OptimizationParams[T Ordered] struct {
-	MeasureFitness       Option[func(Code[T]) float64]
-	Mutate               Option[func(*Code[T])]
-	InitialPopulation    Option[[]Code[T]]
-	MaxIterations        Option[int]
-	PopulationSize       Option[int]
-	ParentsPerGeneration Option[int]
-	FitnessTarget        Option[float64]
-	RecombinationOpts    Option[RecombineOptions]
-	ParallelCount        Option[int]
-	IterationHook        Option[func(int, []*ScoredCode[T])]
+	MeasureFitness          Option[func(Code[T]) float64]
+	Mutate                  Option[func(*Code[T])]
+	InitialPopulation       Option[[]Code[T]]
+	MaxIterations           Option[int]
+	PopulationSize          Option[int]
+	ParentsPerGeneration    Option[int]
+	FitnessTarget           Option[float64]
+	RecombinationOpts       Option[RecombineOptions]
+	ParallelCount           Option[int]
+	IterationHook           Option[func(int, []*ScoredCode[T])]
+	Selector                Option[Selector[T]]
+	TimeLimit               Option[time.Duration]
+	MaxEvaluations          Option[int]
+	StagnationLimit         Option[int]
+	StagnationEpsilon       Option[float64]
+	MinDiversity            Option[float64]
+	MaxRestarts             Option[int]
+	RestartOptions          Option[MakeOptions[T]]
+	RestartPopulationGrowth Option[float64]
+	HallOfFameSize          Option[int]
+	Rand                    Option[*Rand]
+	CheckpointInterval      Option[int]
+	CheckpointWriter        Option[func(int) (io.Writer, error)]
+	StatsHook               Option[func(GenerationStats)]
+	FitnessCache            Option[*FitnessCache]
+	MutateWithRate          Option[func(*Code[T], float64, *Rand)]
+	MutationRate            Option[RateSchedule]
+	SelfAdaptation          Option[SelfAdaptation]
+	Schema                  Option[Schema[T]]
+	RepairMethod            Option[RepairMethod]
+	Constraints             Option[Constraints[T]]
+	Direction               Option[Direction]
+	Replacement             Option[ReplacementScheme]
+	Survivors               Option[int]
+	Offspring               Option[int]
+	AgeLayers               Option[int]
+	AgeGap                  Option[int]
+	Niching                 Option[Niching[T]]
+	Speciation              Option[Speciation[T]]
+	MutateWithRand          Option[func(*Code[T], *Rand)]
+	DistanceSamples         Option[int]
 }
 
 type BenchmarkResult struct {
@@ -101,63 +187,92 @@ type BenchmarkResult struct {
 }
 
 type ScoredCode[T Ordered] struct {
-	Code  Code[T]
-	Score float64
+	Code      Code[T]
+	Score     float64
+	Violation float64
+	Age       int
+	Species   int
 }
 
-func sortScoredCodes[T Ordered](scores []*ScoredCode[T]) {
+// Sorts the scores best first.
+func sortScoredCodes[T Ordered](scores []*ScoredCode[T], direction Direction) {
 	sort.SliceStable(scores, func(i, j int) bool {
-		return scores[i].Score > scores[j].Score
+		return direction.better(scores[i].Score, scores[j].Score)
 	})
 }
 
 func RandomChoices[T any](items []T, k int) []T {
+	return randomChoices(nil, items, k)
+}
+
+func randomChoices[T any](rng *Rand, items []T, k int) []T {
 	choices := []T{}
 
 	for len(choices) < k {
-		i := RandomInt(0, len(items)-1)
+		i := randomInt(rng, 0, len(items)-1)
 		choices = append(choices, items[i])
 	}
 	return choices
 }
 
-func weightedParents[T Ordered](scores []*ScoredCode[T]) []Code[T] {
-	parents := []Code[T]{}
-	weight := len(scores)
-	for i, l := 0, len(scores); i < l; i++ {
-		for j := 0; j < weight; j++ {
-			parents = append(parents, scores[i].Code)
-		}
-		weight--
-	}
-	return parents
+func Optimize[T Ordered](params OptimizationParams[T]) (int, []*ScoredCode[T], error) {
+	return OptimizeContext(context.Background(), params)
 }
 
-func weightedRandomParents[T Ordered](parents []Code[T]) (Code[T], Code[T]) {
-	dad_and_mom := RandomChoices(parents, 2)
-	dad := dad_and_mom[0]
-	mom := dad_and_mom[1]
-	for mom == dad {
-		mom = RandomChoices(parents, 1)[0]
+// Runs the optimization until MaxIterations or FitnessTarget is reached or ctx
+// is done. When ctx is done (or TimeLimit elapses), the best-so-far population
+// is returned along with ctx.Err(); the generation count only includes fully
+// completed generations.
+func OptimizeContext[T Ordered](ctx context.Context, params OptimizationParams[T]) (int, []*ScoredCode[T], error) {
+	params, err := prepareOptimizationParams(params)
+	if err != nil {
+		return 0, []*ScoredCode[T]{}, err
+	}
+	if params.TimeLimit.Ok() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.TimeLimit.Val)
+		defer cancel()
 	}
-	return dad, mom
-}
 
-func Optimize[T Ordered](params OptimizationParams[T]) (int, []*ScoredCode[T], error) {
-	generation_count := 0
-	scores := []*ScoredCode[T]{}
+	return optimize(ctx, params, breederFor(params))
+}
 
+// Validates the params and fills in defaults for any that are missing.
+func prepareOptimizationParams[T Ordered](params OptimizationParams[T]) (OptimizationParams[T], error) {
 	if !params.InitialPopulation.Ok() {
-		return generation_count, scores, missingParameterError{"params.InitialPopulation"}
+		return params, missingParameterError{"params.InitialPopulation"}
 	}
 	if len(params.InitialPopulation.Val) < 1 {
-		return generation_count, scores, anError{"params.InitialPopulation Must have len > 0"}
+		return params, anError{"params.InitialPopulation Must have len > 0"}
 	}
 	if !params.MeasureFitness.Ok() {
-		return generation_count, scores, missingParameterError{"params.MeasureFitness"}
+		return params, missingParameterError{"params.MeasureFitness"}
+	}
+	if !params.Mutate.Ok() && !params.MutateWithRate.Ok() && !params.MutateWithRand.Ok() {
+		return params, missingParameterError{"params.Mutate"}
+	}
+	if params.MutateWithRand.Ok() && (params.Mutate.Ok() || params.MutateWithRate.Ok()) {
+		return params, anError{"params.MutateWithRand cannot be used with params.Mutate or params.MutateWithRate"}
+	}
+	if params.MutationRate.Ok() && params.SelfAdaptation.Ok() {
+		return params, anError{"params.MutationRate and params.SelfAdaptation cannot be used together"}
 	}
-	if !params.Mutate.Ok() {
-		return generation_count, scores, missingParameterError{"params.Mutate"}
+	if params.MutationRate.Ok() && !params.MutateWithRate.Ok() {
+		return params, missingParameterError{"params.MutateWithRate"}
+	}
+	if params.MutateWithRate.Ok() && !params.MutationRate.Ok() && !params.SelfAdaptation.Ok() {
+		return params, missingParameterError{"params.MutationRate"}
+	}
+	if params.SelfAdaptation.Ok() {
+		if len(params.SelfAdaptation.Val.Initial) == 0 {
+			return params, missingParameterError{"params.SelfAdaptation.Initial"}
+		}
+		if params.MutateWithRate.Ok() {
+			mutate := params.MutateWithRate.Val
+			params.MutateWithRand = NewOption(func(code *Code[T], rng *Rand) {
+				mutate(code, code.Strategy.Val[0], rng)
+			})
+		}
 	}
 	if !params.MaxIterations.Ok() {
 		params.MaxIterations.Val = 1000
@@ -166,165 +281,606 @@ func Optimize[T Ordered](params OptimizationParams[T]) (int, []*ScoredCode[T], e
 		params.PopulationSize.Val = 100
 	}
 	if params.PopulationSize.Val < 3 {
-		return generation_count, scores, anError{"params.PopulationSize must be at least 3"}
+		return params, anError{"params.PopulationSize must be at least 3"}
 	}
 	if !params.ParentsPerGeneration.Ok() {
 		params.ParentsPerGeneration.Val = 10
 	}
-	if !params.FitnessTarget.Ok() {
-		params.FitnessTarget.Val = float64(0.99)
-	}
+	params.FitnessTarget.Val = fitnessTarget(params.FitnessTarget, params.Direction.Val)
 	if params.ParentsPerGeneration.Val > params.PopulationSize.Val {
 		params.ParentsPerGeneration.Val = params.PopulationSize.Val / 10
 	}
 	if params.ParentsPerGeneration.Val < 2 {
 		params.ParentsPerGeneration.Val = 2
 	}
+	if !params.Selector.Ok() {
+		params.Selector = NewOption[Selector[T]](weightedSelector[T]{})
+	}
 	if params.ParallelCount.Ok() && params.PopulationSize.Val/params.ParallelCount.Val < 1 {
 		params.ParallelCount.Val = params.PopulationSize.Val / 2
 	}
+	if params.MaxRestarts.Ok() && params.MaxRestarts.Val > 0 &&
+		!params.RestartOptions.Ok() {
+		return params, missingParameterError{"params.RestartOptions"}
+	}
+	if params.Survivors.Ok() &&
+		(params.Survivors.Val < 0 || params.Survivors.Val >= params.PopulationSize.Val) {
+		return params, anError{"params.Survivors must be at least 0 and less than params.PopulationSize"}
+	}
+	if params.Offspring.Ok() && params.Offspring.Val < 1 {
+		return params, anError{"params.Offspring must be at least 1"}
+	}
+	if params.Replacement.Val == CommaReplacement && params.Offspring.Ok() &&
+		params.Offspring.Val < params.PopulationSize.Val {
+		return params, anError{"params.Offspring must be at least params.PopulationSize for CommaReplacement"}
+	}
+	if params.Replacement.Val == AgeLayeredReplacement {
+		if !params.RestartOptions.Ok() {
+			return params, missingParameterError{"params.RestartOptions"}
+		}
+		if !params.AgeLayers.Ok() {
+			params.AgeLayers.Val = 5
+		}
+		if !params.AgeGap.Ok() {
+			params.AgeGap.Val = 10
+		}
+		if params.AgeLayers.Val < 1 || params.PopulationSize.Val/params.AgeLayers.Val < 1 {
+			return params, anError{"params.AgeLayers must be at least 1 and at most params.PopulationSize"}
+		}
+		if params.AgeGap.Val < 1 {
+			return params, anError{"params.AgeGap must be at least 1"}
+		}
+	}
+	if params.RecombinationOpts.Val.baseCrossover().realValued() {
+		var zero T
+		switch any(zero).(type) {
+		case float32, float64:
+		default:
+			return params, anError{"params.RecombinationOpts.BaseCrossover requires Float bases"}
+		}
+	}
+	for name, crossover := range map[string]Option[CrossoverOptions]{
+		"NucleosomeCrossover": params.RecombinationOpts.Val.NucleosomeCrossover,
+		"ChromosomeCrossover": params.RecombinationOpts.Val.ChromosomeCrossover,
+		"GenomeCrossover":     params.RecombinationOpts.Val.GenomeCrossover,
+	} {
+		if !crossover.Val.Method.Val.structural() {
+			return params, anError{"params.RecombinationOpts." + name + ".Method cannot be used for this level"}
+		}
+	}
+	if params.Niching.Ok() {
+		method := params.Niching.Val.Method
+		if !method.replaces() && params.Niching.Val.Sigma <= 0.0 {
+			return params, anError{"params.Niching.Sigma must be > 0 for " + method.String()}
+		}
+		if method.replaces() && params.Replacement.Val != GenerationalReplacement {
+			return params, anError{"params.Replacement cannot be used with " + method.String()}
+		}
+	}
+	if params.Speciation.Ok() {
+		if params.Speciation.Val.Threshold <= 0.0 {
+			return params, anError{"params.Speciation.Threshold must be > 0"}
+		}
+		if params.Niching.Ok() || params.Replacement.Val != GenerationalReplacement {
+			return params, anError{"params.Speciation cannot be used with params.Niching or params.Replacement"}
+		}
+	}
+	if params.DistanceSamples.Ok() && params.DistanceSamples.Val < 1 {
+		return params, anError{"params.DistanceSamples must be at least 1"}
+	}
+	if !params.CheckpointInterval.Ok() || params.CheckpointInterval.Val < 1 {
+		params.CheckpointInterval.Val = 10
+	}
+	if params.Schema.Ok() && len(params.Schema.Val.Genes) > 0 &&
+		!params.RecombinationOpts.Val.InheritNames.Ok() {
+		// spliced names would not match any GeneSchema
+		params.RecombinationOpts.Val.InheritNames = NewOption(true)
+	}
+	if !params.Rand.Ok() && params.RecombinationOpts.Val.Rand.Ok() {
+		params.Rand = params.RecombinationOpts.Val.Rand
+	}
+	return params, nil
+}
 
+// A breeder creates children from consecutive pairs of mates, writing each
+// into the pre-allocated children, and reports which children were finished
+// before ctx was done and how many of their scores came from the FitnessCache.
+type breeder[T Ordered] func(ctx context.Context, params OptimizationParams[T],
+	mates []Code[T], children []*ScoredCode[T], rng *Rand) ([]bool, int)
+
+// Returns the breeder to use according to params.ParallelCount.
+func breederFor[T Ordered](params OptimizationParams[T]) breeder[T] {
 	if params.ParallelCount.Ok() && params.ParallelCount.Val > 1 {
-		return optimizeInParallel(params)
-	} else {
-		return optimizeSequentially(params)
+		return optimizeInParallel[T]
 	}
+	return optimizeSequentially[T]
 }
 
-func optimizeInParallel[T Ordered](params OptimizationParams[T]) (int, []*ScoredCode[T], error) {
-	generation_count := 0
-	scores_pool_size, _ := max(params.PopulationSize.Val, len(params.InitialPopulation.Val))
-	scores_pool := make(chan *ScoredCode[T], scores_pool_size+10)
-	for i := 0; i < scores_pool_size; i++ {
-		scores_pool <- &ScoredCode[T]{}
+// Breeds, mutates, and scores the child. Returns true if the score came from
+// the FitnessCache.
+func breedChild[T Ordered](params OptimizationParams[T], dad, mom Code[T],
+	child *ScoredCode[T], rng *Rand) bool {
+	recombination_opts := params.RecombinationOpts.Val
+	recombination_opts.Rand = NewOption(rng)
+	dad.Recombine(mom, &child.Code, recombination_opts)
+	if params.SelfAdaptation.Ok() {
+		child.Code.Strategy = NewOption(params.SelfAdaptation.Val.mutate(child.Code.Strategy.Val, rng))
+	}
+	mutateWith(params, rng)(&child.Code)
+	if params.Schema.Ok() {
+		params.Schema.Val.Repair(&child.Code, params.RepairMethod.Val)
+	}
+	score, cached := measureFitness(params, child.Code)
+	child.Score = score
+	if params.Constraints.Ok() {
+		child.Violation = params.Constraints.Val.violation(child.Code)
+	}
+	return cached
+}
+
+// Returns params.Mutate, or params.MutateWithRand drawing from rng.
+func mutateWith[T Ordered](params OptimizationParams[T], rng *Rand) func(*Code[T]) {
+	if params.MutateWithRand.Ok() {
+		return func(code *Code[T]) { params.MutateWithRand.Val(code, rng) }
 	}
-	scores := []*ScoredCode[T]{}
-	var wg sync.WaitGroup
-	work_done := make(chan *ScoredCode[T], params.PopulationSize.Val+10)
-	done_signal := make(chan bool, params.ParallelCount.Val)
-	measure_fitness := params.MeasureFitness.Val
-	Mutate := params.Mutate.Val
-	for _, code := range params.InitialPopulation.Val {
-		score := <-scores_pool
+	return params.Mutate.Val
+}
+
+type optimizationRun[T Ordered] struct {
+	params              OptimizationParams[T]
+	breed               breeder[T]
+	rng                 *Rand
+	pool                []*ScoredCode[T]
+	scores              []*ScoredCode[T]
+	generationCount     int
+	evaluations         int
+	cacheHits           int
+	bestFitness         float64
+	stagnationBaseline  float64
+	stagnantGenerations int
+	restarts            int
+	hallOfFame          []*ScoredCode[T]
+	stopReason          StopReason
+	started             time.Time
+	keepHistory         bool
+	history             []GenerationStats
+	mutationRate        float64
+	successRatio        float64
+	penalty             float64
+	species             map[int]*species[T]
+	nextSpecies         int
+	niched              map[*ScoredCode[T]]float64
+	nichedGeneration    int
+}
+
+func (r *optimizationRun[T]) getScoredCode() *ScoredCode[T] {
+	if len(r.pool) == 0 {
+		return &ScoredCode[T]{}
+	}
+	score := r.pool[len(r.pool)-1]
+	r.pool = r.pool[:len(r.pool)-1]
+	score.Species = 0
+	// a reused member no longer has the niched fitness cached for it
+	delete(r.niched, score)
+	return score
+}
+
+func (r *optimizationRun[T]) putScoredCode(score *ScoredCode[T]) {
+	r.pool = append(r.pool, score)
+}
+
+// Scores each Code and adds it to the population, stopping early if ctx is
+// done.
+func (r *optimizationRun[T]) populate(ctx context.Context, codes []Code[T]) {
+	for _, code := range codes {
+		if ctx.Err() != nil {
+			break
+		}
+		score := r.getScoredCode()
 		score.Code = code
-		score.Score = measure_fitness(code)
-		scores = append(scores, score)
-	}
-	sortScoredCodes(scores)
-	best_fitness := scores[0].Score
-
-	for generation_count < params.MaxIterations.Val && best_fitness < params.FitnessTarget.Val {
-		generation_count++
-		for _, score := range scores[params.ParentsPerGeneration.Val:] {
-			scores_pool <- score
-		}
-		scores = scores[:params.ParentsPerGeneration.Val]
-		parents := weightedParents(scores)
-		children_to_create := (params.PopulationSize.Val -
-			params.ParentsPerGeneration.Val) / params.ParallelCount.Val
-
-		for i := params.ParallelCount.Val; i > 0; i-- {
-			diff := 0
-			if i == 1 {
-				diff = params.PopulationSize.Val - params.ParentsPerGeneration.Val
-				diff -= params.ParallelCount.Val * children_to_create
-			}
-			wg.Add(1)
-			go func(count int, parents []Code[T], work_done chan<- *ScoredCode[T], done_signal chan<- bool, scores_pool <-chan *ScoredCode[T]) {
-				defer wg.Done()
-				for c := 0; c < count; c++ {
-					child := <-scores_pool
-					mom, dad := weightedRandomParents(parents)
-					dad.Recombine(mom, &child.Code, params.RecombinationOpts.Val)
-					Mutate(&child.Code)
-					child.Score = measure_fitness(child.Code)
-					work_done <- child
-				}
-				done_signal <- true
-			}(children_to_create+diff, parents, work_done, done_signal, scores_pool)
+		score.Violation = 0.0
+		score.Age = 0
+		cached := false
+		score.Score, cached = measureFitness(r.params, code)
+		if r.params.Constraints.Ok() {
+			score.Violation = r.params.Constraints.Val.violation(code)
+		}
+		if cached {
+			r.cacheHits++
+		} else {
+			r.evaluations++
 		}
+		r.scores = append(r.scores, score)
+	}
+	r.rank()
+}
 
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			finished := 0
-			for finished < params.ParallelCount.Val {
-				select {
-				case child := <-work_done:
-					scores = append(scores, child)
-				case <-done_signal:
-					finished += 1
-				default:
-				}
-			}
-			finished = 0
-			for finished == 0 {
-				select {
-				case child := <-work_done:
-					scores = append(scores, child)
-				default:
-					finished = 1
-				}
-			}
-		}()
+// Orders the population best first, according to params.Niching or
+// params.Constraints if set, updates bestFitness to the best Score of a
+// feasible member, and assigns new members to species with params.Speciation.
+func (r *optimizationRun[T]) rank() {
+	if len(r.scores) == 0 {
+		return
+	}
+	direction := r.params.Direction.Val
+	switch {
+	case r.params.Niching.Ok() && !r.nicheReplacement():
+		r.nicheRank()
+		r.bestFitness = bestFeasibleScore(r.scores, direction)
+	case !r.params.Constraints.Ok():
+		sortScoredCodes(r.scores, direction)
+		r.bestFitness = r.scores[0].Score
+	default:
+		r.params.Constraints.Val.rank(r.scores, r.penalty, direction, r.rng)
+		r.bestFitness = bestFeasibleScore(r.scores, direction)
+	}
+	if r.params.Speciation.Ok() {
+		r.speciate()
+	}
+}
 
-		wg.Wait()
-		sortScoredCodes(scores)
-		best_fitness = scores[0].Score
+func (r *optimizationRun[T]) evaluationsRemaining() int {
+	if !r.params.MaxEvaluations.Ok() {
+		return math.MaxInt
+	}
+	return r.params.MaxEvaluations.Val - r.evaluations
+}
+
+// Returns true if the best Score has reached the FitnessTarget.
+func (r *optimizationRun[T]) targetReached() bool {
+	direction := r.params.Direction.Val
+	return direction.fitness(r.bestFitness) >= direction.fitness(r.params.FitnessTarget.Val)
+}
+
+func (r *optimizationRun[T]) done() bool {
+	return r.stopReason != StopNone ||
+		r.generationCount >= r.params.MaxIterations.Val ||
+		r.targetReached() ||
+		r.evaluationsRemaining() <= 0
+}
 
-		if params.IterationHook.Ok() {
-			params.IterationHook.Val(generation_count, scores)
+func (r *optimizationRun[T]) generation(ctx context.Context) error {
+	for _, score := range r.scores {
+		score.Age++
+	}
+	if err := r.reseedYoungestLayer(ctx); err != nil {
+		return err
+	}
+	n_children := r.offspringCount()
+	parents, mates := r.selectParents(n_children)
+	n_children = len(mates) / 2
+	children := make([]*ScoredCode[T], n_children)
+	for i := range children {
+		children[i] = r.getScoredCode()
+	}
+
+	params := r.params
+	if params.MutationRate.Ok() {
+		r.mutationRate = params.MutationRate.Val.Rate(r.generationCount, r.mutationRate, r.successRatio)
+		rate, mutate := r.mutationRate, params.MutateWithRate.Val
+		params.MutateWithRand = NewOption(func(code *Code[T], rng *Rand) { mutate(code, rate, rng) })
+	}
+
+	completed, hits := r.breed(ctx, params, mates, children, r.rng)
+	if params.MutationRate.Ok() {
+		r.successRatio = successRatio(parents, mates, children, completed, r.params.Direction.Val)
+	}
+	r.setChildAges(mates, children)
+	completed_mates, completed_children := []Code[T]{}, []*ScoredCode[T]{}
+	for i, child := range children {
+		if completed[i] {
+			completed_mates = append(completed_mates, mates[2*i], mates[2*i+1])
+			completed_children = append(completed_children, child)
+			r.evaluations++
+		} else {
+			r.putScoredCode(child)
 		}
 	}
+	r.evaluations -= hits
+	r.cacheHits += hits
+	r.replace(completed_mates, completed_children)
 
-	return generation_count, scores, nil
+	r.rank()
+	if r.params.Constraints.Ok() {
+		r.penalty = r.params.Constraints.Val.adaptPenalty(r.penalty, r.scores)
+	}
+	if r.params.Speciation.Ok() {
+		r.updateSpecies()
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.generationCount++
+	return nil
 }
 
-func optimizeSequentially[T Ordered](params OptimizationParams[T]) (int,
-	[]*ScoredCode[T], error) {
-	generation_count := 0
-	scores_pool_size, _ := max(params.PopulationSize.Val, len(params.InitialPopulation.Val))
-	scores_pool := make(chan *ScoredCode[T], scores_pool_size+10)
-	for i := 0; i < scores_pool_size; i++ {
-		scores_pool <- &ScoredCode[T]{}
+// Returns StopStagnation or StopDiversity if the run has converged according to
+// the stagnation and diversity criteria, or StopNone otherwise.
+func (r *optimizationRun[T]) converged() StopReason {
+	epsilon := 0.0
+	if r.params.StagnationEpsilon.Ok() {
+		epsilon = r.params.StagnationEpsilon.Val
+	}
+	direction := r.params.Direction.Val
+	if direction.fitness(r.bestFitness) > direction.fitness(r.stagnationBaseline)+epsilon {
+		r.stagnationBaseline = r.bestFitness
+		r.stagnantGenerations = 0
+	} else {
+		r.stagnantGenerations++
 	}
-	scores := []*ScoredCode[T]{}
-	measure_fitness := params.MeasureFitness.Val
-	Mutate := params.Mutate.Val
-	for _, code := range params.InitialPopulation.Val {
-		score := <-scores_pool
-		score.Code = code
-		score.Score = measure_fitness(code)
-		scores = append(scores, score)
+	if r.params.StagnationLimit.Ok() &&
+		r.stagnantGenerations >= r.params.StagnationLimit.Val {
+		return StopStagnation
+	}
+	if r.params.MinDiversity.Ok() &&
+		uniqueGenotypeRatio(r.scores) < r.params.MinDiversity.Val {
+		return StopDiversity
+	}
+	return StopNone
+}
+
+func (r *optimizationRun[T]) updateHallOfFame() {
+	size := r.params.ParentsPerGeneration.Val
+	if r.params.HallOfFameSize.Ok() {
+		size = r.params.HallOfFameSize.Val
+	}
+	if size < 1 {
+		return
+	}
+	keys := newSet[string]()
+	for _, famous := range r.hallOfFame {
+		keys.add(famous.Code.key())
+	}
+	for _, score := range r.scores {
+		if score.Violation > 0.0 {
+			continue
+		}
+		if len(r.hallOfFame) >= size &&
+			!r.params.Direction.Val.better(score.Score, r.hallOfFame[len(r.hallOfFame)-1].Score) {
+			continue
+		}
+		key := score.Code.key()
+		if keys.contains(key) {
+			continue
+		}
+		keys.add(key)
+		r.hallOfFame = append(r.hallOfFame, &ScoredCode[T]{
+			Code: score.Code.DeepCopy(), Score: score.Score,
+		})
+		sortScoredCodes(r.hallOfFame, r.params.Direction.Val)
+		if len(r.hallOfFame) > size {
+			r.hallOfFame = r.hallOfFame[:size]
+		}
+	}
+}
+
+// Reseeds the population from the hall of fame and fresh random Code, growing
+// the population size by RestartPopulationGrowth (IPOP-style).
+func (r *optimizationRun[T]) restart(ctx context.Context) error {
+	growth := 2.0
+	if r.params.RestartPopulationGrowth.Ok() {
+		growth = r.params.RestartPopulationGrowth.Val
+	}
+	population_size := int(float64(r.params.PopulationSize.Val) * growth)
+	population_size, _ = max(population_size, r.params.PopulationSize.Val)
+	// a restart that cannot be evaluated in full would lose the population
+	if r.evaluationsRemaining() < population_size {
+		r.stopReason = StopMaxEvaluations
+		return nil
+	}
+
+	codes := []Code[T]{}
+	for _, famous := range r.hallOfFame {
+		codes = append(codes, famous.Code.DeepCopy())
+	}
+	random_codes, err := r.randomCodes(population_size - len(codes))
+	if err != nil {
+		return err
+	}
+	codes = append(codes, random_codes...)
+	if len(codes) == 0 {
+		r.stopReason = StopMaxEvaluations
+		return nil
+	}
+
+	r.restarts++
+	r.params.PopulationSize.Val = population_size
+	for _, score := range r.scores {
+		r.putScoredCode(score)
+	}
+	r.scores = r.scores[:0]
+	r.populate(ctx, codes)
+	r.stagnationBaseline = r.bestFitness
+	r.stagnantGenerations = 0
+	return ctx.Err()
+}
+
+// Returns n fresh random Codes made with params.RestartOptions like the first
+// member of the InitialPopulation.
+func (r *optimizationRun[T]) randomCodes(n int) ([]Code[T], error) {
+	template := r.params.InitialPopulation.Val[0]
+	make_opts := r.params.RestartOptions.Val
+	if !make_opts.Rand.Ok() {
+		make_opts.Rand = NewOption(r.rng)
+	}
+	if !make_opts.Schema.Ok() {
+		make_opts.Schema = r.params.Schema
+	}
+	n, _ = min(n, r.evaluationsRemaining())
+	codes := []Code[T]{}
+	for len(codes) < n {
+		code, err := MakeCode(make_opts, template)
+		if err != nil {
+			return codes, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// Sets up a run and scores the initial population.
+func newOptimizationRun[T Ordered](ctx context.Context, params OptimizationParams[T],
+	breed breeder[T]) (*optimizationRun[T], error) {
+	r := &optimizationRun[T]{
+		params: params, breed: breed, rng: params.Rand.Val, started: time.Now(),
+	}
+	pool_size, _ := max(params.PopulationSize.Val, len(params.InitialPopulation.Val))
+	for i := 0; i < pool_size; i++ {
+		r.putScoredCode(&ScoredCode[T]{})
+	}
+	if params.Constraints.Ok() {
+		r.penalty = params.Constraints.Val.initialPenalty()
+	}
+	r.populate(ctx, params.InitialPopulation.Val)
+	if err := ctx.Err(); err != nil {
+		return r, err
+	}
+	r.stagnationBaseline = r.bestFitness
+	if params.Speciation.Ok() {
+		r.updateSpecies()
+	}
+	if r.useRestarts() {
+		r.updateHallOfFame()
 	}
-	sortScoredCodes(scores)
-	best_fitness := scores[0].Score
+	return r, nil
+}
+
+func (r *optimizationRun[T]) useRestarts() bool {
+	return r.params.MaxRestarts.Ok() && r.params.MaxRestarts.Val > 0
+}
+
+// Runs one generation followed by the IterationHook and the convergence
+// checks, restarting or stopping the run if it has converged.
+func (r *optimizationRun[T]) step(ctx context.Context) error {
+	if err := r.generation(ctx); err != nil {
+		return err
+	}
+
+	if r.params.IterationHook.Ok() {
+		r.params.IterationHook.Val(r.generationCount, r.scores)
+	}
+	r.recordStats()
+
+	if r.useRestarts() {
+		r.updateHallOfFame()
+	}
+	if reason := r.converged(); reason != StopNone {
+		if !r.useRestarts() || r.restarts >= r.params.MaxRestarts.Val {
+			r.stopReason = reason
+			return nil
+		}
+		return r.restart(ctx)
+	}
+	return nil
+}
+
+func optimize[T Ordered](ctx context.Context, params OptimizationParams[T],
+	breed breeder[T]) (int, []*ScoredCode[T], error) {
+	r, err := newOptimizationRun(ctx, params, breed)
+	if err != nil {
+		return r.generationCount, r.scores, err
+	}
+	return r.run(ctx)
+}
 
-	for generation_count < params.MaxIterations.Val && best_fitness < params.FitnessTarget.Val {
-		generation_count++
-		for _, score := range scores[params.ParentsPerGeneration.Val:] {
-			scores_pool <- score
+// Steps the run until it is done, writing checkpoints along the way.
+func (r *optimizationRun[T]) run(ctx context.Context) (int, []*ScoredCode[T], error) {
+	if r.generationCount == 0 {
+		r.recordStats()
+	}
+	for !r.done() {
+		if err := ctx.Err(); err != nil {
+			return r.generationCount, r.scores, err
 		}
-		scores = scores[:params.ParentsPerGeneration.Val]
-		parents := weightedParents(scores)
-		for len(scores) < params.PopulationSize.Val {
-			child := <-scores_pool
-			mom, dad := weightedRandomParents(parents)
-			dad.Recombine(mom, &child.Code, params.RecombinationOpts.Val)
-			Mutate(&child.Code)
-			child.Score = measure_fitness(child.Code)
-			scores = append(scores, child)
+		if err := r.step(ctx); err != nil {
+			return r.generationCount, r.scores, err
 		}
+		if r.checkpointDue() {
+			if err := r.writeCheckpoint(); err != nil {
+				return r.generationCount, r.scores, err
+			}
+		}
+	}
 
-		sortScoredCodes(scores)
-		best_fitness = scores[0].Score
+	return r.generationCount, r.scores, nil
+}
 
-		if params.IterationHook.Ok() {
-			params.IterationHook.Val(generation_count, scores)
+// Returns the number of unique genotypes divided by the population size.
+func uniqueGenotypeRatio[T Ordered](scores []*ScoredCode[T]) float64 {
+	if len(scores) == 0 {
+		return 0.0
+	}
+	return float64(UniqueGenotypes(scores)) / float64(len(scores))
+}
+
+// Calls measure for every index from 0 to n-1, spreading the calls across
+// parallel_count goroutines.
+func measureConcurrently(n, parallel_count int, measure func(i int)) {
+	if parallel_count < 1 {
+		parallel_count = 1
+	}
+	var wg sync.WaitGroup
+	chunk_size := int(math.Ceil(float64(n) / float64(parallel_count)))
+	for start := 0; start < n; start += chunk_size {
+		stop, _ := min(start+chunk_size, n)
+		wg.Add(1)
+		go func(start, stop int) {
+			defer wg.Done()
+			for i := start; i < stop; i++ {
+				measure(i)
+			}
+		}(start, stop)
+	}
+	wg.Wait()
+}
+
+func optimizeInParallel[T Ordered](ctx context.Context, params OptimizationParams[T],
+	mates []Code[T], children []*ScoredCode[T], rng *Rand) ([]bool, int) {
+	var wg sync.WaitGroup
+	completed := make([]bool, len(children))
+	hits := make([]int, params.ParallelCount.Val)
+	chunk_size := len(children) / params.ParallelCount.Val
+
+	for i := params.ParallelCount.Val; i > 0; i-- {
+		start := (params.ParallelCount.Val - i) * chunk_size
+		stop := start + chunk_size
+		if i == 1 {
+			stop = len(children)
 		}
+		wg.Add(1)
+		go func(start, stop int, rng *Rand, hits *int) {
+			defer wg.Done()
+			for c := start; c < stop; c++ {
+				if ctx.Err() != nil {
+					return
+				}
+				if breedChild(params, mates[2*c], mates[2*c+1], children[c], rng) {
+					*hits++
+				}
+				completed[c] = true
+			}
+		}(start, stop, rng.Split(), &hits[i-1])
 	}
 
-	return generation_count, scores, nil
+	wg.Wait()
+	return completed, reduce(hits, func(h, total int) int { return h + total })
+}
+
+func optimizeSequentially[T Ordered](ctx context.Context, params OptimizationParams[T],
+	mates []Code[T], children []*ScoredCode[T], rng *Rand) ([]bool, int) {
+	completed := make([]bool, len(children))
+	hits := 0
+	for c, child := range children {
+		if ctx.Err() != nil {
+			break
+		}
+		if breedChild(params, mates[2*c], mates[2*c+1], child, rng) {
+			hits++
+		}
+		completed[c] = true
+	}
+	return completed, hits
 }
 
 func TuneOptimization[T Ordered](params OptimizationParams[T], max_threads ...int) (int, error) {
@@ -342,7 +898,7 @@ func TuneOptimization[T Ordered](params OptimizationParams[T], max_threads ...in
 	if !params.MeasureFitness.Ok() {
 		return n_goroutines, missingParameterError{"params.MeasureFitness"}
 	}
-	if !params.Mutate.Ok() {
+	if !params.Mutate.Ok() && !params.MutateWithRate.Ok() && !params.MutateWithRand.Ok() {
 		return n_goroutines, missingParameterError{"params.Mutate"}
 	}
 	if !params.PopulationSize.Ok() {
@@ -368,8 +924,13 @@ func TuneOptimization[T Ordered](params OptimizationParams[T], max_threads ...in
 func BenchmarkOptimization[T Ordered](params OptimizationParams[T]) BenchmarkResult {
 	res := testing.Benchmark(func(b *testing.B) {
 		gm := params.InitialPopulation.Val[0]
+		mutate := mutateWith(params, params.Rand.Val)
+		if params.MutateWithRate.Ok() {
+			rate := initialMutationRate(params)
+			mutate = func(code *Code[T]) { params.MutateWithRate.Val(code, rate, params.Rand.Val) }
+		}
 		for i := 0; i < b.N; i++ {
-			params.Mutate.Val(&gm)
+			mutate(&gm)
 		}
 	})
 	CostOfMutate := res.T / time.Duration(res.N)
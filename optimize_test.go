@@ -1,9 +1,13 @@
 package bluegenes
 
 import (
+	"context"
+	"fmt"
 	"math"
 	"math/rand"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 var target = 12345
@@ -176,6 +180,20 @@ func measureCodeFitnessExpensive(code Code[int]) float64 {
 	return fitness / float64(fitness_count)
 }
 
+func geneInitialPopulation(size int) []Code[int] {
+	base_factory := func() int { return RandomInt(-10, 10) }
+	opts := MakeOptions[int]{
+		NBases:      NewOption(uint(5)),
+		BaseFactory: NewOption(base_factory),
+	}
+	initial_population := []Code[int]{}
+	for i := 0; i < size; i++ {
+		gene, _ := MakeGene(opts)
+		initial_population = append(initial_population, Code[int]{Gene: NewOption(gene)})
+	}
+	return initial_population
+}
+
 func TestOptimize(t *testing.T) {
 	t.Run("Gene", func(t *testing.T) {
 		t.Run("parallel", func(t *testing.T) {
@@ -657,6 +675,266 @@ func TestOptimize(t *testing.T) {
 			}
 		})
 	})
+	t.Run("Selector", func(t *testing.T) {
+		selectors := map[string]Selector[int]{
+			"Tournament":          TournamentSelector[int]{K: 3},
+			"Roulette":            RouletteSelector[int]{},
+			"StochasticUniversal": StochasticUniversalSelector[int]{},
+			"LinearRank":          LinearRankSelector[int]{Pressure: 1.8},
+			"ExponentialRank":     ExponentialRankSelector[int]{Base: 0.7},
+			"Boltzmann":           BoltzmannSelector[int]{Temperature: 0.05},
+		}
+		for name, selector := range selectors {
+			for _, parallel_count := range []int{1, 10} {
+				name, selector, parallel_count := name, selector, parallel_count
+				t.Run(fmt.Sprintf("%s/%d", name, parallel_count), func(t *testing.T) {
+					t.Parallel()
+					n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+						InitialPopulation: NewOption(geneInitialPopulation(10)),
+						MeasureFitness:    NewOption(measureCodeFitness),
+						Mutate:            NewOption(MutateCode),
+						MaxIterations:     NewOption(1000),
+						ParallelCount:     NewOption(parallel_count),
+						Selector:          NewOption(selector),
+					})
+
+					if err != nil {
+						t.Fatalf("Optimize with %s failed with error: %v", name, err)
+					}
+
+					if len(final_population) != 100 {
+						t.Errorf("Optimize with %s failed: expected population of 100, observed %d", name, len(final_population))
+					}
+
+					if n_iterations < 1000 && final_population[0].Score < 0.9 {
+						t.Errorf("Optimize with %s failed to meet fitness threshold of 0.9: %f reached instead",
+							name, final_population[0].Score)
+					}
+				})
+			}
+		}
+	})
+	t.Run("Context", func(t *testing.T) {
+		for _, parallel_count := range []int{1, 10} {
+			parallel_count := parallel_count
+			t.Run(fmt.Sprintf("cancel/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				n_iterations, final_population, err := OptimizeContext(ctx, OptimizationParams[int]{
+					InitialPopulation: NewOption(geneInitialPopulation(10)),
+					MeasureFitness:    NewOption(measureCodeFitness),
+					Mutate:            NewOption(MutateCode),
+					MaxIterations:     NewOption(1000),
+					FitnessTarget:     NewOption(2.0),
+					ParallelCount:     NewOption(parallel_count),
+					IterationHook: NewOption(func(gc int, pop []*ScoredCode[int]) {
+						if gc == 5 {
+							cancel()
+						}
+					}),
+				})
+
+				if err != context.Canceled {
+					t.Fatalf("OptimizeContext failed: expected context.Canceled, observed %v", err)
+				}
+
+				if n_iterations != 5 {
+					t.Errorf("OptimizeContext failed: expected 5 iterations, observed %d", n_iterations)
+				}
+
+				if len(final_population) < 10 {
+					t.Errorf("OptimizeContext failed to return best-so-far population: observed len %d", len(final_population))
+				}
+			})
+			t.Run(fmt.Sprintf("TimeLimit/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				start := time.Now()
+				_, final_population, err := Optimize(OptimizationParams[int]{
+					InitialPopulation: NewOption(geneInitialPopulation(10)),
+					MeasureFitness:    NewOption(measureCodeFitnessExpensive),
+					Mutate:            NewOption(MutateCode),
+					MaxIterations:     NewOption(1000000),
+					FitnessTarget:     NewOption(2.0),
+					ParallelCount:     NewOption(parallel_count),
+					TimeLimit:         NewOption(50 * time.Millisecond),
+				})
+				elapsed := time.Since(start)
+
+				if err != context.DeadlineExceeded {
+					t.Fatalf("Optimize with TimeLimit failed: expected context.DeadlineExceeded, observed %v", err)
+				}
+
+				if elapsed > time.Second {
+					t.Errorf("Optimize with TimeLimit failed to stop promptly: took %v", elapsed)
+				}
+
+				if len(final_population) < 1 {
+					t.Fatal("Optimize with TimeLimit failed to return a population")
+				}
+
+				for i := 1; i < len(final_population); i++ {
+					if final_population[i].Score > final_population[i-1].Score {
+						t.Fatal("Optimize with TimeLimit returned an unsorted population")
+					}
+				}
+			})
+		}
+	})
+	t.Run("Convergence", func(t *testing.T) {
+		constant_fitness := func(code Code[int]) float64 { return 0.5 }
+		for _, parallel_count := range []int{1, 10} {
+			parallel_count := parallel_count
+			t.Run(fmt.Sprintf("StagnationLimit/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				n_iterations, _, err := Optimize(OptimizationParams[int]{
+					InitialPopulation: NewOption(geneInitialPopulation(10)),
+					MeasureFitness:    NewOption(constant_fitness),
+					Mutate:            NewOption(MutateCode),
+					ParallelCount:     NewOption(parallel_count),
+					StagnationLimit:   NewOption(5),
+				})
+
+				if err != nil {
+					t.Fatalf("Optimize with StagnationLimit failed with error: %v", err)
+				}
+
+				if n_iterations != 5 {
+					t.Errorf("Optimize with StagnationLimit failed: expected 5 iterations, observed %d", n_iterations)
+				}
+			})
+			t.Run(fmt.Sprintf("MaxEvaluations/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				var evaluations int64
+				_, _, err := Optimize(OptimizationParams[int]{
+					InitialPopulation: NewOption(geneInitialPopulation(10)),
+					MeasureFitness: NewOption(func(code Code[int]) float64 {
+						atomic.AddInt64(&evaluations, 1)
+						return measureCodeFitness(code)
+					}),
+					Mutate:         NewOption(MutateCode),
+					FitnessTarget:  NewOption(2.0),
+					ParallelCount:  NewOption(parallel_count),
+					MaxEvaluations: NewOption(555),
+				})
+
+				if err != nil {
+					t.Fatalf("Optimize with MaxEvaluations failed with error: %v", err)
+				}
+
+				if evaluations != 555 {
+					t.Errorf("Optimize with MaxEvaluations failed: expected 555 evaluations, observed %d", evaluations)
+				}
+			})
+			t.Run(fmt.Sprintf("MinDiversity/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				clone := geneInitialPopulation(1)[0]
+				initial_population := []Code[int]{}
+				for i := 0; i < 10; i++ {
+					initial_population = append(initial_population, clone.DeepCopy())
+				}
+				n_iterations, _, err := Optimize(OptimizationParams[int]{
+					InitialPopulation: NewOption(initial_population),
+					MeasureFitness:    NewOption(constant_fitness),
+					Mutate:            NewOption(func(code *Code[int]) {}),
+					ParallelCount:     NewOption(parallel_count),
+					MinDiversity:      NewOption(0.5),
+				})
+
+				if err != nil {
+					t.Fatalf("Optimize with MinDiversity failed with error: %v", err)
+				}
+
+				if n_iterations != 1 {
+					t.Errorf("Optimize with MinDiversity failed: expected 1 iteration, observed %d", n_iterations)
+				}
+			})
+			t.Run(fmt.Sprintf("MaxRestarts/%d", parallel_count), func(t *testing.T) {
+				t.Parallel()
+				sizes := []int{}
+				n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+					InitialPopulation: NewOption(geneInitialPopulation(10)),
+					MeasureFitness:    NewOption(constant_fitness),
+					Mutate:            NewOption(MutateCode),
+					ParallelCount:     NewOption(parallel_count),
+					StagnationLimit:   NewOption(3),
+					MaxRestarts:       NewOption(2),
+					RestartOptions: NewOption(MakeOptions[int]{
+						NBases:      NewOption(uint(5)),
+						BaseFactory: NewOption(factory),
+					}),
+					IterationHook: NewOption(func(gc int, pop []*ScoredCode[int]) {
+						sizes = append(sizes, len(pop))
+					}),
+				})
+
+				if err != nil {
+					t.Fatalf("Optimize with MaxRestarts failed with error: %v", err)
+				}
+
+				if n_iterations != 9 {
+					t.Errorf("Optimize with MaxRestarts failed: expected 9 iterations, observed %d", n_iterations)
+				}
+
+				expected := []int{100, 100, 100, 200, 200, 200, 400, 400, 400}
+				if !equal(sizes, expected) {
+					t.Errorf("Optimize with MaxRestarts failed to grow population: expected %v, observed %v", expected, sizes)
+				}
+
+				if len(final_population) != 400 {
+					t.Errorf("Optimize with MaxRestarts failed: expected final population of 400, observed %d", len(final_population))
+				}
+			})
+		}
+
+		t.Run("MaxRestarts/missing RestartOptions", func(t *testing.T) {
+			t.Parallel()
+			_, _, err := Optimize(OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(10)),
+				MeasureFitness:    NewOption(constant_fitness),
+				Mutate:            NewOption(MutateCode),
+				MaxRestarts:       NewOption(2),
+			})
+
+			if err == nil {
+				t.Errorf("Optimize with MaxRestarts failed to return error for missing RestartOptions")
+			}
+		})
+
+		t.Run("MaxRestarts/exhausted MaxEvaluations", func(t *testing.T) {
+			t.Parallel()
+			params := OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(10)),
+				MeasureFitness:    NewOption(constant_fitness),
+				Mutate:            NewOption(MutateCode),
+				PopulationSize:    NewOption(10),
+				StagnationLimit:   NewOption(1),
+				MaxRestarts:       NewOption(3),
+				MaxEvaluations:    NewOption(18),
+				RestartOptions: NewOption(MakeOptions[int]{
+					NBases:      NewOption(uint(5)),
+					BaseFactory: NewOption(factory),
+				}),
+			}
+			n_iterations, final_population, err := Optimize(params)
+			if err != nil {
+				t.Fatalf("Optimize with exhausted MaxEvaluations failed with error: %v", err)
+			}
+			if n_iterations != 1 || len(final_population) != 10 {
+				t.Errorf("Optimize with exhausted MaxEvaluations failed: expected 1 iteration and 10 members, "+
+					"observed %d and %d", n_iterations, len(final_population))
+			}
+
+			report, err := OptimizeWithReport(params)
+			if err != nil {
+				t.Fatalf("OptimizeWithReport with exhausted MaxEvaluations failed with error: %v", err)
+			}
+			if len(report.Population) != 10 || report.StopReason != StopMaxEvaluations {
+				t.Errorf("OptimizeWithReport with exhausted MaxEvaluations failed: expected 10 members and %v, "+
+					"observed %d and %v", StopMaxEvaluations, len(report.Population), report.StopReason)
+			}
+		})
+	})
 }
 
 func TestTuneOptimize(t *testing.T) {
@@ -1045,6 +1323,17 @@ func BenchmarkOptimize(b *testing.B) {
 			}
 		}
 	})
+	b.Run("GeneIslands", func(b *testing.B) {
+		params.ParallelCount = NewOption(1)
+		params.PopulationSize = NewOption(25)
+		island_params := IslandParams[int]{Optimization: params, Islands: NewOption(4)}
+		for i := 0; i < b.N; i++ {
+			_, _, err := OptimizeIslands(island_params)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 
 	population = []Code[int]{}
 	for i := 0; i < 100; i++ {
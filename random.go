@@ -0,0 +1,140 @@
+package bluegenes
+
+import (
+	"math/bits"
+	"math/rand"
+	"sync"
+)
+
+// A seedable source of randomness that is safe for concurrent use. All methods
+// may be called on a nil *Rand, in which case the global math/rand source is
+// used instead; this is the default behavior wherever a Rand is optional.
+type Rand struct {
+	mu     sync.Mutex
+	source *xoshiroSource
+	rand   *rand.Rand
+}
+
+// Creates a Rand that produces the same sequence for the same seed.
+func NewRand(seed int64) *Rand {
+	source := &xoshiroSource{}
+	source.Seed(seed)
+	return &Rand{source: source, rand: rand.New(source)}
+}
+
+// Creates a Rand that continues from a state returned by Rand.state.
+func newRandFromState(state []uint64) *Rand {
+	source := &xoshiroSource{}
+	copy(source.state[:], state)
+	return &Rand{source: source, rand: rand.New(source)}
+}
+
+// Returns the internal state of the generator, or nil for a nil Rand.
+func (r *Rand) state() []uint64 {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := make([]uint64, len(r.source.state))
+	copy(state, r.source.state[:])
+	return state
+}
+
+// Returns a new Rand seeded from this one. Use Split to give each goroutine
+// an independent stream that is still determined by the original seed.
+func (r *Rand) Split() *Rand {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return NewRand(r.rand.Int63())
+}
+
+func (r *Rand) Intn(n int) int {
+	if r == nil {
+		return rand.Intn(n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Intn(n)
+}
+
+func (r *Rand) Int63() int64 {
+	if r == nil {
+		return rand.Int63()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Int63()
+}
+
+func (r *Rand) Float64() float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64()
+}
+
+func (r *Rand) NormFloat64() float64 {
+	if r == nil {
+		return rand.NormFloat64()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.NormFloat64()
+}
+
+func (r *Rand) Perm(n int) []int {
+	if r == nil {
+		return rand.Perm(n)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Perm(n)
+}
+
+func (r *Rand) Shuffle(n int, swap func(i, j int)) {
+	if r == nil {
+		rand.Shuffle(n, swap)
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rand.Shuffle(n, swap)
+}
+
+// xoshiro256** seeded with splitmix64; see https://prng.di.unimi.it/
+type xoshiroSource struct {
+	state [4]uint64
+}
+
+func (s *xoshiroSource) Seed(seed int64) {
+	x := uint64(seed)
+	for i := range s.state {
+		x += 0x9e3779b97f4a7c15
+		z := x
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		s.state[i] = z ^ (z >> 31)
+	}
+}
+
+func (s *xoshiroSource) Uint64() uint64 {
+	result := bits.RotateLeft64(s.state[1]*5, 7) * 9
+	t := s.state[1] << 17
+	s.state[2] ^= s.state[0]
+	s.state[3] ^= s.state[1]
+	s.state[1] ^= s.state[2]
+	s.state[0] ^= s.state[3]
+	s.state[2] ^= t
+	s.state[3] = bits.RotateLeft64(s.state[3], 45)
+	return result
+}
+
+func (s *xoshiroSource) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
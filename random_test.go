@@ -0,0 +1,141 @@
+package bluegenes
+
+import (
+	"testing"
+)
+
+func TestRand(t *testing.T) {
+	t.Run("NewRand", func(t *testing.T) {
+		t.Parallel()
+		r1, r2, r3 := NewRand(42), NewRand(42), NewRand(43)
+		same, different := true, false
+		for i := 0; i < 100; i++ {
+			v1, v2, v3 := r1.Int63(), r2.Int63(), r3.Int63()
+			same = same && v1 == v2
+			different = different || v1 != v3
+		}
+		if !same {
+			t.Error("NewRand failed: same seed produced different sequences")
+		}
+		if !different {
+			t.Error("NewRand failed: different seeds produced the same sequence")
+		}
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		t.Parallel()
+		s1, s2 := NewRand(42).Split(), NewRand(42).Split()
+		parent := NewRand(42)
+		parent.Split()
+		for i := 0; i < 100; i++ {
+			v1, v2 := s1.Float64(), s2.Float64()
+			if v1 != v2 {
+				t.Fatal("Rand.Split failed: streams from the same seed differ")
+			}
+		}
+		if s1.Int63() == parent.Int63() {
+			t.Error("Rand.Split failed: split stream matches the parent stream")
+		}
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		t.Parallel()
+		var r *Rand
+		if r.Split() != nil {
+			t.Error("Rand.Split failed: expected nil for nil Rand")
+		}
+		for i := 0; i < 100; i++ {
+			if v := r.Intn(10); v < 0 || v >= 10 {
+				t.Fatalf("Rand.Intn failed for nil Rand: observed %d", v)
+			}
+			if v := r.Float64(); v < 0.0 || v >= 1.0 {
+				t.Fatalf("Rand.Float64 failed for nil Rand: observed %f", v)
+			}
+		}
+		if len(r.Perm(5)) != 5 {
+			t.Error("Rand.Perm failed for nil Rand")
+		}
+	})
+
+	t.Run("MakeGene", func(t *testing.T) {
+		t.Parallel()
+		make_gene := func() *Gene[int] {
+			rng := NewRand(7)
+			gene, _ := MakeGene(MakeOptions[int]{
+				NBases:      NewOption(uint(5)),
+				BaseFactory: NewOption(func() int { return rng.Intn(100) }),
+				Rand:        NewOption(rng),
+			})
+			return gene
+		}
+		g1, g2 := make_gene(), make_gene()
+		if g1.Name != g2.Name || !equal(g1.Bases, g2.Bases) {
+			t.Errorf("MakeGene failed to reproduce with Rand: %v vs %v", g1.ToMap(), g2.ToMap())
+		}
+	})
+
+	t.Run("Recombine", func(t *testing.T) {
+		t.Parallel()
+		recombine := func() *Chromosome[int] {
+			c1, _ := rangeChromosome(4, 4, 0, 10, "c1")
+			c2, _ := rangeChromosome(4, 4, 10, 20, "c2")
+			child := &Chromosome[int]{}
+			err := c1.Recombine(c2, []int{}, child, RecombineOptions{Rand: NewOption(NewRand(3))})
+			if err != nil {
+				t.Fatalf("Chromosome.Recombine failed with error: %v", err)
+			}
+			return child
+		}
+		sep := []int{-1}
+		c1, c2 := recombine(), recombine()
+		if c1.Name != c2.Name || !equal(c1.Sequence(sep), c2.Sequence(sep)) {
+			t.Error("Chromosome.Recombine failed to reproduce with Rand")
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		run := func() (int, []*ScoredCode[int]) {
+			rng := NewRand(12345)
+			opts := MakeOptions[int]{
+				NBases:      NewOption(uint(5)),
+				BaseFactory: NewOption(func() int { return randomInt(rng, -10, 10) }),
+				Rand:        NewOption(rng),
+			}
+			initial_population := []Code[int]{}
+			for i := 0; i < 10; i++ {
+				gene, _ := MakeGene(opts)
+				initial_population = append(initial_population, Code[int]{Gene: NewOption(gene)})
+			}
+			mutate := func(code *Code[int]) {
+				for i := range code.Gene.Val.Bases {
+					code.Gene.Val.Bases[i] += randomInt(rng, -11, 11)
+				}
+			}
+			n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+				InitialPopulation: NewOption(initial_population),
+				MeasureFitness:    NewOption(measureCodeFitness),
+				Mutate:            NewOption(mutate),
+				MaxIterations:     NewOption(50),
+				FitnessTarget:     NewOption(2.0),
+				Selector:          NewOption[Selector[int]](TournamentSelector[int]{K: 3}),
+				Rand:              NewOption(rng),
+			})
+			if err != nil {
+				t.Fatalf("Optimize with Rand failed with error: %v", err)
+			}
+			return n_iterations, final_population
+		}
+
+		n1, p1 := run()
+		n2, p2 := run()
+		if n1 != n2 || len(p1) != len(p2) {
+			t.Fatalf("Optimize with Rand failed to reproduce: %d/%d iterations, %d/%d members", n1, n2, len(p1), len(p2))
+		}
+		for i := range p1 {
+			if p1[i].Score != p2[i].Score || p1[i].Code.Hash() != p2[i].Code.Hash() {
+				t.Fatalf("Optimize with Rand failed to reproduce member %d", i)
+			}
+		}
+	})
+}
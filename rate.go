@@ -0,0 +1,203 @@
+package bluegenes
+
+import (
+	"math"
+)
+
+// A RateSchedule sets the mutation rate passed to
+// OptimizationParams.MutateWithRate before each generation.
+type RateSchedule interface {
+	// Returns the rate for the generation (0 for the first), given the rate
+	// used for the previous generation and the fraction of its children that
+	// scored higher than both of their parents.
+	Rate(generation int, previous float64, success_ratio float64) float64
+}
+
+// Returns a function suitable for OptimizationParams.MutateWithRate that
+// applies the Mutator made by mutator for the current rate to every Gene in the
+// Code, e.g. MutatorWithRate(func(rate float64) Mutator[float64] {
+// return GaussianMutation[float64](1.0, rate) }) to adapt the step size.
+func MutatorWithRate[T Ordered](mutator func(rate float64) Mutator[T]) func(*Code[T], float64, *Rand) {
+	return func(code *Code[T], rate float64, rng *Rand) {
+		mutate := mutator(rate)
+		for _, gene := range codeGenes(*code) {
+			mutate(gene, rng)
+		}
+	}
+}
+
+// Returns the rate passed to MutateWithRate in the first generation.
+func initialMutationRate[T Ordered](params OptimizationParams[T]) float64 {
+	switch {
+	case params.MutationRate.Ok():
+		return params.MutationRate.Val.Rate(0, 0.0, 0.0)
+	case params.SelfAdaptation.Ok() && len(params.SelfAdaptation.Val.Initial) > 0:
+		return params.SelfAdaptation.Val.Initial[0]
+	default:
+		return 0.0
+	}
+}
+
+// Returns generation/generations limited to [0, 1].
+func scheduleProgress(generation, generations int) float64 {
+	if generations < 1 {
+		return 1.0
+	}
+	progress, _ := min(float64(generation)/float64(generations), 1.0)
+	return progress
+}
+
+// Changes the rate linearly from Start to End over Generations, then keeps it
+// at End.
+type LinearRate struct {
+	Start       float64
+	End         float64
+	Generations int
+}
+
+func (s LinearRate) Rate(generation int, previous float64, success_ratio float64) float64 {
+	return s.Start + (s.End-s.Start)*scheduleProgress(generation, s.Generations)
+}
+
+// Changes the rate exponentially from Start to End over Generations, then
+// keeps it at End. Start and End must be greater than 0.
+type ExponentialRate struct {
+	Start       float64
+	End         float64
+	Generations int
+}
+
+func (s ExponentialRate) Rate(generation int, previous float64, success_ratio float64) float64 {
+	return s.Start * math.Pow(s.End/s.Start, scheduleProgress(generation, s.Generations))
+}
+
+// Rechenberg's 1/5th success rule: starting from Initial, multiplies the rate
+// by Factor (default 1.22) after a generation in which more than a fifth of the
+// children scored higher than both of their parents and divides it by Factor
+// after a generation in which fewer did. The rate is kept within Bounds unless
+// Bounds.Upper is 0.
+type OneFifthRule struct {
+	Initial float64
+	Factor  float64
+	Bounds  Bounds[float64]
+}
+
+func (s OneFifthRule) Rate(generation int, previous float64, success_ratio float64) float64 {
+	if generation == 0 {
+		return s.Initial
+	}
+	factor := s.Factor
+	if factor <= 0.0 {
+		factor = 1.22
+	}
+	rate := previous
+	if success_ratio > 0.2 {
+		rate *= factor
+	} else if success_ratio < 0.2 {
+		rate /= factor
+	}
+	if s.Bounds.Upper > 0.0 {
+		rate = s.Bounds.Clamp(rate)
+	}
+	return rate
+}
+
+// Evolution strategy self-adaptation: every Code carries its own strategy
+// parameters (e.g. mutation rates or step sizes) in Code.Strategy, starting
+// from Initial. Recombination averages the parents' parameters, and then each
+// child's parameters are multiplied by exp(GlobalLearningRate*N(0,1) +
+// LearningRate*N_i(0,1)) before Mutate is called. The learning rates default
+// to 1/sqrt(2*sqrt(n)) and 1/sqrt(2n) for n parameters. The parameters are
+// kept within Bounds unless Bounds.Upper is 0, and are always kept above 0.
+type SelfAdaptation struct {
+	Initial            []float64
+	LearningRate       float64
+	GlobalLearningRate float64
+	Bounds             Bounds[float64]
+}
+
+// Returns the mutated copy of the strategy parameters, or of Initial if
+// strategy is empty.
+func (s SelfAdaptation) mutate(strategy []float64, rng *Rand) []float64 {
+	if len(strategy) == 0 {
+		strategy = s.Initial
+	}
+	n := float64(len(strategy))
+	tau, tau_prime := s.LearningRate, s.GlobalLearningRate
+	if tau <= 0.0 {
+		tau = 1.0 / math.Sqrt(2.0*math.Sqrt(n))
+	}
+	if tau_prime <= 0.0 {
+		tau_prime = 1.0 / math.Sqrt(2.0*n)
+	}
+
+	global := tau_prime * rng.NormFloat64()
+	mutated := make([]float64, len(strategy))
+	for i, parameter := range strategy {
+		mutated[i] = parameter * math.Exp(global+tau*rng.NormFloat64())
+		if s.Bounds.Upper > 0.0 {
+			mutated[i] = s.Bounds.Clamp(mutated[i])
+		}
+		if mutated[i] <= 0.0 {
+			mutated[i] = math.SmallestNonzeroFloat64
+		}
+	}
+	return mutated
+}
+
+// Returns the average of two sets of strategy parameters. Parameters past the
+// end of the shorter set are copied from the longer one.
+func intermediateStrategy(a, b []float64) []float64 {
+	if len(b) > len(a) {
+		a, b = b, a
+	}
+	strategy := make([]float64, len(a))
+	copy(strategy, a)
+	for i := range b {
+		strategy[i] = (a[i] + b[i]) / 2.0
+	}
+	return strategy
+}
+
+// Returns the fraction of the children that scored better than both of their
+// parents, where mates[2*i] and mates[2*i+1] are the parents of children[i].
+func successRatio[T Ordered](parents []*ScoredCode[T], mates []Code[T],
+	children []*ScoredCode[T], completed []bool, direction Direction) float64 {
+	scores := make(map[string]float64, len(parents))
+	for _, parent := range parents {
+		scores[parent.Code.key()] = parent.Score
+	}
+	successes, total := 0, 0
+	for i, child := range children {
+		if !completed[i] {
+			continue
+		}
+		total++
+		best := scores[mates[2*i].key()]
+		if other := scores[mates[2*i+1].key()]; direction.better(other, best) {
+			best = other
+		}
+		if direction.better(child.Score, best) {
+			successes++
+		}
+	}
+	if total == 0 {
+		return 0.0
+	}
+	return float64(successes) / float64(total)
+}
+
+// Returns the mean of the first strategy parameter of every Code that has one.
+func meanStrategy[T Ordered](scores []*ScoredCode[T]) float64 {
+	total, count := 0.0, 0
+	for _, score := range scores {
+		if score.Code.Strategy.Ok() && len(score.Code.Strategy.Val) > 0 {
+			total += score.Code.Strategy.Val[0]
+			count++
+		}
+	}
+	if count == 0 {
+		return 0.0
+	}
+	return total / float64(count)
+}
@@ -0,0 +1,260 @@
+package bluegenes
+
+import (
+	"math"
+	"testing"
+)
+
+func sphereInitialPopulation(rng *Rand, size int) []Code[float64] {
+	initial_population := []Code[float64]{}
+	for i := 0; i < size; i++ {
+		gene, _ := MakeGene(MakeOptions[float64]{
+			NBases:      NewOption(uint(4)),
+			BaseFactory: NewOption(func() float64 { return -5.0 + 10.0*rng.Float64() }),
+			Rand:        NewOption(rng),
+		})
+		initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+	}
+	return initial_population
+}
+
+func measureSphereFitness(code Code[float64]) float64 {
+	total := 0.0
+	for _, base := range code.Gene.Val.Bases {
+		total += (base - 1.0) * (base - 1.0)
+	}
+	return 1.0 / (1.0 + total)
+}
+
+func TestMutationRate(t *testing.T) {
+	t.Run("LinearRate/ExponentialRate", func(t *testing.T) {
+		t.Parallel()
+		linear := LinearRate{Start: 0.5, End: 0.1, Generations: 4}
+		exponential := ExponentialRate{Start: 0.4, End: 0.1, Generations: 2}
+		expected := map[int][2]float64{0: {0.5, 0.4}, 1: {0.4, 0.2}, 2: {0.3, 0.1}, 4: {0.1, 0.1}, 10: {0.1, 0.1}}
+		for generation, rates := range expected {
+			if rate := linear.Rate(generation, 0.0, 0.0); math.Abs(rate-rates[0]) > 1e-9 {
+				t.Errorf("LinearRate failed: expected %f at %d, observed %f", rates[0], generation, rate)
+			}
+			if rate := exponential.Rate(generation, 0.0, 0.0); math.Abs(rate-rates[1]) > 1e-9 {
+				t.Errorf("ExponentialRate failed: expected %f at %d, observed %f", rates[1], generation, rate)
+			}
+		}
+	})
+
+	t.Run("OneFifthRule", func(t *testing.T) {
+		t.Parallel()
+		rule := OneFifthRule{Initial: 0.1, Factor: 2.0, Bounds: Bounds[float64]{0.05, 0.3}}
+		observed := []float64{
+			rule.Rate(0, 0.5, 1.0),
+			rule.Rate(1, 0.1, 0.5),
+			rule.Rate(1, 0.1, 0.1),
+			rule.Rate(1, 0.1, 0.2),
+			rule.Rate(1, 0.2, 0.5),
+			rule.Rate(1, 0.06, 0.0),
+		}
+		if !equal(observed, []float64{0.1, 0.2, 0.05, 0.1, 0.3, 0.05}) {
+			t.Errorf("OneFifthRule failed: expected [0.1 0.2 0.05 0.1 0.3 0.05], observed %v", observed)
+		}
+		if rate := (OneFifthRule{}).Rate(1, 1.0, 1.0); rate != 1.22 {
+			t.Errorf("OneFifthRule failed to use the default Factor: observed %f", rate)
+		}
+	})
+
+	t.Run("SelfAdaptation", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(41)
+		adaptation := SelfAdaptation{Initial: []float64{0.5, 1.0}, Bounds: Bounds[float64]{0.1, 2.0}}
+		strategy := adaptation.mutate(nil, rng)
+		if len(strategy) != 2 || (strategy[0] == 0.5 && strategy[1] == 1.0) {
+			t.Fatalf("SelfAdaptation failed to mutate Initial: observed %v", strategy)
+		}
+		for i := 0; i < 100; i++ {
+			strategy = adaptation.mutate(strategy, rng)
+			if strategy[0] < 0.1 || strategy[0] > 2.0 || strategy[1] < 0.1 || strategy[1] > 2.0 {
+				t.Fatalf("SelfAdaptation failed to respect Bounds: observed %v", strategy)
+			}
+		}
+		if adaptation.Initial[0] != 0.5 {
+			t.Errorf("SelfAdaptation changed Initial: observed %v", adaptation.Initial)
+		}
+	})
+
+	t.Run("Code.Strategy", func(t *testing.T) {
+		t.Parallel()
+		dad := Code[int]{Gene: NewOption(firstGene()), Strategy: NewOption([]float64{1.0, 2.0})}
+		mom := Code[int]{Gene: NewOption(firstGene()), Strategy: NewOption([]float64{3.0})}
+		child := Code[int]{}
+		dad.Recombine(mom, &child, RecombineOptions{})
+		if !equal(child.Strategy.Val, []float64{2.0, 2.0}) {
+			t.Errorf("Code.Recombine failed to average Strategy: expected [2 2], observed %v", child.Strategy.Val)
+		}
+		copied := dad.DeepCopy()
+		copied.Strategy.Val[0] = 5.0
+		if dad.Strategy.Val[0] != 1.0 || dad.Hash() != copied.Hash() {
+			t.Errorf("Code.DeepCopy failed to copy Strategy: observed %v", dad.Strategy.Val)
+		}
+		restored := checkpointCode(dad).restore()
+		if !equal(restored.Strategy.Val, dad.Strategy.Val) {
+			t.Errorf("checkpoint failed to restore Strategy: observed %v", restored.Strategy.Val)
+		}
+		Code[int]{}.Recombine(Code[int]{}, &child, RecombineOptions{})
+		if child.Strategy.Ok() {
+			t.Errorf("Code.Recombine failed to clear Strategy: observed %v", child.Strategy.Val)
+		}
+	})
+
+	t.Run("successRatio", func(t *testing.T) {
+		t.Parallel()
+		// the parents share Bases (and thus Hash) but not Name
+		low := &ScoredCode[int]{Code: Code[int]{Gene: NewOption(&Gene[int]{Name: "low", Bases: []int{1}})}, Score: 0.1}
+		high := &ScoredCode[int]{Code: Code[int]{Gene: NewOption(&Gene[int]{Name: "high", Bases: []int{1}})}, Score: 0.9}
+		children := []*ScoredCode[int]{{Score: 0.5}, {Score: 0.5}}
+		mates := []Code[int]{low.Code, low.Code, high.Code, low.Code}
+		ratio := successRatio([]*ScoredCode[int]{low, high}, mates, children, []bool{true, true}, Maximize)
+		if ratio != 0.5 {
+			t.Errorf("successRatio failed: expected 0.5, observed %f", ratio)
+		}
+	})
+
+	t.Run("MutatorWithRate", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(2, 2, 2, 0, 5)
+		code := Code[int]{Gene: NewOption(permutationGene(5)), Genome: NewOption(genome)}
+		rates := []float64{}
+		mutate := MutatorWithRate(func(rate float64) Mutator[int] {
+			rates = append(rates, rate)
+			return UniformResetMutation(rate, func() int { return -1 })
+		})
+		mutate(&code, 1.0, NewRand(1))
+		for _, gene := range codeGenes(code) {
+			for _, base := range gene.Bases {
+				if base != -1 {
+					t.Fatalf("MutatorWithRate failed to mutate every Gene: observed %v", gene.Bases)
+				}
+			}
+		}
+		if !equal(rates, []float64{1.0}) {
+			t.Errorf("MutatorWithRate failed to pass the rate: expected [1], observed %v", rates)
+		}
+	})
+
+	t.Run("TuneOptimization", func(t *testing.T) {
+		t.Parallel()
+		rates := []float64{}
+		n_goroutines, err := TuneOptimization(OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			MutateWithRate: NewOption(func(code *Code[int], rate float64, rng *Rand) {
+				if len(rates) == 0 {
+					rates = append(rates, rate)
+				}
+			}),
+			MutationRate: NewOption[RateSchedule](LinearRate{Start: 0.3, End: 0.1, Generations: 10}),
+		})
+		if err != nil || n_goroutines < 1 {
+			t.Fatalf("TuneOptimization with MutateWithRate failed: observed %d (%v)", n_goroutines, err)
+		}
+		if !equal(rates, []float64{0.3}) {
+			t.Errorf("TuneOptimization failed to benchmark the initial rate: expected [0.3], observed %v", rates)
+		}
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		t.Parallel()
+		mutate := func(code *Code[int], rate float64, rng *Rand) {}
+		cases := map[string]OptimizationParams[int]{
+			"MutationRate without MutateWithRate": {
+				Mutate: NewOption(MutateCode), MutationRate: NewOption[RateSchedule](LinearRate{}),
+			},
+			"MutateWithRate without MutationRate": {MutateWithRate: NewOption(mutate)},
+			"MutationRate with SelfAdaptation": {
+				MutateWithRate: NewOption(mutate),
+				MutationRate:   NewOption[RateSchedule](LinearRate{}),
+				SelfAdaptation: NewOption(SelfAdaptation{Initial: []float64{1.0}}),
+			},
+			"SelfAdaptation without Initial": {
+				MutateWithRate: NewOption(mutate), SelfAdaptation: NewOption(SelfAdaptation{}),
+			},
+		}
+		for name, params := range cases {
+			params.InitialPopulation = NewOption(geneInitialPopulation(10))
+			params.MeasureFitness = NewOption(measureCodeFitness)
+			if _, _, err := Optimize(params); err == nil {
+				t.Errorf("Optimize failed to reject %s", name)
+			}
+		}
+	})
+
+	schedules := map[string]RateSchedule{
+		"LinearRate":   LinearRate{Start: 0.5, End: 0.05, Generations: 50},
+		"OneFifthRule": OneFifthRule{Initial: 0.5, Bounds: Bounds[float64]{0.01, 1.0}},
+	}
+	for name, schedule := range schedules {
+		name, schedule := name, schedule
+		t.Run("Optimize/"+name, func(t *testing.T) {
+			t.Parallel()
+			rng := NewRand(43)
+			rates := []float64{}
+			report, err := OptimizeWithReport(OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+				MeasureFitness:    NewOption(measureSphereFitness),
+				MutateWithRate: NewOption(func(code *Code[float64], rate float64, rng *Rand) {
+					rates = append(rates, rate)
+					GaussianMutation[float64](1.0, rate)(code.Gene.Val, rng)
+				}),
+				MutationRate:  NewOption(schedule),
+				MaxIterations: NewOption(100),
+				FitnessTarget: NewOption(0.999),
+				Rand:          NewOption(rng),
+			})
+			if err != nil {
+				t.Fatalf("Optimize with %s failed with error: %v", name, err)
+			}
+			if len(rates) == 0 || rates[0] != 0.5 {
+				t.Fatalf("Optimize with %s failed to pass the rate to MutateWithRate: observed %v", name, rates)
+			}
+			for _, stats := range report.History[1:] {
+				expected := schedule.Rate(stats.Generation-1, 0.0, 0.0)
+				if _, ok := schedule.(LinearRate); ok && math.Abs(stats.MutationRate-expected) > 1e-9 {
+					t.Fatalf("Optimize with %s failed: expected rate %f, observed %f", name, expected, stats.MutationRate)
+				}
+			}
+			if last := report.History[len(report.History)-1]; last.MutationRate >= 0.5 {
+				t.Errorf("Optimize with %s failed to reduce the rate: observed %f", name, last.MutationRate)
+			}
+			if report.Population[0].Score < 0.9 {
+				t.Errorf("Optimize with %s failed to improve: %f", name, report.Population[0].Score)
+			}
+		})
+	}
+
+	t.Run("Optimize/SelfAdaptation", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(47)
+		report, err := OptimizeWithReport(OptimizationParams[float64]{
+			InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+			MeasureFitness:    NewOption(measureSphereFitness),
+			MutateWithRate: NewOption(MutatorWithRate(func(sigma float64) Mutator[float64] {
+				return GaussianMutation[float64](1.0, sigma)
+			})),
+			SelfAdaptation: NewOption(SelfAdaptation{Initial: []float64{1.0}, Bounds: Bounds[float64]{1e-6, 5.0}}),
+			MaxIterations:  NewOption(200),
+			FitnessTarget:  NewOption(0.9999),
+			Rand:           NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with SelfAdaptation failed with error: %v", err)
+		}
+		if !report.Population[0].Code.Strategy.Ok() {
+			t.Fatal("Optimize with SelfAdaptation failed to set Code.Strategy")
+		}
+		last := report.History[len(report.History)-1]
+		if last.MutationRate <= 0.0 || last.MutationRate >= 1.0 {
+			t.Errorf("Optimize with SelfAdaptation failed to adapt the step size: observed %f", last.MutationRate)
+		}
+		if report.Population[0].Score < 0.99 {
+			t.Errorf("Optimize with SelfAdaptation failed to improve: %f", report.Population[0].Score)
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package bluegenes
+
+import (
+	"context"
+	"sort"
+)
+
+// How the next population is chosen from the current one and its children.
+type ReplacementScheme int
+
+const (
+	GenerationalReplacement ReplacementScheme = iota
+	PlusReplacement
+	CommaReplacement
+	ReplaceWorstSteadyState
+	ReplaceOldestSteadyState
+	AgeLayeredReplacement
+)
+
+func (s ReplacementScheme) String() string {
+	switch s {
+	case GenerationalReplacement:
+		return "generational"
+	case PlusReplacement:
+		return "mu+lambda"
+	case CommaReplacement:
+		return "mu,lambda"
+	case ReplaceWorstSteadyState:
+		return "replace worst"
+	case ReplaceOldestSteadyState:
+		return "replace oldest"
+	case AgeLayeredReplacement:
+		return "age-layered"
+	default:
+		return "unknown"
+	}
+}
+
+// Returns the number of members that survive unchanged with
+// GenerationalReplacement.
+func (r *optimizationRun[T]) survivorCount() int {
+	survivors := r.params.ParentsPerGeneration.Val
+	if r.params.Survivors.Ok() {
+		survivors = r.params.Survivors.Val
+	}
+	survivors, _ = min(survivors, len(r.scores))
+	return survivors
+}
+
+// Returns the number of children to breed in the next generation.
+func (r *optimizationRun[T]) offspringCount() int {
+	n_children := r.params.PopulationSize.Val - r.survivorCount()
+	switch r.params.Replacement.Val {
+	case PlusReplacement, CommaReplacement, AgeLayeredReplacement:
+		n_children = r.params.PopulationSize.Val
+	case ReplaceWorstSteadyState, ReplaceOldestSteadyState:
+		n_children = 1
+	}
+	if r.nicheReplacement() {
+		n_children = r.params.PopulationSize.Val
+	}
+	if r.params.Speciation.Ok() {
+		n_children, _ = max(r.params.PopulationSize.Val-len(r.speciesElites()), 0)
+	}
+	if r.params.Offspring.Ok() && (r.params.Replacement.Val != GenerationalReplacement || r.nicheReplacement()) {
+		n_children = r.params.Offspring.Val
+	}
+	n_children, _ = min(n_children, r.evaluationsRemaining())
+	return n_children
+}
+
+// Returns the parents of the next generation and the mates chosen from them
+// for n_children children. With AgeLayeredReplacement, the children of each
+// layer are bred from the members of that layer and the one below it, and
+// layers with fewer than 2 such members have no children. With
+// DeterministicCrowding or RestrictedTournament, every member is a parent, and
+// with Speciation, the mates are chosen within each species.
+func (r *optimizationRun[T]) selectParents(n_children int) ([]*ScoredCode[T], []Code[T]) {
+	selector, direction := r.params.Selector.Val, r.params.Direction.Val
+	if r.params.Speciation.Ok() {
+		return r.scores, r.speciesMates(n_children)
+	}
+	if r.params.Replacement.Val != AgeLayeredReplacement {
+		n_parents, _ := min(r.params.ParentsPerGeneration.Val, len(r.scores))
+		if r.nicheReplacement() {
+			n_parents = len(r.scores)
+		}
+		parents := r.scores[:n_parents]
+		return parents, selectMates(selector, parents, n_children, direction, r.rng)
+	}
+
+	mates := []Code[T]{}
+	for layer := 0; layer < r.params.AgeLayers.Val; layer++ {
+		pool := []*ScoredCode[T]{}
+		for _, score := range r.scores {
+			if l := r.ageLayer(score.Age); l == layer || l == layer-1 {
+				pool = append(pool, score)
+			}
+		}
+		if len(pool) < 2 {
+			continue
+		}
+		n_parents, _ := min(r.params.ParentsPerGeneration.Val, len(pool))
+		mates = append(mates, selectMates(selector, pool[:n_parents], r.layerSize(), direction, r.rng)...)
+	}
+	if len(mates) > 2*n_children {
+		mates = mates[:2*n_children]
+	}
+	return r.scores, mates
+}
+
+// Sets the Age of each child: 0, or the Age of its oldest parent with
+// AgeLayeredReplacement.
+func (r *optimizationRun[T]) setChildAges(mates []Code[T], children []*ScoredCode[T]) {
+	ages := map[string]int{}
+	if r.params.Replacement.Val == AgeLayeredReplacement {
+		for _, score := range r.scores {
+			key := score.Code.key()
+			ages[key], _ = max(ages[key], score.Age)
+		}
+	}
+	for i, child := range children {
+		child.Age = 0
+		if len(ages) > 0 {
+			child.Age, _ = max(ages[mates[2*i].key()], ages[mates[2*i+1].key()])
+		}
+	}
+}
+
+// Removes the members at and after index from the population, returning them
+// to the pool.
+func (r *optimizationRun[T]) truncate(index int) {
+	if index >= len(r.scores) {
+		return
+	}
+	for _, score := range r.scores[index:] {
+		r.putScoredCode(score)
+	}
+	r.scores = r.scores[:index]
+}
+
+// Replaces members of the ranked population with the children according to
+// params.Replacement, or params.Niching or params.Speciation if either decides
+// replacement. The mates are the consecutive pairs of parents of the children.
+func (r *optimizationRun[T]) replace(mates []Code[T], children []*ScoredCode[T]) {
+	if r.params.Niching.Ok() && r.params.Niching.Val.Method == DeterministicCrowding {
+		r.crowd(mates, children)
+		return
+	}
+	if r.params.Niching.Ok() && r.params.Niching.Val.Method == RestrictedTournament {
+		r.restrictedTournament(children)
+		return
+	}
+	if r.params.Speciation.Ok() {
+		elites := r.speciesElites()
+		for _, score := range r.scores {
+			if !contains(elites, score) {
+				r.putScoredCode(score)
+			}
+		}
+		r.scores = append(elites, children...)
+		return
+	}
+	population_size := r.params.PopulationSize.Val
+	switch r.params.Replacement.Val {
+	case PlusReplacement:
+		r.scores = append(r.scores, children...)
+		r.rank()
+		r.truncate(population_size)
+	case CommaReplacement:
+		if len(children) > 0 {
+			r.truncate(0)
+		}
+		r.scores = append(r.scores, children...)
+		r.rank()
+		r.truncate(population_size)
+	case ReplaceWorstSteadyState, ReplaceOldestSteadyState:
+		// the members to be replaced are moved to the end
+		if r.params.Replacement.Val == ReplaceOldestSteadyState {
+			sort.SliceStable(r.scores, func(i, j int) bool {
+				return r.scores[i].Age < r.scores[j].Age
+			})
+		}
+		if excess := len(r.scores) + len(children) - population_size; excess > 0 {
+			excess, _ = min(excess, len(r.scores))
+			r.truncate(len(r.scores) - excess)
+		}
+		r.scores = append(r.scores, children...)
+	case AgeLayeredReplacement:
+		r.scores = append(r.scores, children...)
+		r.rank()
+		counts := make([]int, r.params.AgeLayers.Val)
+		kept := r.scores[:0]
+		for _, score := range r.scores {
+			layer := r.ageLayer(score.Age)
+			if counts[layer] < r.layerSize() {
+				counts[layer]++
+				kept = append(kept, score)
+			} else {
+				r.putScoredCode(score)
+			}
+		}
+		r.scores = kept
+	default:
+		r.truncate(r.survivorCount())
+		r.scores = append(r.scores, children...)
+	}
+}
+
+// Returns the number of members in each layer with AgeLayeredReplacement.
+func (r *optimizationRun[T]) layerSize() int {
+	size, _ := max(r.params.PopulationSize.Val/r.params.AgeLayers.Val, 1)
+	return size
+}
+
+// Returns the layer for a member of the given Age: layer i holds members no
+// older than AgeGap*2^i, and the last layer holds all older members.
+func (r *optimizationRun[T]) ageLayer(age int) int {
+	limit := r.params.AgeGap.Val
+	for layer := 0; layer < r.params.AgeLayers.Val-1; layer++ {
+		if age <= limit {
+			return layer
+		}
+		limit *= 2
+	}
+	return r.params.AgeLayers.Val - 1
+}
+
+// With AgeLayeredReplacement, replaces the youngest layer with fresh random
+// Code every AgeGap generations.
+func (r *optimizationRun[T]) reseedYoungestLayer(ctx context.Context) error {
+	if r.params.Replacement.Val != AgeLayeredReplacement || r.generationCount == 0 ||
+		r.generationCount%r.params.AgeGap.Val != 0 {
+		return nil
+	}
+	kept := r.scores[:0]
+	for _, score := range r.scores {
+		if r.ageLayer(score.Age) == 0 {
+			r.putScoredCode(score)
+		} else {
+			kept = append(kept, score)
+		}
+	}
+	r.scores = kept
+	codes, err := r.randomCodes(r.layerSize())
+	if err != nil {
+		return err
+	}
+	r.populate(ctx, codes)
+	return nil
+}
@@ -0,0 +1,199 @@
+package bluegenes
+
+import (
+	"testing"
+)
+
+func replacementRun(scheme ReplacementScheme, population_size int, scores []*ScoredCode[int]) *optimizationRun[int] {
+	return &optimizationRun[int]{
+		params: OptimizationParams[int]{
+			Replacement:          NewOption(scheme),
+			PopulationSize:       NewOption(population_size),
+			ParentsPerGeneration: NewOption(2),
+			AgeLayers:            NewOption(3),
+			AgeGap:               NewOption(2),
+			Selector:             NewOption[Selector[int]](weightedSelector[int]{}),
+		},
+		scores: scores,
+		rng:    NewRand(3),
+	}
+}
+
+func TestReplacement(t *testing.T) {
+	t.Run("offspringCount", func(t *testing.T) {
+		t.Parallel()
+		expected := map[ReplacementScheme]int{
+			GenerationalReplacement:  8,
+			PlusReplacement:          10,
+			CommaReplacement:         10,
+			ReplaceWorstSteadyState:  1,
+			ReplaceOldestSteadyState: 1,
+			AgeLayeredReplacement:    10,
+		}
+		for scheme, count := range expected {
+			r := replacementRun(scheme, 10, scoredCodes(make([][2]float64, 10)...))
+			if observed := r.offspringCount(); observed != count {
+				t.Errorf("offspringCount failed for %s: expected %d, observed %d", scheme, count, observed)
+			}
+		}
+
+		r := replacementRun(GenerationalReplacement, 10, scoredCodes(make([][2]float64, 10)...))
+		r.params.Survivors = NewOption(0)
+		if r.survivorCount() != 0 || r.offspringCount() != 10 {
+			t.Errorf("Survivors failed: expected 0 and 10, observed %d and %d", r.survivorCount(), r.offspringCount())
+		}
+		r.params.Replacement = NewOption(PlusReplacement)
+		r.params.Offspring = NewOption(30)
+		r.params.MaxEvaluations = NewOption(20)
+		if observed := r.offspringCount(); observed != 20 {
+			t.Errorf("offspringCount failed to respect MaxEvaluations: expected 20, observed %d", observed)
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+		parents := [][2]float64{{0.9, 0.0}, {0.5, 0.0}, {0.1, 0.0}}
+		children := [][2]float64{{0.3, 0.0}, {0.7, 0.0}, {0.2, 0.0}}
+		expected := map[ReplacementScheme][]float64{
+			GenerationalReplacement: {0.9, 0.7, 0.5, 0.3, 0.2},
+			PlusReplacement:         {0.9, 0.7, 0.5},
+			CommaReplacement:        {0.7, 0.3, 0.2},
+			ReplaceWorstSteadyState: {0.9, 0.5, 0.3},
+		}
+		for scheme, scores := range expected {
+			r := replacementRun(scheme, 3, scoredCodes(parents...))
+			offspring := scoredCodes(children...)
+			if scheme == ReplaceWorstSteadyState {
+				offspring = offspring[:1]
+			}
+			r.replace(nil, offspring)
+			r.rank()
+			observed := []float64{}
+			for _, score := range r.scores {
+				observed = append(observed, score.Score)
+			}
+			if !equal(observed, scores) {
+				t.Errorf("replace failed for %s: expected %v, observed %v", scheme, scores, observed)
+			}
+		}
+
+		r := replacementRun(ReplaceOldestSteadyState, 3, scoredCodes(parents...))
+		for i, age := range []int{1, 7, 3} {
+			r.scores[i].Age = age
+		}
+		r.replace(nil, scoredCodes(children[:1]...))
+		if bases := rankedBases(r.scores); len(bases) != 3 || contains(bases[:2], 1) {
+			t.Errorf("replace failed to remove the oldest member: observed %v", bases)
+		}
+	})
+
+	t.Run("age layers", func(t *testing.T) {
+		t.Parallel()
+		r := replacementRun(AgeLayeredReplacement, 7, nil)
+		if r.layerSize() != 2 {
+			t.Errorf("layerSize failed: expected 2, observed %d", r.layerSize())
+		}
+		layers := []int{}
+		for _, age := range []int{0, 2, 3, 4, 5, 100} {
+			layers = append(layers, r.ageLayer(age))
+		}
+		if !equal(layers, []int{0, 0, 1, 1, 2, 2}) {
+			t.Errorf("ageLayer failed: expected [0 0 1 1 2 2], observed %v", layers)
+		}
+
+		r.scores = scoredCodes([2]float64{0.9, 0.0}, [2]float64{0.8, 0.0}, [2]float64{0.7, 0.0})
+		for i, age := range []int{5, 1, 0} {
+			r.scores[i].Age = age
+		}
+		_, mates := r.selectParents(3)
+		children := scoredCodes(make([][2]float64, len(mates)/2)...)
+		r.setChildAges(mates, children)
+		for i, child := range children {
+			if child.Age != 0 && child.Age != 1 && child.Age != 5 {
+				t.Errorf("setChildAges failed for child %d: observed %d", i, child.Age)
+			}
+		}
+		r.scores = append(r.scores, scoredCodes([2]float64{0.6, 0.0}, [2]float64{0.5, 0.0}, [2]float64{0.4, 0.0})...)
+		for _, score := range r.scores[3:] {
+			score.Age = 1
+		}
+		r.replace(nil, nil)
+		if bases := rankedBases(r.scores); !equal(bases, []int{0, 1, 2}) {
+			t.Errorf("replace failed to limit the youngest layer: expected [0 1 2], observed %v", bases)
+		}
+	})
+
+	schemes := []ReplacementScheme{
+		GenerationalReplacement, PlusReplacement, CommaReplacement,
+		ReplaceWorstSteadyState, ReplaceOldestSteadyState, AgeLayeredReplacement,
+	}
+	for _, scheme := range schemes {
+		scheme := scheme
+		t.Run("Optimize/"+scheme.String(), func(t *testing.T) {
+			t.Parallel()
+			rng := NewRand(71)
+			max_iterations := 200
+			if scheme == ReplaceWorstSteadyState || scheme == ReplaceOldestSteadyState {
+				max_iterations = 4000
+			}
+			params := OptimizationParams[float64]{
+				InitialPopulation: NewOption(sphereInitialPopulation(rng, 20)),
+				MeasureFitness:    NewOption(measureSphereFitness),
+				Mutate: NewOption(func(code *Code[float64]) {
+					GaussianMutation[float64](0.5, 0.3)(code.Gene.Val, rng)
+				}),
+				PopulationSize:       NewOption(20),
+				ParentsPerGeneration: NewOption(5),
+				Survivors:            NewOption(1),
+				Replacement:          NewOption(scheme),
+				RestartOptions: NewOption(MakeOptions[float64]{
+					NBases:      NewOption(uint(4)),
+					BaseFactory: NewOption(func() float64 { return -5.0 + 10.0*rng.Float64() }),
+				}),
+				AgeLayers:     NewOption(4),
+				AgeGap:        NewOption(5),
+				FitnessTarget: NewOption(0.98),
+				MaxIterations: NewOption(max_iterations),
+				Rand:          NewOption(rng),
+			}
+			if scheme == CommaReplacement {
+				params.Offspring = NewOption(40)
+			}
+			generations, final_population, err := Optimize(params)
+			if err != nil {
+				t.Fatalf("Optimize with %s failed with error: %v", scheme, err)
+			}
+			if len(final_population) > 20 {
+				t.Errorf("Optimize with %s failed to keep the PopulationSize: observed %d", scheme, len(final_population))
+
+			}
+			if final_population[0].Score < 0.98 {
+				t.Errorf("Optimize with %s failed to reach the target after %d generations: observed %f",
+					scheme, generations, final_population[0].Score)
+			}
+		})
+	}
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		invalid := map[string]OptimizationParams[int]{
+			"Survivors":    {Survivors: NewOption(100)},
+			"Offspring":    {Offspring: NewOption(0)},
+			"Comma":        {Replacement: NewOption(CommaReplacement), Offspring: NewOption(50)},
+			"ALPS options": {Replacement: NewOption(AgeLayeredReplacement)},
+			"AgeLayers": {
+				Replacement:    NewOption(AgeLayeredReplacement),
+				RestartOptions: NewOption(MakeOptions[int]{}),
+				AgeLayers:      NewOption(101),
+			},
+		}
+		for name, params := range invalid {
+			params.InitialPopulation = NewOption(geneInitialPopulation(10))
+			params.MeasureFitness = NewOption(measureCodeFitness)
+			params.Mutate = NewOption(MutateCode)
+			if _, err := prepareOptimizationParams(params); err == nil {
+				t.Errorf("prepareOptimizationParams failed to reject invalid %s", name)
+			}
+		}
+	})
+}
@@ -0,0 +1,391 @@
+package bluegenes
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// How Schema.Repair moves a base that is outside its Bounds back within them.
+type RepairMethod int
+
+const (
+	ClampRepair RepairMethod = iota
+	ReflectRepair
+)
+
+// The legal values and length of a Gene. Bounds[i] limits the base at
+// position i, and the last of them limits every base after it. If Allowed is
+// not empty, it lists the only legal values. Length limits the number of
+// bases, where an Upper less than 1 means there is no maximum; set Lower and
+// Upper to the same value for a fixed length.
+type GeneSchema[T Ordered] struct {
+	Bounds  []Bounds[T]
+	Allowed []T
+	Length  Option[Bounds[int]]
+}
+
+// The legal values and lengths of every level of genetic code. Gene applies
+// to every Gene unless Genes has a GeneSchema with the same name.
+// NucleosomeLength, ChromosomeLength, and GenomeLength limit the number of
+// Genes in a Nucleosome, Nucleosomes in a Chromosome, and Chromosomes in a
+// Genome in the same way that GeneSchema.Length limits the number of bases.
+type Schema[T Ordered] struct {
+	Gene             GeneSchema[T]
+	Genes            map[string]GeneSchema[T]
+	NucleosomeLength Option[Bounds[int]]
+	ChromosomeLength Option[Bounds[int]]
+	GenomeLength     Option[Bounds[int]]
+}
+
+// Returns the GeneSchema for the Gene with the given name.
+func (s Schema[T]) gene(name string) GeneSchema[T] {
+	if schema, ok := s.Genes[name]; ok {
+		return schema
+	}
+	return s.Gene
+}
+
+// Returns the Bounds for the base at index i.
+func (s GeneSchema[T]) bounds(i int) (Bounds[T], bool) {
+	if len(s.Bounds) == 0 {
+		return Bounds[T]{}, false
+	}
+	if i >= len(s.Bounds) {
+		i = len(s.Bounds) - 1
+	}
+	return s.Bounds[i], true
+}
+
+// Returns the size limited to the length Bounds, where an Upper less than 1
+// means there is no maximum.
+func schemaLength(size int, length Option[Bounds[int]]) int {
+	if !length.Ok() {
+		return size
+	}
+	if size < length.Val.Lower {
+		return length.Val.Lower
+	}
+	if length.Val.Upper > 0 && size > length.Val.Upper {
+		return length.Val.Upper
+	}
+	return size
+}
+
+// Returns an error if the size is outside the length Bounds.
+func validateLength(size int, length Option[Bounds[int]], what string) error {
+	if schemaLength(size, length) != size {
+		return anError{fmt.Sprintf("%s has length %d outside %v", what, size, length.Val)}
+	}
+	return nil
+}
+
+// Returns the base as a float64, or false if T is not numeric.
+func baseFloat[T Ordered](base T) (float64, bool) {
+	value := reflect.ValueOf(base)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0.0, false
+	}
+}
+
+// Returns the float64 converted to T, rounding to the nearest integer for
+// integer types.
+func floatBase[T Ordered](f float64) T {
+	var zero T
+	value := reflect.New(reflect.TypeOf(zero)).Elem()
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value.SetInt(int64(math.Round(f)))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value.SetUint(uint64(math.Round(f)))
+	case reflect.Float32, reflect.Float64:
+		value.SetFloat(f)
+	}
+	return value.Interface().(T)
+}
+
+// Returns the base reflected back and forth off the Bounds until it is within
+// them. Non-numeric bases are clamped instead.
+func reflectBase[T Ordered](base T, bounds Bounds[T]) T {
+	x, ok := baseFloat(base)
+	lower, _ := baseFloat(bounds.Lower)
+	upper, _ := baseFloat(bounds.Upper)
+	if !ok || upper <= lower {
+		return bounds.Clamp(base)
+	}
+	span := upper - lower
+	offset := math.Mod(x-lower, 2.0*span)
+	if offset < 0.0 {
+		offset += 2.0 * span
+	}
+	if offset > span {
+		offset = 2.0*span - offset
+	}
+	return bounds.Clamp(floatBase[T](lower + offset))
+}
+
+// Returns the allowed value closest to the base. Non-numeric bases are
+// replaced by the smallest allowed value that is not less than the base, or by
+// the largest allowed value if there is none.
+func nearestAllowed[T Ordered](base T, allowed []T) T {
+	sorted := append([]T{}, allowed...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := sort.Search(len(sorted), func(i int) bool { return sorted[i] >= base })
+	if index == len(sorted) {
+		return sorted[index-1]
+	}
+	if index == 0 || sorted[index] == base {
+		return sorted[index]
+	}
+	x, ok := baseFloat(base)
+	above, _ := baseFloat(sorted[index])
+	below, _ := baseFloat(sorted[index-1])
+	if ok && x-below < above-x {
+		return sorted[index-1]
+	}
+	return sorted[index]
+}
+
+// Returns a random legal base for position i, or false if the schema has
+// neither Allowed values nor numeric Bounds for it.
+func (s GeneSchema[T]) randomBase(i int, rng *Rand) (T, bool) {
+	if len(s.Allowed) > 0 {
+		return s.Allowed[randomInt(rng, 0, len(s.Allowed))], true
+	}
+	bounds, ok := s.bounds(i)
+	if !ok {
+		var zero T
+		return zero, false
+	}
+	lower, ok := baseFloat(bounds.Lower)
+	upper, _ := baseFloat(bounds.Upper)
+	if !ok {
+		return bounds.Lower, false
+	}
+	var zero T
+	switch reflect.ValueOf(zero).Kind() {
+	case reflect.Float32, reflect.Float64:
+		return floatBase[T](lower + rng.Float64()*(upper-lower)), true
+	default:
+		return floatBase[T](float64(randomInt(rng, int(lower), int(upper)+1))), true
+	}
+}
+
+// Returns an error describing the first base of the Gene that violates the
+// schema.
+func (s GeneSchema[T]) validate(gene *Gene[T]) error {
+	gene.Mu.RLock()
+	defer gene.Mu.RUnlock()
+	if err := validateLength(len(gene.Bases), s.Length, "gene "+gene.Name); err != nil {
+		return err
+	}
+	for i, base := range gene.Bases {
+		if bounds, ok := s.bounds(i); ok && bounds.Clamp(base) != base {
+			return anError{fmt.Sprintf("gene %s base %d: %v is outside %v", gene.Name, i, base, bounds)}
+		}
+		if len(s.Allowed) > 0 && !contains(s.Allowed, base) {
+			return anError{fmt.Sprintf("gene %s base %d: %v is not allowed", gene.Name, i, base)}
+		}
+	}
+	return nil
+}
+
+// Truncates or pads the Gene to fit Length, then moves every base that is
+// outside its Bounds back within them and replaces every base that is not
+// Allowed with the closest one that is. Padding repeats the last base.
+func (s GeneSchema[T]) repair(gene *Gene[T], method RepairMethod) {
+	gene.Mu.Lock()
+	defer gene.Mu.Unlock()
+	size := schemaLength(len(gene.Bases), s.Length)
+	if size < len(gene.Bases) {
+		gene.Bases = gene.Bases[:size]
+	}
+	for len(gene.Bases) < size {
+		var base T
+		if len(gene.Bases) > 0 {
+			base = gene.Bases[len(gene.Bases)-1]
+		}
+		gene.Bases = append(gene.Bases, base)
+	}
+	for i, base := range gene.Bases {
+		if bounds, ok := s.bounds(i); ok {
+			if method == ReflectRepair {
+				base = reflectBase(base, bounds)
+			} else {
+				base = bounds.Clamp(base)
+			}
+		}
+		if len(s.Allowed) > 0 {
+			base = nearestAllowed(base, s.Allowed)
+		}
+		gene.Bases[i] = base
+	}
+}
+
+// Returns an error describing the first violation of the schema by the
+// Nucleosome or its Genes.
+func (s Schema[T]) validateNucleosome(nucleosome *Nucleosome[T]) error {
+	nucleosome.Mu.RLock()
+	defer nucleosome.Mu.RUnlock()
+	err := validateLength(len(nucleosome.Genes), s.NucleosomeLength, "nucleosome "+nucleosome.Name)
+	if err != nil {
+		return err
+	}
+	for _, gene := range nucleosome.Genes {
+		if err := s.gene(gene.Name).validate(gene); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Truncates the Nucleosome or pads it with copies of its last Gene to fit
+// NucleosomeLength, then repairs its Genes.
+func (s Schema[T]) repairNucleosome(nucleosome *Nucleosome[T], method RepairMethod) {
+	nucleosome.Mu.Lock()
+	defer nucleosome.Mu.Unlock()
+	size := schemaLength(len(nucleosome.Genes), s.NucleosomeLength)
+	if size < len(nucleosome.Genes) {
+		nucleosome.Genes = nucleosome.Genes[:size]
+	}
+	for len(nucleosome.Genes) > 0 && len(nucleosome.Genes) < size {
+		nucleosome.Genes = append(nucleosome.Genes, nucleosome.Genes[len(nucleosome.Genes)-1].Copy())
+	}
+	for _, gene := range nucleosome.Genes {
+		s.gene(gene.Name).repair(gene, method)
+	}
+}
+
+// Returns an error describing the first violation of the schema by the
+// Chromosome or its Nucleosomes.
+func (s Schema[T]) validateChromosome(chromosome *Chromosome[T]) error {
+	chromosome.Mu.RLock()
+	defer chromosome.Mu.RUnlock()
+	err := validateLength(len(chromosome.Nucleosomes), s.ChromosomeLength, "chromosome "+chromosome.Name)
+	if err != nil {
+		return err
+	}
+	for _, nucleosome := range chromosome.Nucleosomes {
+		if err := s.validateNucleosome(nucleosome); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Truncates the Chromosome or pads it with copies of its last Nucleosome to
+// fit ChromosomeLength, then repairs its Nucleosomes.
+func (s Schema[T]) repairChromosome(chromosome *Chromosome[T], method RepairMethod) {
+	chromosome.Mu.Lock()
+	defer chromosome.Mu.Unlock()
+	size := schemaLength(len(chromosome.Nucleosomes), s.ChromosomeLength)
+	if size < len(chromosome.Nucleosomes) {
+		chromosome.Nucleosomes = chromosome.Nucleosomes[:size]
+	}
+	for len(chromosome.Nucleosomes) > 0 && len(chromosome.Nucleosomes) < size {
+		last := chromosome.Nucleosomes[len(chromosome.Nucleosomes)-1]
+		chromosome.Nucleosomes = append(chromosome.Nucleosomes, last.DeepCopy())
+	}
+	for _, nucleosome := range chromosome.Nucleosomes {
+		s.repairNucleosome(nucleosome, method)
+	}
+}
+
+// Returns an error describing the first violation of the schema by the Genome
+// or its Chromosomes.
+func (s Schema[T]) validateGenome(genome *Genome[T]) error {
+	genome.Mu.RLock()
+	defer genome.Mu.RUnlock()
+	err := validateLength(len(genome.Chromosomes), s.GenomeLength, "genome "+genome.Name)
+	if err != nil {
+		return err
+	}
+	for _, chromosome := range genome.Chromosomes {
+		if err := s.validateChromosome(chromosome); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Truncates the Genome or pads it with copies of its last Chromosome to fit
+// GenomeLength, then repairs its Chromosomes.
+func (s Schema[T]) repairGenome(genome *Genome[T], method RepairMethod) {
+	genome.Mu.Lock()
+	defer genome.Mu.Unlock()
+	size := schemaLength(len(genome.Chromosomes), s.GenomeLength)
+	if size < len(genome.Chromosomes) {
+		genome.Chromosomes = genome.Chromosomes[:size]
+	}
+	for len(genome.Chromosomes) > 0 && len(genome.Chromosomes) < size {
+		last := genome.Chromosomes[len(genome.Chromosomes)-1]
+		genome.Chromosomes = append(genome.Chromosomes, last.DeepCopy())
+	}
+	for _, chromosome := range genome.Chromosomes {
+		s.repairChromosome(chromosome, method)
+	}
+}
+
+// Returns an error describing the first violation of the Schema by any level
+// of the Code, or nil if there is none.
+func (s Schema[T]) Validate(code Code[T]) error {
+	if code.Gene.Ok() {
+		if err := s.gene(code.Gene.Val.Name).validate(code.Gene.Val); err != nil {
+			return err
+		}
+	}
+	if code.Nucleosome.Ok() {
+		if err := s.validateNucleosome(code.Nucleosome.Val); err != nil {
+			return err
+		}
+	}
+	if code.Chromosome.Ok() {
+		if err := s.validateChromosome(code.Chromosome.Val); err != nil {
+			return err
+		}
+	}
+	if code.Genome.Ok() {
+		if err := s.validateGenome(code.Genome.Val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Changes every level of the Code in place to fit the Schema. Structures that
+// are too long are truncated, and structures that are too short are padded
+// with copies of their last subunit (empty structures other than Genes are
+// left empty). Bases outside their Bounds are clamped or reflected back within
+// them according to method, and bases that are not Allowed are replaced with
+// the closest Allowed value.
+func (s Schema[T]) Repair(code *Code[T], method RepairMethod) {
+	if code.Gene.Ok() {
+		s.gene(code.Gene.Val.Name).repair(code.Gene.Val, method)
+	}
+	if code.Nucleosome.Ok() {
+		s.repairNucleosome(code.Nucleosome.Val, method)
+	}
+	if code.Chromosome.Ok() {
+		s.repairChromosome(code.Chromosome.Val, method)
+	}
+	if code.Genome.Ok() {
+		s.repairGenome(code.Genome.Val, method)
+	}
+}
+
+// Returns a Mutator that applies the mutator and then repairs the Gene to fit
+// the Schema.
+func (s Schema[T]) Constrain(mutator Mutator[T], method RepairMethod) Mutator[T] {
+	return func(gene *Gene[T], rng *Rand) {
+		mutator(gene, rng)
+		s.gene(gene.Name).repair(gene, method)
+	}
+}
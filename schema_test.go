@@ -0,0 +1,273 @@
+package bluegenes
+
+import (
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[int]{
+			Gene:             GeneSchema[int]{Bounds: []Bounds[int]{{0, 1}, {0, 5}}, Length: NewOption(Bounds[int]{2, 4})},
+			Genes:            map[string]GeneSchema[int]{"odd": {Allowed: []int{1, 3, 5}}},
+			NucleosomeLength: NewOption(Bounds[int]{1, 2}),
+		}
+		valid := &Nucleosome[int]{Genes: []*Gene[int]{
+			{Name: "a", Bases: []int{1, 5, 0}},
+			{Name: "odd", Bases: []int{9, 9, 9, 9, 9}},
+		}}
+		if err := schema.Validate(Code[int]{Nucleosome: NewOption(valid)}); err == nil {
+			t.Errorf("Schema.Validate failed to reject a base that is not Allowed")
+		}
+		valid.Genes[1].Bases = []int{5, 1, 3, 3, 1}
+		if err := schema.Validate(Code[int]{Nucleosome: NewOption(valid)}); err != nil {
+			t.Errorf("Schema.Validate failed with error: %v", err)
+		}
+		invalid := map[string]*Gene[int]{
+			"position bounds": {Name: "a", Bases: []int{2, 0}},
+			"last bounds":     {Name: "a", Bases: []int{0, 1, 6}},
+			"short":           {Name: "a", Bases: []int{0}},
+			"long":            {Name: "a", Bases: []int{0, 1, 2, 3, 4}},
+		}
+		for name, gene := range invalid {
+			if err := schema.Validate(Code[int]{Gene: NewOption(gene)}); err == nil {
+				t.Errorf("Schema.Validate failed to reject %s: %v", name, gene.Bases)
+			}
+		}
+		valid.Genes = append(valid.Genes, valid.Genes[0].Copy())
+		if err := schema.Validate(Code[int]{Nucleosome: NewOption(valid)}); err == nil {
+			t.Errorf("Schema.Validate failed to reject a Nucleosome with too many Genes")
+		}
+	})
+
+	t.Run("Repair", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[float64]{Gene: GeneSchema[float64]{
+			Bounds: []Bounds[float64]{{0.0, 1.0}, {-2.0, 2.0}},
+			Length: NewOption(Bounds[int]{4, 4}),
+		}}
+		expected := map[RepairMethod][]float64{
+			ClampRepair:   {1.0, -2.0, 2.0, 2.0},
+			ReflectRepair: {0.5, 1.0, 1.0, 1.0},
+		}
+		for method, bases := range expected {
+			gene := &Gene[float64]{Name: "a", Bases: []float64{1.5, -7.0, 3.0}}
+			code := Code[float64]{Gene: NewOption(gene)}
+			schema.Repair(&code, method)
+			if !equal(gene.Bases, bases) {
+				t.Errorf("Schema.Repair(%d) failed: expected %v, observed %v", method, bases, gene.Bases)
+			}
+			if err := schema.Validate(code); err != nil {
+				t.Errorf("Schema.Repair(%d) failed to fit the Schema: %v", method, err)
+			}
+		}
+
+		allowed := Schema[int]{Gene: GeneSchema[int]{Allowed: []int{10, 0, 5}}}
+		gene := &Gene[int]{Bases: []int{-3, 2, 3, 8, 100}}
+		allowed.Repair(&Code[int]{Gene: NewOption(gene)}, ClampRepair)
+		if !equal(gene.Bases, []int{0, 0, 5, 10, 10}) {
+			t.Errorf("Schema.Repair failed to use the nearest Allowed value: observed %v", gene.Bases)
+		}
+	})
+
+	t.Run("Repair/structure", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[int]{
+			Gene:             GeneSchema[int]{Length: NewOption(Bounds[int]{2, 2})},
+			NucleosomeLength: NewOption(Bounds[int]{3, 0}),
+			ChromosomeLength: NewOption(Bounds[int]{1, 2}),
+			GenomeLength:     NewOption(Bounds[int]{2, 2}),
+		}
+		genome, _ := rangeGenome(3, 3, 1, 0, 5)
+		code := Code[int]{Genome: NewOption(genome)}
+		schema.Repair(&code, ClampRepair)
+		if err := schema.Validate(code); err != nil {
+			t.Fatalf("Schema.Repair failed to fit the Schema: %v", err)
+		}
+		if len(genome.Chromosomes) != 2 || len(genome.Chromosomes[0].Nucleosomes) != 2 ||
+			len(genome.Chromosomes[0].Nucleosomes[0].Genes) != 3 {
+			t.Errorf("Schema.Repair failed to truncate and pad the Genome")
+		}
+	})
+
+	t.Run("Constrain", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[float64]{Gene: GeneSchema[float64]{Bounds: []Bounds[float64]{{-1.0, 1.0}}}}
+		mutate := schema.Constrain(GaussianMutation[float64](1.0, 100.0), ReflectRepair)
+		gene := &Gene[float64]{Bases: make([]float64, 10)}
+		rng := NewRand(5)
+		for i := 0; i < 20; i++ {
+			mutate(gene, rng)
+			if err := schema.Validate(Code[float64]{Gene: NewOption(gene)}); err != nil {
+				t.Fatalf("Schema.Constrain failed: %v", err)
+			}
+		}
+	})
+
+	t.Run("MakeCode", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[int]{
+			Gene:             GeneSchema[int]{Bounds: []Bounds[int]{{-3, 3}}, Length: NewOption(Bounds[int]{4, 8})},
+			NucleosomeLength: NewOption(Bounds[int]{2, 3}),
+			ChromosomeLength: NewOption(Bounds[int]{1, 1}),
+		}
+		rng := NewRand(7)
+		values := newSet[int]()
+		for i := 0; i < 10; i++ {
+			code, err := MakeCode(MakeOptions[int]{
+				NBases:       NewOption(uint(2)),
+				NGenes:       NewOption(uint(5)),
+				NNucleosomes: NewOption(uint(3)),
+				Rand:         NewOption(rng),
+				Schema:       NewOption(schema),
+			}, Code[int]{Gene: NewOption(&Gene[int]{}), Chromosome: NewOption(&Chromosome[int]{})})
+			if err != nil {
+				t.Fatalf("MakeCode with Schema failed with error: %v", err)
+			}
+			if err := schema.Validate(code); err != nil {
+				t.Fatalf("MakeCode with Schema failed to generate within it: %v", err)
+			}
+			if len(code.Chromosome.Val.Nucleosomes[0].Genes) != 3 {
+				t.Fatalf("MakeCode with Schema failed to limit NGenes: observed %d", len(code.Chromosome.Val.Nucleosomes[0].Genes))
+			}
+			for _, base := range code.Gene.Val.Bases {
+				values.add(base)
+			}
+		}
+		if values.len() != 7 {
+			t.Errorf("MakeCode with Schema failed to draw every legal base: observed %v", values.toSlice())
+		}
+
+		_, err := MakeGene(MakeOptions[string]{NBases: NewOption(uint(2)), Schema: NewOption(Schema[string]{})})
+		if err == nil {
+			t.Errorf("MakeGene failed to require BaseFactory for a Schema without Allowed values or Bounds")
+		}
+	})
+
+	t.Run("MakeGenome", func(t *testing.T) {
+		t.Parallel()
+		schema := Schema[int]{
+			Gene:         GeneSchema[int]{Bounds: []Bounds[int]{{-3, 3}}},
+			GenomeLength: NewOption(Bounds[int]{3, 4}),
+		}
+		rng := NewRand(11)
+		for n, expected := range map[uint]int{1: 3, 4: 4, 6: 4} {
+			genome, err := MakeGenome(MakeOptions[int]{
+				NBases:       NewOption(uint(4)),
+				NGenes:       NewOption(uint(2)),
+				NNucleosomes: NewOption(uint(2)),
+				NChromosomes: NewOption(n),
+				Rand:         NewOption(rng),
+				Schema:       NewOption(schema),
+			})
+			if err != nil {
+				t.Fatalf("MakeGenome with Schema failed with error: %v", err)
+			}
+			if len(genome.Chromosomes) != expected {
+				t.Fatalf("MakeGenome with Schema failed: expected %d chromosomes, observed %d", expected, len(genome.Chromosomes))
+			}
+			names := newSet[string]()
+			for _, chromosome := range genome.Chromosomes {
+				names.add(chromosome.Name)
+			}
+			if names.len() != expected {
+				t.Errorf("MakeGenome with Schema failed to make distinct chromosomes: observed %v", names.toSlice())
+			}
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(53)
+		schema := Schema[float64]{Gene: GeneSchema[float64]{Bounds: []Bounds[float64]{{-2.0, 0.5}}}}
+		initial_population := sphereInitialPopulation(rng, 20)
+		for i := range initial_population {
+			schema.Repair(&initial_population[i], ClampRepair)
+		}
+		_, final_population, err := Optimize(OptimizationParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(measureSphereFitness),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](0.5, 3.0)(code.Gene.Val, rng)
+			}),
+			Schema:        NewOption(schema),
+			RepairMethod:  NewOption(ReflectRepair),
+			MaxIterations: NewOption(200),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with Schema failed with error: %v", err)
+		}
+		for _, score := range final_population {
+			if err := schema.Validate(score.Code); err != nil {
+				t.Fatalf("Optimize with Schema failed to repair a child: %v", err)
+			}
+		}
+		if final_population[0].Score < 0.45 {
+			t.Errorf("Optimize with Schema failed to approach the constrained optimum: %f", final_population[0].Score)
+		}
+	})
+
+	t.Run("Optimize/Genes", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(59)
+		schema := Schema[float64]{
+			Gene: GeneSchema[float64]{Bounds: []Bounds[float64]{{-1000.0, 1000.0}}},
+			Genes: map[string]GeneSchema[float64]{
+				"low":  {Bounds: []Bounds[float64]{{0.0, 10.0}}},
+				"high": {Bounds: []Bounds[float64]{{100.0, 110.0}}},
+			},
+		}
+		initial_population := make([]Code[float64], 20)
+		for i := range initial_population {
+			names := []string{"low", "high"}
+			if rng.Intn(2) == 0 {
+				names[0], names[1] = names[1], names[0]
+			}
+			nucleosome := &Nucleosome[float64]{Name: "n"}
+			for _, name := range names {
+				gene := &Gene[float64]{Name: name, Bases: []float64{5.0, 5.0, 5.0}}
+				if name == "high" {
+					gene.Bases = []float64{105.0, 105.0, 105.0}
+				}
+				nucleosome.Genes = append(nucleosome.Genes, gene)
+			}
+			initial_population[i] = Code[float64]{Nucleosome: NewOption(nucleosome)}
+		}
+		_, final_population, err := Optimize(OptimizationParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness: NewOption(func(code Code[float64]) float64 {
+				total := 0.0
+				for _, gene := range code.Nucleosome.Val.Genes {
+					for _, base := range gene.Bases {
+						total += base
+					}
+				}
+				return total
+			}),
+			Mutate: NewOption(func(code *Code[float64]) {
+				for _, gene := range code.Nucleosome.Val.Genes {
+					GaussianMutation[float64](0.5, 3.0)(gene, rng)
+				}
+			}),
+			Schema:        NewOption(schema),
+			RepairMethod:  NewOption(ClampRepair),
+			FitnessTarget: NewOption(1000.0),
+			MaxIterations: NewOption(50),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with Schema.Genes failed with error: %v", err)
+		}
+		for _, score := range final_population {
+			for _, gene := range score.Code.Nucleosome.Val.Genes {
+				if gene.Name != "low" && gene.Name != "high" {
+					t.Fatalf("Optimize with Schema.Genes failed: expected low or high, observed %v", gene.Name)
+				}
+			}
+			if err := schema.Validate(score.Code); err != nil {
+				t.Fatalf("Optimize with Schema.Genes failed to repair a child: %v", err)
+			}
+		}
+	})
+}
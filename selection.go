@@ -0,0 +1,254 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+// A Selector chooses n parents for breeding from a population that is sorted
+// by descending Score. The same Code may be chosen more than once. All
+// randomness should come from rng, which may be nil (see Rand).
+type Selector[T Ordered] interface {
+	Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T]
+}
+
+// The default Selector, which picks parents with probability proportional to
+// (len(population)-index), i.e. the original rank-weighted breeding scheme.
+// Each consecutive pair (dad, mom) holds two different members whenever the
+// population has more than one.
+type weightedSelector[T Ordered] struct{}
+
+func (s weightedSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	weights := make([]float64, len(population))
+	for i := range weights {
+		weights[i] = float64(len(population) - i)
+	}
+	selected := make([]Code[T], 0, n)
+	for len(selected) < n && len(population) > 0 {
+		dad := rouletteIndices(rng, weights, 1)[0]
+		selected = append(selected, population[dad].Code)
+		if len(selected) == n {
+			break
+		}
+		mom := dad
+		for mom == dad && len(population) > 1 {
+			mom = rouletteIndices(rng, weights, 1)[0]
+		}
+		selected = append(selected, population[mom].Code)
+	}
+	return selected
+}
+
+// Chooses the best of K randomly drawn individuals for each selection. Larger
+// K means higher selection pressure; K defaults to 2. Individuals with a lower
+// Violation are always better (Deb's feasibility rules).
+type TournamentSelector[T Ordered] struct {
+	K int
+}
+
+func (s TournamentSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	k := s.K
+	if k < 1 {
+		k = 2
+	}
+	selected := make([]Code[T], 0, n)
+	for len(selected) < n {
+		best := randomInt(rng, 0, len(population))
+		for i := 1; i < k; i++ {
+			contender := randomInt(rng, 0, len(population))
+			if feasiblyBetter(population[contender], population[best], Maximize) {
+				best = contender
+			}
+		}
+		selected = append(selected, population[best].Code)
+	}
+	return selected
+}
+
+// Fitness-proportionate (roulette wheel) selection. Scores are shifted so that
+// the worst individual has weight 0 whenever any Score is negative.
+type RouletteSelector[T Ordered] struct{}
+
+func (s RouletteSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	return selectIndices(population, rouletteIndices(rng, fitnessWeights(population), n))
+}
+
+// Fitness-proportionate selection using a single spin with n evenly spaced
+// pointers, which has lower variance than RouletteSelector.
+type StochasticUniversalSelector[T Ordered] struct{}
+
+func (s StochasticUniversalSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	return selectIndices(population, stochasticUniversalIndices(rng, fitnessWeights(population), n))
+}
+
+// Linear rank selection. Pressure is the expected number of offspring of the
+// best individual and must be in [1.0, 2.0]; it defaults to 1.5.
+type LinearRankSelector[T Ordered] struct {
+	Pressure float64
+}
+
+func (s LinearRankSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	pressure := s.Pressure
+	if pressure < 1.0 || pressure > 2.0 {
+		pressure = 1.5
+	}
+	size := len(population)
+	weights := make([]float64, size)
+	for i := range weights {
+		if size == 1 {
+			weights[i] = 1.0
+			break
+		}
+		rank := float64(size - 1 - i)
+		weights[i] = (2.0 - pressure) + 2.0*(pressure-1.0)*rank/float64(size-1)
+	}
+	return selectIndices(population, rouletteIndices(rng, weights, n))
+}
+
+// Exponential rank selection: the individual at index i has weight Base^i.
+// Base must be in (0.0, 1.0); it defaults to 0.9. Smaller Base means higher
+// selection pressure.
+type ExponentialRankSelector[T Ordered] struct {
+	Base float64
+}
+
+func (s ExponentialRankSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	base := s.Base
+	if base <= 0.0 || base >= 1.0 {
+		base = 0.9
+	}
+	weights := make([]float64, len(population))
+	for i := range weights {
+		weights[i] = math.Pow(base, float64(i))
+	}
+	return selectIndices(population, rouletteIndices(rng, weights, n))
+}
+
+// Boltzmann selection: each individual has weight exp(Score/Temperature).
+// Lower Temperature means higher selection pressure; it defaults to 1.0.
+type BoltzmannSelector[T Ordered] struct {
+	Temperature float64
+}
+
+func (s BoltzmannSelector[T]) Select(population []*ScoredCode[T], n int, rng *Rand) []Code[T] {
+	temperature := s.Temperature
+	if temperature <= 0.0 {
+		temperature = 1.0
+	}
+	weights := make([]float64, len(population))
+	if len(population) == 0 {
+		return []Code[T]{}
+	}
+	best, _ := max(scoresOf(population)...)
+	for i, sc := range population {
+		// subtract the best score to avoid overflow
+		weights[i] = math.Exp((sc.Score - best) / temperature)
+	}
+	return selectIndices(population, rouletteIndices(rng, weights, n))
+}
+
+func scoresOf[T Ordered](population []*ScoredCode[T]) []float64 {
+	scores := make([]float64, len(population))
+	for i, sc := range population {
+		scores[i] = sc.Score
+	}
+	return scores
+}
+
+func fitnessWeights[T Ordered](population []*ScoredCode[T]) []float64 {
+	weights := scoresOf(population)
+	smallest, err := min(weights...)
+	if err != nil || smallest >= 0.0 {
+		return weights
+	}
+	for i := range weights {
+		weights[i] -= smallest
+	}
+	return weights
+}
+
+func selectIndices[T Ordered](population []*ScoredCode[T], indices []int) []Code[T] {
+	selected := make([]Code[T], len(indices))
+	for i, idx := range indices {
+		selected[i] = population[idx].Code
+	}
+	return selected
+}
+
+func cumulativeWeights(weights []float64) ([]float64, float64) {
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		if w > 0.0 && !math.IsInf(w, 0) && !math.IsNaN(w) {
+			total += w
+		}
+		cumulative[i] = total
+	}
+	return cumulative, total
+}
+
+func rouletteIndices(rng *Rand, weights []float64, n int) []int {
+	indices := make([]int, 0, n)
+	if len(weights) == 0 {
+		return indices
+	}
+	cumulative, total := cumulativeWeights(weights)
+	for len(indices) < n {
+		if total <= 0.0 {
+			indices = append(indices, randomInt(rng, 0, len(weights)))
+			continue
+		}
+		spin := rng.Float64() * total
+		idx := sort.SearchFloat64s(cumulative, spin)
+		for idx < len(cumulative)-1 && cumulative[idx] <= spin {
+			idx++
+		}
+		indices = append(indices, idx)
+	}
+	return indices
+}
+
+func stochasticUniversalIndices(rng *Rand, weights []float64, n int) []int {
+	indices := make([]int, 0, n)
+	if len(weights) == 0 || n < 1 {
+		return indices
+	}
+	cumulative, total := cumulativeWeights(weights)
+	if total <= 0.0 {
+		return rouletteIndices(rng, weights, n)
+	}
+	step := total / float64(n)
+	pointer := rng.Float64() * step
+	idx := 0
+	for len(indices) < n {
+		for idx < len(cumulative)-1 && cumulative[idx] <= pointer {
+			idx++
+		}
+		indices = append(indices, idx)
+		pointer += step
+	}
+	return indices
+}
+
+// Selects 2*n parents and returns them shuffled so that consecutive items can
+// be paired as mates. When minimizing, the selector is given copies of the
+// parents with negated Scores so that higher is always better.
+func selectMates[T Ordered](selector Selector[T], parents []*ScoredCode[T], n int,
+	direction Direction, rng *Rand) []Code[T] {
+	if direction == Minimize {
+		oriented := make([]*ScoredCode[T], len(parents))
+		for i, parent := range parents {
+			oriented[i] = &ScoredCode[T]{Code: parent.Code, Score: -parent.Score, Violation: parent.Violation}
+		}
+		parents = oriented
+	}
+	mates := selector.Select(parents, 2*n, rng)
+	if _, ok := selector.(weightedSelector[T]); ok {
+		// its draws are independent and already paired
+		return mates
+	}
+	rng.Shuffle(len(mates), func(i, j int) {
+		mates[i], mates[j] = mates[j], mates[i]
+	})
+	return mates
+}
@@ -0,0 +1,109 @@
+package bluegenes
+
+import (
+	"testing"
+)
+
+func scoredPopulation(size int) []*ScoredCode[int] {
+	population := []*ScoredCode[int]{}
+	for i := 0; i < size; i++ {
+		gene := &Gene[int]{Name: string(alphanumerics[i]), Bases: []int{i}}
+		population = append(population, &ScoredCode[int]{
+			Code:  Code[int]{Gene: NewOption(gene)},
+			Score: float64(size-i) / float64(size),
+		})
+	}
+	return population
+}
+
+func countSelections(selected []Code[int]) map[int]int {
+	counts := map[int]int{}
+	for _, code := range selected {
+		counts[code.Gene.Val.Bases[0]]++
+	}
+	return counts
+}
+
+func TestSelectors(t *testing.T) {
+	selectors := map[string]Selector[int]{
+		"weighted":                weightedSelector[int]{},
+		"Tournament":              TournamentSelector[int]{K: 3},
+		"Roulette":                RouletteSelector[int]{},
+		"StochasticUniversal":     StochasticUniversalSelector[int]{},
+		"LinearRank":              LinearRankSelector[int]{Pressure: 2.0},
+		"ExponentialRank":         ExponentialRankSelector[int]{Base: 0.8},
+		"Boltzmann":               BoltzmannSelector[int]{Temperature: 0.1},
+		"TournamentDefaults":      TournamentSelector[int]{},
+		"LinearRankDefaults":      LinearRankSelector[int]{},
+		"ExponentialRankDefaults": ExponentialRankSelector[int]{},
+		"BoltzmannDefaults":       BoltzmannSelector[int]{},
+	}
+
+	for name, selector := range selectors {
+		name, selector := name, selector
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			population := scoredPopulation(10)
+			selected := selector.Select(population, 1000, nil)
+
+			if len(selected) != 1000 {
+				t.Fatalf("%s.Select failed: expected 1000 items, observed %d", name, len(selected))
+			}
+
+			counts := countSelections(selected)
+			if counts[0] <= counts[9] {
+				t.Errorf("%s.Select failed to apply selection pressure: best chosen %d times, worst chosen %d times",
+					name, counts[0], counts[9])
+			}
+		})
+	}
+
+	t.Run("weighted/distinct", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(3)
+		mates := selectMates[int](weightedSelector[int]{}, population, 500, Maximize, NewRand(5))
+		for i := 0; i < len(mates); i += 2 {
+			if mates[i].Gene.Val == mates[i+1].Gene.Val {
+				t.Fatalf("weightedSelector.Select failed: pair %d has the same dad and mom", i/2)
+			}
+		}
+		if selected := (weightedSelector[int]{}).Select(population[:1], 4, nil); len(selected) != 4 {
+			t.Errorf("weightedSelector.Select failed with one member: expected 4 items, observed %d", len(selected))
+		}
+	})
+
+	t.Run("Roulette/negative", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(10)
+		for _, sc := range population {
+			sc.Score -= 2.0
+		}
+		counts := countSelections(RouletteSelector[int]{}.Select(population, 1000, nil))
+		if counts[9] != 0 {
+			t.Errorf("RouletteSelector.Select failed: worst individual should have weight 0, chosen %d times", counts[9])
+		}
+	})
+
+	t.Run("StochasticUniversal/spread", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(10)
+		for _, sc := range population {
+			sc.Score = 1.0
+		}
+		counts := countSelections(StochasticUniversalSelector[int]{}.Select(population, 100, nil))
+		for i := 0; i < 10; i++ {
+			if counts[i] != 10 {
+				t.Errorf("StochasticUniversalSelector.Select failed: expected 10 selections of %d, observed %d", i, counts[i])
+			}
+		}
+	})
+
+	t.Run("selectMates", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(10)
+		mates := selectMates[int](TournamentSelector[int]{K: 2}, population, 45, Maximize, nil)
+		if len(mates) != 90 {
+			t.Errorf("selectMates failed: expected 90 mates, observed %d", len(mates))
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package bluegenes
+
+import (
+	"math"
+	"sort"
+)
+
+// NEAT-style speciation (Stanley and Miikkulainen). Each member of the
+// population belongs to the first species whose representative is within
+// Threshold of it according to Distance, which defaults to Code.Distance with
+// Metric; a member that is compatible with no species founds a new one. Each
+// generation, the children are allotted to the species in proportion to the
+// mean (shifted) fitness of their members, i.e. the sum of their fitness
+// adjusted by sharing it within the species. The best SurvivalRate (default
+// 0.2) of each species are its parents, the best Elites (default 1) of each
+// species with more than Elites members survive unchanged, and a species that
+// has not improved for StagnationLimit (default 15) generations has no
+// children or survivors unless it holds the best member of the population.
+type Speciation[T Ordered] struct {
+	Distance        func(Code[T], Code[T]) float64
+	Metric          DistanceMetric
+	Threshold       float64
+	StagnationLimit int
+	Elites          int
+	SurvivalRate    float64
+}
+
+// A species tracked by an optimizationRun.
+type species[T Ordered] struct {
+	representative Code[T]
+	best           float64
+	stagnant       int
+}
+
+// Returns the compatibility distance between the two Codes.
+func (s Speciation[T]) distance(a, b Code[T]) float64 {
+	if s.Distance != nil {
+		return s.Distance(a, b)
+	}
+	return a.Distance(b, s.Metric)
+}
+
+func (s Speciation[T]) stagnationLimit() int {
+	if s.StagnationLimit < 1 {
+		return 15
+	}
+	return s.StagnationLimit
+}
+
+func (s Speciation[T]) elites() int {
+	if s.Elites < 1 {
+		return 1
+	}
+	return s.Elites
+}
+
+func (s Speciation[T]) survivalRate() float64 {
+	if s.SurvivalRate <= 0.0 || s.SurvivalRate > 1.0 {
+		return 0.2
+	}
+	return s.SurvivalRate
+}
+
+// Returns the number of members of each species in the population.
+func SpeciesSizes[T Ordered](scores []*ScoredCode[T]) map[int]int {
+	sizes := map[int]int{}
+	for _, score := range scores {
+		sizes[score.Species]++
+	}
+	return sizes
+}
+
+// Returns the IDs of the species in ascending order.
+func (r *optimizationRun[T]) speciesIDs() []int {
+	ids := []int{}
+	for id := range r.species {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Assigns every member without a species to the first compatible species,
+// founding a new species for it if there is none.
+func (r *optimizationRun[T]) speciate() {
+	if r.species == nil {
+		r.species = map[int]*species[T]{}
+	}
+	speciation := r.params.Speciation.Val
+	ids := r.speciesIDs()
+	for _, score := range r.scores {
+		if score.Species != 0 {
+			continue
+		}
+		for _, id := range ids {
+			if speciation.distance(score.Code, r.species[id].representative) < speciation.Threshold {
+				score.Species = id
+				break
+			}
+		}
+		if score.Species == 0 {
+			r.nextSpecies++
+			score.Species = r.nextSpecies
+			r.species[r.nextSpecies] = &species[T]{
+				representative: score.Code.DeepCopy(),
+				best:           r.params.Direction.Val.worst(),
+			}
+			ids = append(ids, r.nextSpecies)
+		}
+	}
+}
+
+// Returns the members of each species in ranked order.
+func (r *optimizationRun[T]) speciesMembers() map[int][]*ScoredCode[T] {
+	members := map[int][]*ScoredCode[T]{}
+	for _, score := range r.scores {
+		members[score.Species] = append(members[score.Species], score)
+	}
+	return members
+}
+
+// Updates the best Score, stagnation, and representative of each species after
+// a generation and forgets the species that have died out. The best member of
+// each species becomes its representative.
+func (r *optimizationRun[T]) updateSpecies() {
+	members := r.speciesMembers()
+	direction := r.params.Direction.Val
+	for _, id := range r.speciesIDs() {
+		if len(members[id]) == 0 {
+			delete(r.species, id)
+			continue
+		}
+		record, best := r.species[id], members[id][0]
+		if direction.better(best.Score, record.best) {
+			record.best = best.Score
+			record.stagnant = 0
+		} else {
+			record.stagnant++
+		}
+		record.representative = best.Code.DeepCopy()
+	}
+}
+
+// Returns true if the species may not reproduce or survive because it has
+// stagnated. The species of the best member is never culled.
+func (r *optimizationRun[T]) culled(id int) bool {
+	if len(r.scores) > 0 && r.scores[0].Species == id {
+		return false
+	}
+	record, ok := r.species[id]
+	return !ok || record.stagnant >= r.params.Speciation.Val.stagnationLimit()
+}
+
+// Returns the members that survive unchanged.
+func (r *optimizationRun[T]) speciesElites() []*ScoredCode[T] {
+	members, elites := r.speciesMembers(), []*ScoredCode[T]{}
+	n := r.params.Speciation.Val.elites()
+	for _, id := range r.speciesIDs() {
+		if !r.culled(id) && len(members[id]) > n {
+			elites = append(elites, members[id][:n]...)
+		}
+	}
+	return elites
+}
+
+// Returns the number of children allotted to each species, which sum to n.
+func (r *optimizationRun[T]) speciesOffspring(n int) map[int]int {
+	members, direction := r.speciesMembers(), r.params.Direction.Val
+	lowest := math.Inf(1)
+	for _, score := range r.scores {
+		lowest, _ = min(lowest, direction.fitness(score.Score))
+	}
+	active, shares, total := []int{}, map[int]float64{}, 0.0
+	for _, id := range r.speciesIDs() {
+		if r.culled(id) || len(members[id]) == 0 {
+			continue
+		}
+		active = append(active, id)
+		for _, score := range members[id] {
+			shares[id] += direction.fitness(score.Score) - lowest
+		}
+		shares[id] /= float64(len(members[id]))
+		total += shares[id]
+	}
+	if total <= 0.0 {
+		for _, id := range active {
+			shares[id] = float64(len(members[id]))
+			total += shares[id]
+		}
+	}
+
+	// allot the remainder to the species with the largest fractions
+	offspring, remainders, allotted := map[int]int{}, map[int]float64{}, 0
+	for _, id := range active {
+		exact := float64(n) * shares[id] / total
+		offspring[id] = int(exact)
+		remainders[id] = exact - float64(offspring[id])
+		allotted += offspring[id]
+	}
+	sort.SliceStable(active, func(i, j int) bool {
+		return remainders[active[i]] > remainders[active[j]]
+	})
+	for i := 0; allotted < n && len(active) > 0; i++ {
+		offspring[active[i%len(active)]]++
+		allotted++
+	}
+	return offspring
+}
+
+// Returns the mates for n_children children, chosen by the Selector from the
+// parents of each species according to its allotment. A species with a single
+// parent breeds it with itself.
+func (r *optimizationRun[T]) speciesMates(n_children int) []Code[T] {
+	members, offspring := r.speciesMembers(), r.speciesOffspring(n_children)
+	rate := r.params.Speciation.Val.survivalRate()
+	mates := []Code[T]{}
+	for _, id := range r.speciesIDs() {
+		if offspring[id] == 0 {
+			continue
+		}
+		n_parents := int(math.Ceil(rate * float64(len(members[id]))))
+		n_parents, _ = max(n_parents, 1)
+		parents := members[id][:n_parents]
+		if len(parents) == 1 {
+			for i := 0; i < offspring[id]; i++ {
+				mates = append(mates, parents[0].Code, parents[0].Code)
+			}
+			continue
+		}
+		mates = append(mates, selectMates(r.params.Selector.Val, parents, offspring[id], r.params.Direction.Val, r.rng)...)
+	}
+	return mates
+}
@@ -0,0 +1,175 @@
+package bluegenes
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+)
+
+func speciationRun(scores []*ScoredCode[int]) *optimizationRun[int] {
+	return &optimizationRun[int]{
+		params: OptimizationParams[int]{
+			Speciation: NewOption(Speciation[int]{Distance: baseDistance, Threshold: 2.0, StagnationLimit: 2}),
+			Selector:   NewOption[Selector[int]](weightedSelector[int]{}),
+		},
+		scores: scores,
+		rng:    NewRand(13),
+	}
+}
+
+func TestSpeciation(t *testing.T) {
+	t.Run("speciate", func(t *testing.T) {
+		t.Parallel()
+		r := speciationRun(nichedCodes([]int{0, 10, 1, 11, 5}, []float64{0.9, 0.8, 0.7, 0.6, 0.5}))
+		r.rank()
+		species := []int{}
+		for _, score := range r.scores {
+			species = append(species, score.Species)
+		}
+		if !equal(species, []int{1, 2, 1, 2, 3}) {
+			t.Errorf("speciate failed: expected [1 2 1 2 3], observed %v", species)
+		}
+		sizes := SpeciesSizes(r.scores)
+		if sizes[1] != 2 || sizes[2] != 2 || sizes[3] != 1 {
+			t.Errorf("SpeciesSizes failed: observed %v", sizes)
+		}
+		if stats := populationStats(r.scores, Maximize); stats.Species != 3 {
+			t.Errorf("populationStats failed to count species: expected 3, observed %d", stats.Species)
+		}
+
+		r.scores = append(r.scores, nichedCodes([]int{4}, []float64{0.1})...)
+		r.rank()
+		if r.scores[5].Species != 3 {
+			t.Errorf("speciate failed to use the representative: expected 3, observed %d", r.scores[5].Species)
+		}
+	})
+
+	t.Run("updateSpecies", func(t *testing.T) {
+		t.Parallel()
+		r := speciationRun(nichedCodes([]int{0, 10, 1}, []float64{0.9, 0.8, 0.7}))
+		r.rank()
+		for i := 0; i < 3; i++ {
+			r.updateSpecies()
+		}
+		if r.species[1].stagnant != 2 || r.culled(1) || !r.culled(2) {
+			t.Errorf("updateSpecies failed: stagnant %d, culled %v and %v", r.species[1].stagnant, r.culled(1), r.culled(2))
+		}
+		r.scores = r.scores[:1]
+		r.updateSpecies()
+		if _, ok := r.species[2]; ok {
+			t.Errorf("updateSpecies failed to forget an extinct species")
+		}
+	})
+
+	t.Run("speciesOffspring", func(t *testing.T) {
+		t.Parallel()
+		r := speciationRun(nichedCodes([]int{0, 1, 10, 11, 20}, []float64{1.0, 1.0, 0.5, 0.5, 0.0}))
+		r.rank()
+		r.updateSpecies()
+		offspring := r.speciesOffspring(10)
+		if offspring[1] != 7 || offspring[2] != 3 || offspring[3] != 0 {
+			t.Errorf("speciesOffspring failed: expected map[1:7 2:3 3:0], observed %v", offspring)
+		}
+		elites := r.speciesElites()
+		if len(elites) != 2 || elites[0] != r.scores[0] || elites[1] != r.scores[2] {
+			t.Errorf("speciesElites failed: observed %d elites", len(elites))
+		}
+		if mates := r.speciesMates(10); len(mates) != 20 {
+			t.Errorf("speciesMates failed: expected 20, observed %d", len(mates))
+		}
+
+		r.species[2].stagnant = 2
+		if offspring := r.speciesOffspring(10); offspring[1] != 10 || offspring[2] != 0 {
+			t.Errorf("speciesOffspring failed to cull a stagnant species: observed %v", offspring)
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(79)
+		initial_population := []Code[float64]{}
+		for i := 0; i < 40; i++ {
+			gene := &Gene[float64]{Name: "x", Bases: []float64{-5.0 + 10.0*rng.Float64()}}
+			initial_population = append(initial_population, Code[float64]{Gene: NewOption(gene)})
+		}
+		unassigned := 0
+		report, err := OptimizeWithReport(OptimizationParams[float64]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(measureTwinPeaks),
+			Mutate: NewOption(func(code *Code[float64]) {
+				GaussianMutation[float64](0.5, 0.2)(code.Gene.Val, rng)
+			}),
+			PopulationSize: NewOption(40),
+			Speciation: NewOption(Speciation[float64]{
+				Metric: EuclideanMetric, Threshold: 1.5, StagnationLimit: 200,
+			}),
+			IterationHook: NewOption(func(generation int, scores []*ScoredCode[float64]) {
+				unassigned += SpeciesSizes(scores)[0]
+			}),
+			FitnessTarget: NewOption(2.0),
+			MaxIterations: NewOption(100),
+			Rand:          NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with Speciation failed with error: %v", err)
+		}
+		if unassigned != 0 {
+			t.Errorf("Optimize with Speciation passed %d members without a species to IterationHook", unassigned)
+		}
+		peaks := map[float64]int{}
+		for _, score := range report.Population {
+			for _, peak := range []float64{-2.0, 2.0} {
+				if math.Abs(score.Code.Gene.Val.Bases[0]-peak) < 0.2 {
+					peaks[peak]++
+				}
+			}
+		}
+		if peaks[-2.0] == 0 || peaks[2.0] == 0 {
+			t.Errorf("Optimize with Speciation failed to keep both peaks: observed %v", peaks)
+		}
+		if last := report.History[len(report.History)-1]; last.Species < 2 {
+			t.Errorf("Optimize with Speciation failed to report the species: observed %d", last.Species)
+		}
+	})
+
+	t.Run("checkpoint", func(t *testing.T) {
+		t.Parallel()
+		r := speciationRun(nichedCodes([]int{0, 10}, []float64{0.9, 0.8}))
+		r.rank()
+		r.updateSpecies()
+		cp := r.checkpoint()
+		restored := restoreOptimizationRun(r.params, optimizeSequentially[int], cp)
+		if restored.nextSpecies != 2 || len(restored.species) != 2 || restored.scores[1].Species != 2 ||
+			restored.species[2].best != 0.8 {
+			t.Errorf("checkpoint failed to restore the species")
+		}
+		buf := bytes.Buffer{}
+		if err := gob.NewEncoder(&buf).Encode(cp); err != nil {
+			t.Errorf("checkpoint failed to encode the species: %v", err)
+		}
+	})
+
+	t.Run("params", func(t *testing.T) {
+		t.Parallel()
+		invalid := map[string]OptimizationParams[int]{
+			"Threshold": {Speciation: NewOption(Speciation[int]{})},
+			"Niching": {
+				Speciation: NewOption(Speciation[int]{Threshold: 1.0}),
+				Niching:    NewOption(Niching[int]{Method: Clearing, Sigma: 1.0}),
+			},
+			"Replacement": {
+				Speciation:  NewOption(Speciation[int]{Threshold: 1.0}),
+				Replacement: NewOption(CommaReplacement),
+			},
+		}
+		for name, params := range invalid {
+			params.InitialPopulation = NewOption(geneInitialPopulation(10))
+			params.MeasureFitness = NewOption(measureCodeFitness)
+			params.Mutate = NewOption(MutateCode)
+			if _, err := prepareOptimizationParams(params); err == nil {
+				t.Errorf("prepareOptimizationParams failed to reject invalid %s", name)
+			}
+		}
+	})
+}
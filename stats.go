@@ -0,0 +1,234 @@
+package bluegenes
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// The reason an optimization run stopped.
+type StopReason int
+
+const (
+	StopNone StopReason = iota
+	StopMaxIterations
+	StopFitnessTarget
+	StopMaxEvaluations
+	StopStagnation
+	StopDiversity
+	StopCancelled
+	StopTimeLimit
+	StopError
+)
+
+func (s StopReason) String() string {
+	switch s {
+	case StopMaxIterations:
+		return "max iterations"
+	case StopFitnessTarget:
+		return "fitness target"
+	case StopMaxEvaluations:
+		return "max evaluations"
+	case StopStagnation:
+		return "stagnation"
+	case StopDiversity:
+		return "diversity"
+	case StopCancelled:
+		return "cancelled"
+	case StopTimeLimit:
+		return "time limit"
+	case StopError:
+		return "error"
+	default:
+		return "none"
+	}
+}
+
+// Summary statistics of the population after a generation. Evaluations and
+// CacheHits are the total number of fitness evaluations and FitnessCache hits
+// so far, and Elapsed is the time since the run started. MeanDistance is only
+// estimated if OptimizationParams.DistanceSamples is set.
+type GenerationStats struct {
+	Generation      int
+	Best            float64
+	Mean            float64
+	Median          float64
+	Worst           float64
+	StdDev          float64
+	Evaluations     int
+	CacheHits       int
+	Elapsed         time.Duration
+	UniqueGenotypes int
+	MeanDistance    float64
+	MutationRate    float64
+	FeasibleRatio   float64
+	Species         int
+}
+
+// The outcome of an optimization run along with the statistics of every
+// generation, starting with the initial population as generation 0.
+type RunReport[T Ordered] struct {
+	Generations int
+	Population  []*ScoredCode[T]
+	History     []GenerationStats
+	StopReason  StopReason
+	Evaluations int
+	CacheHits   int
+	Restarts    int
+	Elapsed     time.Duration
+}
+
+// Returns the bases of every level of the Code concatenated in order.
+func codeBases[T Ordered](code Code[T]) []T {
+	bases := []T{}
+	for _, gene := range codeGenes(code) {
+		bases = append(bases, gene.Sequence()...)
+	}
+	return bases
+}
+
+// Computes the GenerationStats of a population.
+func populationStats[T Ordered](scores []*ScoredCode[T], direction Direction) GenerationStats {
+	stats := GenerationStats{}
+	if len(scores) == 0 {
+		return stats
+	}
+	values := scoresOf(scores)
+	sort.Float64s(values)
+	stats.Worst = values[0]
+	stats.Best = values[len(values)-1]
+	if direction == Minimize {
+		stats.Best, stats.Worst = stats.Worst, stats.Best
+	}
+	if len(values)%2 == 1 {
+		stats.Median = values[len(values)/2]
+	} else {
+		stats.Median = (values[len(values)/2-1] + values[len(values)/2]) / 2.0
+	}
+	for _, value := range values {
+		stats.Mean += value
+	}
+	stats.Mean /= float64(len(values))
+	for _, value := range values {
+		stats.StdDev += (value - stats.Mean) * (value - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(stats.StdDev / float64(len(values)))
+
+	feasible := 0
+	for _, score := range scores {
+		if score.Violation == 0.0 {
+			feasible++
+		}
+	}
+	stats.FeasibleRatio = float64(feasible) / float64(len(scores))
+	stats.UniqueGenotypes = UniqueGenotypes(scores)
+	if sizes := SpeciesSizes(scores); sizes[0] == 0 {
+		stats.Species = len(sizes)
+	}
+	return stats
+}
+
+// Returns the mean distance between n pairs of distinct members chosen at
+// random, or MeanDistance if there are no more than n pairs.
+func sampledMeanDistance[T Ordered](scores []*ScoredCode[T], metric DistanceMetric, n int,
+	rng *Rand) float64 {
+	if len(scores) < 2 || n >= len(scores)*(len(scores)-1)/2 {
+		return MeanDistance(scores, metric)
+	}
+	total := 0.0
+	for k := 0; k < n; k++ {
+		i := randomInt(rng, 0, len(scores))
+		j := randomInt(rng, 0, len(scores)-1)
+		if j >= i {
+			j++
+		}
+		total += scores[i].Code.Distance(scores[j].Code, metric)
+	}
+	return total / float64(n)
+}
+
+// Records the statistics of the current population if they are wanted.
+func (r *optimizationRun[T]) recordStats() {
+	if !r.keepHistory && !r.params.StatsHook.Ok() {
+		return
+	}
+	stats := populationStats(r.scores, r.params.Direction.Val)
+	stats.Generation = r.generationCount
+	stats.Evaluations = r.evaluations
+	stats.CacheHits = r.cacheHits
+	stats.Elapsed = time.Since(r.started)
+	if r.params.DistanceSamples.Ok() {
+		// seeded by the generation so that sampling leaves the run's Rand alone
+		stats.MeanDistance = sampledMeanDistance(r.scores, HammingMetric, r.params.DistanceSamples.Val,
+			NewRand(int64(r.generationCount)))
+	}
+	stats.MutationRate = r.mutationRate
+	if r.params.SelfAdaptation.Ok() {
+		stats.MutationRate = meanStrategy(r.scores)
+	}
+	if r.keepHistory {
+		r.history = append(r.history, stats)
+	}
+	if r.params.StatsHook.Ok() {
+		r.params.StatsHook.Val(stats)
+	}
+}
+
+// Returns the reason the run stopped given the error it returned.
+func (r *optimizationRun[T]) stopReasonFor(err error) StopReason {
+	switch {
+	case err == context.Canceled:
+		return StopCancelled
+	case err == context.DeadlineExceeded:
+		return StopTimeLimit
+	case err != nil:
+		return StopError
+	case r.stopReason != StopNone:
+		return r.stopReason
+	case r.targetReached():
+		return StopFitnessTarget
+	case r.evaluationsRemaining() <= 0:
+		return StopMaxEvaluations
+	case r.generationCount >= r.params.MaxIterations.Val:
+		return StopMaxIterations
+	default:
+		return StopNone
+	}
+}
+
+func OptimizeWithReport[T Ordered](params OptimizationParams[T]) (RunReport[T], error) {
+	return OptimizeWithReportContext(context.Background(), params)
+}
+
+// Runs the optimization like OptimizeContext and returns a RunReport with the
+// statistics of every generation and the reason the run stopped.
+func OptimizeWithReportContext[T Ordered](ctx context.Context, params OptimizationParams[T]) (RunReport[T], error) {
+	report := RunReport[T]{Population: []*ScoredCode[T]{}, History: []GenerationStats{}}
+	params, err := prepareOptimizationParams(params)
+	if err != nil {
+		report.StopReason = StopError
+		return report, err
+	}
+	if params.TimeLimit.Ok() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.TimeLimit.Val)
+		defer cancel()
+	}
+
+	r, err := newOptimizationRun(ctx, params, breederFor(params))
+	if err == nil {
+		r.keepHistory = true
+		_, _, err = r.run(ctx)
+	}
+
+	report.Generations = r.generationCount
+	report.Population = r.scores
+	report.History = append(report.History, r.history...)
+	report.StopReason = r.stopReasonFor(err)
+	report.Evaluations = r.evaluations
+	report.CacheHits = r.cacheHits
+	report.Restarts = r.restarts
+	report.Elapsed = time.Since(r.started)
+	return report, err
+}
@@ -0,0 +1,161 @@
+package bluegenes
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	t.Run("populationStats", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(4)
+		population[3].Code = population[0].Code
+		stats := populationStats(population, Maximize)
+
+		if stats.Best != 1.0 || stats.Worst != 0.25 {
+			t.Errorf("populationStats failed: expected best 1.0 and worst 0.25, observed %f and %f",
+				stats.Best, stats.Worst)
+		}
+		if stats.Mean != 0.625 || stats.Median != 0.625 {
+			t.Errorf("populationStats failed: expected mean and median 0.625, observed %f and %f",
+				stats.Mean, stats.Median)
+		}
+		if math.Abs(stats.StdDev-math.Sqrt(0.078125)) > 1e-9 {
+			t.Errorf("populationStats failed: expected stddev %f, observed %f", math.Sqrt(0.078125), stats.StdDev)
+		}
+		if stats.UniqueGenotypes != 3 {
+			t.Errorf("populationStats failed: expected 3 unique genotypes, observed %d", stats.UniqueGenotypes)
+		}
+	})
+
+	t.Run("sampledMeanDistance", func(t *testing.T) {
+		t.Parallel()
+		population := scoredPopulation(4)
+		population[3].Code = population[0].Code
+		// bases are [0], [1], [2], [0]: 5 of 6 pairs differ by 1
+		if d := sampledMeanDistance(population, HammingMetric, 6, nil); math.Abs(d-5.0/6.0) > 1e-9 {
+			t.Errorf("sampledMeanDistance failed: expected %f, observed %f", 5.0/6.0, d)
+		}
+		d := sampledMeanDistance(population, HammingMetric, 5, NewRand(1))
+		if d < 0.0 || d > 1.0 || d != sampledMeanDistance(population, HammingMetric, 5, NewRand(1)) {
+			t.Errorf("sampledMeanDistance failed: observed %f", d)
+		}
+	})
+
+	for _, parallel_count := range []int{1, 4} {
+		parallel_count := parallel_count
+		t.Run(fmt.Sprintf("OptimizeWithReport/%d", parallel_count), func(t *testing.T) {
+			t.Parallel()
+			hook_calls := 0
+			report, err := OptimizeWithReport(OptimizationParams[int]{
+				InitialPopulation: NewOption(geneInitialPopulation(10)),
+				MeasureFitness:    NewOption(measureCodeFitness),
+				Mutate:            NewOption(MutateCode),
+				MaxIterations:     NewOption(20),
+				FitnessTarget:     NewOption(2.0),
+				ParallelCount:     NewOption(parallel_count),
+				StatsHook: NewOption(func(stats GenerationStats) {
+					hook_calls++
+				}),
+			})
+
+			if err != nil {
+				t.Fatalf("OptimizeWithReport failed with error: %v", err)
+			}
+			if report.Generations != 20 || report.StopReason != StopMaxIterations {
+				t.Errorf("OptimizeWithReport failed: expected 20 generations and %v, observed %d and %v",
+					StopMaxIterations, report.Generations, report.StopReason)
+			}
+			if len(report.History) != 21 || hook_calls != 21 {
+				t.Fatalf("OptimizeWithReport failed: expected 21 stats, observed %d in History and %d hook calls",
+					len(report.History), hook_calls)
+			}
+			for i, stats := range report.History {
+				if stats.Generation != i {
+					t.Errorf("OptimizeWithReport failed: expected generation %d, observed %d", i, stats.Generation)
+				}
+				if stats.Best < stats.Mean || stats.Mean < stats.Worst || stats.Best < stats.Median {
+					t.Errorf("OptimizeWithReport failed: inconsistent stats %+v", stats)
+				}
+			}
+			last := report.History[len(report.History)-1]
+			if last.Evaluations != report.Evaluations || last.Best != report.Population[0].Score {
+				t.Errorf("OptimizeWithReport failed: final stats %+v do not match the report", last)
+			}
+		})
+	}
+
+	t.Run("OptimizeWithReport/DistanceSamples", func(t *testing.T) {
+		t.Parallel()
+		params := OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+			MaxIterations:     NewOption(5),
+			FitnessTarget:     NewOption(2.0),
+		}
+		report, _ := OptimizeWithReport(params)
+		for _, stats := range report.History {
+			if stats.MeanDistance != 0.0 {
+				t.Fatalf("OptimizeWithReport failed: expected no MeanDistance without DistanceSamples, observed %f",
+					stats.MeanDistance)
+			}
+		}
+
+		params.DistanceSamples = NewOption(50)
+		report, _ = OptimizeWithReport(params)
+		if report.History[0].MeanDistance <= 0.0 {
+			t.Errorf("OptimizeWithReport failed to estimate MeanDistance: observed %f", report.History[0].MeanDistance)
+		}
+
+		params.DistanceSamples = NewOption(0)
+		if _, err := OptimizeWithReport(params); err == nil {
+			t.Errorf("OptimizeWithReport failed to reject DistanceSamples of 0")
+		}
+	})
+
+	t.Run("OptimizeWithReport/StopReason", func(t *testing.T) {
+		t.Parallel()
+		params := OptimizationParams[int]{
+			InitialPopulation: NewOption(geneInitialPopulation(10)),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate:            NewOption(MutateCode),
+		}
+		report, _ := OptimizeWithReport(params)
+		if report.StopReason != StopFitnessTarget {
+			t.Errorf("OptimizeWithReport failed: expected %v, observed %v", StopFitnessTarget, report.StopReason)
+		}
+
+		params.FitnessTarget = NewOption(2.0)
+		params.MaxEvaluations = NewOption(500)
+		report, _ = OptimizeWithReport(params)
+		if report.StopReason != StopMaxEvaluations || report.Evaluations != 500 {
+			t.Errorf("OptimizeWithReport failed: expected %v after 500 evaluations, observed %v after %d",
+				StopMaxEvaluations, report.StopReason, report.Evaluations)
+		}
+
+		params.MaxEvaluations = Option[int]{}
+		params.StagnationLimit = NewOption(1)
+		params.StagnationEpsilon = NewOption(1.0)
+		report, _ = OptimizeWithReport(params)
+		if report.StopReason != StopStagnation {
+			t.Errorf("OptimizeWithReport failed: expected %v, observed %v", StopStagnation, report.StopReason)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		report, err := OptimizeWithReportContext(ctx, params)
+		if err != context.Canceled || report.StopReason != StopCancelled {
+			t.Errorf("OptimizeWithReportContext failed: expected %v, observed %v (%v)",
+				StopCancelled, report.StopReason, err)
+		}
+
+		params.Mutate = Option[func(*Code[int])]{}
+		report, _ = OptimizeWithReport(params)
+		if report.StopReason != StopError || report.StopReason.String() != "error" {
+			t.Errorf("OptimizeWithReport failed: expected %v, observed %v", StopError, report.StopReason)
+		}
+	})
+}
@@ -0,0 +1,311 @@
+package bluegenes
+
+import (
+	"fmt"
+)
+
+// The kind of change made by a structural mutation.
+type StructuralChangeKind int
+
+const (
+	GeneDuplicated StructuralChangeKind = iota
+	GeneDeleted
+	GeneTranslocated
+	GenesInverted
+	ChromosomesFused
+	ChromosomeSplit
+)
+
+func (k StructuralChangeKind) String() string {
+	switch k {
+	case GeneDuplicated:
+		return "gene duplicated"
+	case GeneDeleted:
+		return "gene deleted"
+	case GeneTranslocated:
+		return "gene translocated"
+	case GenesInverted:
+		return "genes inverted"
+	case ChromosomesFused:
+		return "chromosomes fused"
+	case ChromosomeSplit:
+		return "chromosome split"
+	default:
+		return "unknown"
+	}
+}
+
+// A change made by a structural mutation. From and To are paths of indices
+// into the mutated structure, outermost first, e.g. [nucleosome, gene] for a
+// Chromosome; To is nil if nothing was created or moved. Count is the number
+// of subunits affected.
+type StructuralChange struct {
+	Kind  StructuralChangeKind
+	From  []int
+	To    []int
+	Count int
+}
+
+func (c StructuralChange) String() string {
+	if c.To == nil {
+		return fmt.Sprintf("%s: %v (%d)", c.Kind, c.From, c.Count)
+	}
+	return fmt.Sprintf("%s: %v -> %v (%d)", c.Kind, c.From, c.To, c.Count)
+}
+
+// A NucleosomeMutator changes the Genes of a Nucleosome in place, drawing all
+// randomness from rng (which may be nil; see Rand), and reports what changed.
+type NucleosomeMutator[T Ordered] func(nucleosome *Nucleosome[T], rng *Rand) []StructuralChange
+
+// A ChromosomeMutator changes the structure of a Chromosome in place and
+// reports what changed.
+type ChromosomeMutator[T Ordered] func(chromosome *Chromosome[T], rng *Rand) []StructuralChange
+
+// A GenomeMutator changes the structure of a Genome in place and reports what
+// changed.
+type GenomeMutator[T Ordered] func(genome *Genome[T], rng *Rand) []StructuralChange
+
+// Returns true if a structure with size subunits can grow within size
+// Bounds, where an Upper less than 1 means there is no maximum.
+func canGrow(size int, bounds Bounds[int]) bool {
+	return bounds.Upper < 1 || size < bounds.Upper
+}
+
+// Returns true if a structure with size subunits can shrink within size Bounds.
+func canShrink(size int, bounds Bounds[int]) bool {
+	return size > 0 && size > bounds.Lower
+}
+
+// With probability rate, copies a random Gene and inserts the copy after the
+// original, keeping the number of Genes within size. If diverge is not nil, it
+// is applied to the copy.
+func GeneDuplication[T Ordered](rate float64, diverge Mutator[T], size Bounds[int]) NucleosomeMutator[T] {
+	return func(nucleosome *Nucleosome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		nucleosome.Mu.Lock()
+		defer nucleosome.Mu.Unlock()
+		if len(nucleosome.Genes) == 0 || !canGrow(len(nucleosome.Genes), size) {
+			return nil
+		}
+		index := randomInt(rng, 0, len(nucleosome.Genes))
+		duplicate := nucleosome.Genes[index].Copy()
+		if diverge != nil {
+			diverge(duplicate, rng)
+		}
+		genes := append([]*Gene[T]{duplicate}, nucleosome.Genes[index+1:]...)
+		nucleosome.Genes = append(nucleosome.Genes[:index+1], genes...)
+		return []StructuralChange{{GeneDuplicated, []int{index}, []int{index + 1}, 1}}
+	}
+}
+
+// With probability rate, deletes a random Gene, keeping the number of Genes
+// within size.
+func GeneDeletion[T Ordered](rate float64, size Bounds[int]) NucleosomeMutator[T] {
+	return func(nucleosome *Nucleosome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		nucleosome.Mu.Lock()
+		defer nucleosome.Mu.Unlock()
+		if !canShrink(len(nucleosome.Genes), size) {
+			return nil
+		}
+		index := randomInt(rng, 0, len(nucleosome.Genes))
+		nucleosome.Genes = append(nucleosome.Genes[:index], nucleosome.Genes[index+1:]...)
+		return []StructuralChange{{GeneDeleted, []int{index}, nil, 1}}
+	}
+}
+
+// With probability rate, reverses the order of a random run of Genes.
+func GeneInversion[T Ordered](rate float64) NucleosomeMutator[T] {
+	return func(nucleosome *Nucleosome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		nucleosome.Mu.Lock()
+		defer nucleosome.Mu.Unlock()
+		genes := nucleosome.Genes
+		if len(genes) < 2 {
+			return nil
+		}
+		start, stop := randomSegment(rng, len(genes))
+		for i, j := start, stop; i < j; i, j = i+1, j-1 {
+			genes[i], genes[j] = genes[j], genes[i]
+		}
+		return []StructuralChange{{GenesInverted, []int{start}, []int{stop}, stop - start + 1}}
+	}
+}
+
+// With probability rate, moves a random Gene from one Nucleosome of the
+// Chromosome to a random position in another, keeping the number of Genes in
+// each Nucleosome within size.
+func GeneTranslocation[T Ordered](rate float64, size Bounds[int]) ChromosomeMutator[T] {
+	return func(chromosome *Chromosome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		chromosome.Mu.Lock()
+		defer chromosome.Mu.Unlock()
+		sources, targets := []int{}, []int{}
+		for i, nucleosome := range chromosome.Nucleosomes {
+			nucleosome.Mu.RLock()
+			if canShrink(len(nucleosome.Genes), size) {
+				sources = append(sources, i)
+			}
+			if canGrow(len(nucleosome.Genes), size) {
+				targets = append(targets, i)
+			}
+			nucleosome.Mu.RUnlock()
+		}
+		if len(sources) == 0 {
+			return nil
+		}
+		from := sources[randomInt(rng, 0, len(sources))]
+		targets = removeItem(targets, from)
+		if len(targets) == 0 {
+			return nil
+		}
+		to := targets[randomInt(rng, 0, len(targets))]
+
+		source, target := chromosome.Nucleosomes[from], chromosome.Nucleosomes[to]
+		source.Mu.Lock()
+		defer source.Mu.Unlock()
+		target.Mu.Lock()
+		defer target.Mu.Unlock()
+		index := randomInt(rng, 0, len(source.Genes))
+		gene := source.Genes[index]
+		source.Genes = append(source.Genes[:index], source.Genes[index+1:]...)
+		position := randomInt(rng, 0, len(target.Genes)+1)
+		target.Genes = append(target.Genes[:position], append([]*Gene[T]{gene}, target.Genes[position:]...)...)
+		return []StructuralChange{{GeneTranslocated, []int{from, index}, []int{to, position}, 1}}
+	}
+}
+
+// With probability rate, appends the Nucleosomes of one random Chromosome to
+// another and removes the first, keeping the number of Chromosomes within
+// count and the number of Nucleosomes in the fused Chromosome within size.
+// From is the removed Chromosome and To is the one it was fused into, both
+// indexed before the removal.
+func ChromosomeFusion[T Ordered](rate float64, count, size Bounds[int]) GenomeMutator[T] {
+	return func(genome *Genome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		genome.Mu.Lock()
+		defer genome.Mu.Unlock()
+		if len(genome.Chromosomes) < 2 || !canShrink(len(genome.Chromosomes), count) {
+			return nil
+		}
+		sizes := make([]int, len(genome.Chromosomes))
+		for i, chromosome := range genome.Chromosomes {
+			chromosome.Mu.RLock()
+			sizes[i] = len(chromosome.Nucleosomes)
+			chromosome.Mu.RUnlock()
+		}
+		pairs := [][2]int{}
+		for to := range sizes {
+			for from := range sizes {
+				if to != from && (size.Upper < 1 || sizes[to]+sizes[from] <= size.Upper) {
+					pairs = append(pairs, [2]int{to, from})
+				}
+			}
+		}
+		if len(pairs) == 0 {
+			return nil
+		}
+		pair := pairs[randomInt(rng, 0, len(pairs))]
+		to, from := pair[0], pair[1]
+		target, source := genome.Chromosomes[to], genome.Chromosomes[from]
+		target.Mu.Lock()
+		source.Mu.RLock()
+		moved := len(source.Nucleosomes)
+		target.Nucleosomes = append(target.Nucleosomes, source.Nucleosomes...)
+		source.Mu.RUnlock()
+		target.Mu.Unlock()
+		genome.Chromosomes = append(genome.Chromosomes[:from], genome.Chromosomes[from+1:]...)
+		return []StructuralChange{{ChromosomesFused, []int{from}, []int{to}, moved}}
+	}
+}
+
+// With probability rate, splits a random Chromosome at a random point,
+// inserting a new Chromosome with a random name and the Nucleosomes after that
+// point after the original, keeping the number of Chromosomes within count and
+// both pieces at or above size.Lower Nucleosomes (and never empty). From is
+// the split point.
+func ChromosomeFission[T Ordered](rate float64, count, size Bounds[int]) GenomeMutator[T] {
+	return func(genome *Genome[T], rng *Rand) []StructuralChange {
+		if rng.Float64() >= rate {
+			return nil
+		}
+		genome.Mu.Lock()
+		defer genome.Mu.Unlock()
+		if !canGrow(len(genome.Chromosomes), count) {
+			return nil
+		}
+		smallest, _ := max(size.Lower, 1)
+		candidates := []int{}
+		for i, chromosome := range genome.Chromosomes {
+			chromosome.Mu.RLock()
+			if len(chromosome.Nucleosomes) >= 2*smallest {
+				candidates = append(candidates, i)
+			}
+			chromosome.Mu.RUnlock()
+		}
+		if len(candidates) == 0 {
+			return nil
+		}
+		index := candidates[randomInt(rng, 0, len(candidates))]
+		chromosome := genome.Chromosomes[index]
+		chromosome.Mu.Lock()
+		defer chromosome.Mu.Unlock()
+		split := randomInt(rng, smallest, len(chromosome.Nucleosomes)-smallest+1)
+		name, _ := randomName(rng, 4)
+		fragment := &Chromosome[T]{Name: name}
+		fragment.Nucleosomes = append(fragment.Nucleosomes, chromosome.Nucleosomes[split:]...)
+		chromosome.Nucleosomes = chromosome.Nucleosomes[:split]
+		chromosomes := append([]*Chromosome[T]{fragment}, genome.Chromosomes[index+1:]...)
+		genome.Chromosomes = append(genome.Chromosomes[:index+1], chromosomes...)
+		return []StructuralChange{{ChromosomeSplit, []int{index, split}, []int{index + 1}, len(fragment.Nucleosomes)}}
+	}
+}
+
+// Prepends index to the From and To paths of each change.
+func prefixChanges(changes []StructuralChange, index int) []StructuralChange {
+	for i, change := range changes {
+		changes[i].From = append([]int{index}, change.From...)
+		if change.To != nil {
+			changes[i].To = append([]int{index}, change.To...)
+		}
+	}
+	return changes
+}
+
+// Returns a ChromosomeMutator that applies the mutator to every Nucleosome of
+// the Chromosome.
+func ForEachNucleosome[T Ordered](mutator NucleosomeMutator[T]) ChromosomeMutator[T] {
+	return func(chromosome *Chromosome[T], rng *Rand) []StructuralChange {
+		chromosome.Mu.RLock()
+		defer chromosome.Mu.RUnlock()
+		changes := []StructuralChange{}
+		for i, nucleosome := range chromosome.Nucleosomes {
+			changes = append(changes, prefixChanges(mutator(nucleosome, rng), i)...)
+		}
+		return changes
+	}
+}
+
+// Returns a GenomeMutator that applies the mutator to every Chromosome of the
+// Genome.
+func ForEachChromosome[T Ordered](mutator ChromosomeMutator[T]) GenomeMutator[T] {
+	return func(genome *Genome[T], rng *Rand) []StructuralChange {
+		genome.Mu.RLock()
+		defer genome.Mu.RUnlock()
+		changes := []StructuralChange{}
+		for i, chromosome := range genome.Chromosomes {
+			changes = append(changes, prefixChanges(mutator(chromosome, rng), i)...)
+		}
+		return changes
+	}
+}
@@ -0,0 +1,214 @@
+package bluegenes
+
+import (
+	"fmt"
+	"testing"
+)
+
+func countGenes(chromosome *Chromosome[int]) int {
+	total := 0
+	for _, nucleosome := range chromosome.Nucleosomes {
+		total += len(nucleosome.Genes)
+	}
+	return total
+}
+
+func TestStructuralMutations(t *testing.T) {
+	t.Run("GeneDuplication", func(t *testing.T) {
+		t.Parallel()
+		nucleosome, _ := rangeNucleosome(3, 0, 5)
+		mutate := GeneDuplication(1.0, UniformResetMutation(1.0, func() int { return -1 }), Bounds[int]{0, 5})
+		for i := 0; i < 2; i++ {
+			changes := mutate(nucleosome, nil)
+			if len(changes) != 1 || changes[0].Kind != GeneDuplicated {
+				t.Fatalf("GeneDuplication failed to report the change: observed %v", changes)
+			}
+			original, duplicate := nucleosome.Genes[changes[0].From[0]], nucleosome.Genes[changes[0].To[0]]
+			if original.Name != duplicate.Name || len(duplicate.Bases) != len(original.Bases) ||
+				newSet(duplicate.Bases...).len() != 1 || duplicate.Bases[0] != -1 {
+				t.Fatalf("GeneDuplication failed to diverge the copy: observed %v", duplicate.Bases)
+			}
+			diverged := 0
+			for _, gene := range nucleosome.Genes {
+				if equal(gene.Bases, duplicate.Bases) {
+					diverged++
+				}
+			}
+			if diverged != i+1 {
+				t.Fatalf("GeneDuplication changed the original Gene: expected %d diverged, observed %d", i+1, diverged)
+			}
+		}
+		if changes := mutate(nucleosome, nil); len(changes) != 0 || len(nucleosome.Genes) != 5 {
+			t.Errorf("GeneDuplication failed to respect the maximum size: observed %d genes", len(nucleosome.Genes))
+		}
+	})
+
+	t.Run("GeneDeletion", func(t *testing.T) {
+		t.Parallel()
+		nucleosome, _ := rangeNucleosome(3, 0, 5)
+		mutate := GeneDeletion[int](1.0, Bounds[int]{1, 0})
+		for i := 0; i < 5; i++ {
+			mutate(nucleosome, nil)
+		}
+		if len(nucleosome.Genes) != 1 {
+			t.Errorf("GeneDeletion failed to respect the minimum size: observed %d genes", len(nucleosome.Genes))
+		}
+		if changes := GeneDeletion[int](0.0, Bounds[int]{})(nucleosome, nil); changes != nil {
+			t.Errorf("GeneDeletion failed: rate 0 changed %v", changes)
+		}
+	})
+
+	t.Run("GeneInversion", func(t *testing.T) {
+		t.Parallel()
+		nucleosome, _ := rangeNucleosome(6, 0, 2)
+		names := []string{}
+		for _, gene := range nucleosome.Genes {
+			names = append(names, gene.Name)
+		}
+		changes := GeneInversion[int](1.0)(nucleosome, NewRand(2))
+		if len(changes) != 1 || changes[0].Count != changes[0].To[0]-changes[0].From[0]+1 {
+			t.Fatalf("GeneInversion failed to report the change: observed %v", changes)
+		}
+		start, stop := changes[0].From[0], changes[0].To[0]
+		for i, gene := range nucleosome.Genes {
+			expected := names[i]
+			if i >= start && i <= stop {
+				expected = names[start+stop-i]
+			}
+			if gene.Name != expected {
+				t.Fatalf("GeneInversion failed: expected %s at %d, observed %s", expected, i, gene.Name)
+			}
+		}
+	})
+
+	t.Run("GeneTranslocation", func(t *testing.T) {
+		t.Parallel()
+		chromosome, _ := rangeChromosome(3, 2, 0, 5)
+		mutate := GeneTranslocation[int](1.0, Bounds[int]{1, 3})
+		for i := 0; i < 20; i++ {
+			changes := mutate(chromosome, NewRand(int64(i)))
+			if countGenes(chromosome) != 6 {
+				t.Fatalf("GeneTranslocation failed: expected 6 genes, observed %d", countGenes(chromosome))
+			}
+			for _, nucleosome := range chromosome.Nucleosomes {
+				if len(nucleosome.Genes) < 1 || len(nucleosome.Genes) > 3 {
+					t.Fatalf("GeneTranslocation failed to respect size: observed %d genes", len(nucleosome.Genes))
+				}
+			}
+			if len(changes) == 1 && changes[0].From[0] == changes[0].To[0] {
+				t.Fatalf("GeneTranslocation failed to move between nucleosomes: observed %v", changes)
+			}
+		}
+	})
+
+	t.Run("ChromosomeFusion/ChromosomeFission", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(3, 2, 2, 0, 5)
+		fuse := ChromosomeFusion[int](1.0, Bounds[int]{2, 0}, Bounds[int]{})
+		split := ChromosomeFission[int](1.0, Bounds[int]{0, 4}, Bounds[int]{})
+		changes := fuse(genome, nil)
+		if len(changes) != 1 || changes[0].Count != 2 || len(genome.Chromosomes) != 2 {
+			t.Fatalf("ChromosomeFusion failed: observed %v, %d chromosomes", changes, len(genome.Chromosomes))
+		}
+		if changes := fuse(genome, nil); changes != nil || len(genome.Chromosomes) != 2 {
+			t.Errorf("ChromosomeFusion failed to respect the minimum count: observed %v", changes)
+		}
+		for i := 0; i < 5; i++ {
+			split(genome, nil)
+		}
+		if len(genome.Chromosomes) != 4 {
+			t.Errorf("ChromosomeFission failed to respect the maximum count: observed %d", len(genome.Chromosomes))
+		}
+		total := 0
+		for _, chromosome := range genome.Chromosomes {
+			if len(chromosome.Nucleosomes) == 0 {
+				t.Errorf("ChromosomeFission produced an empty Chromosome")
+			}
+			total += len(chromosome.Nucleosomes)
+		}
+		if total != 6 {
+			t.Errorf("ChromosomeFusion/ChromosomeFission failed: expected 6 nucleosomes, observed %d", total)
+		}
+	})
+
+	t.Run("ChromosomeFusion/ChromosomeFission/size", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(3, 2, 2, 0, 5)
+		if changes := ChromosomeFusion[int](1.0, Bounds[int]{}, Bounds[int]{1, 3})(genome, nil); changes != nil {
+			t.Errorf("ChromosomeFusion failed to respect the maximum size: observed %v", changes)
+		}
+		changes := ChromosomeFusion[int](1.0, Bounds[int]{}, Bounds[int]{1, 4})(genome, nil)
+		if len(changes) != 1 || len(genome.Chromosomes) != 2 {
+			t.Fatalf("ChromosomeFusion failed at the maximum size: observed %v", changes)
+		}
+		if changes := ChromosomeFusion[int](1.0, Bounds[int]{}, Bounds[int]{1, 5})(genome, nil); changes != nil {
+			t.Errorf("ChromosomeFusion failed to respect the maximum size: observed %v", changes)
+		}
+
+		split := ChromosomeFission[int](1.0, Bounds[int]{}, Bounds[int]{2, 0})
+		for i := 0; i < 5; i++ {
+			split(genome, nil)
+		}
+		if len(genome.Chromosomes) != 3 {
+			t.Fatalf("ChromosomeFission failed: expected 3 chromosomes, observed %d", len(genome.Chromosomes))
+		}
+		for _, chromosome := range genome.Chromosomes {
+			if len(chromosome.Nucleosomes) != 2 {
+				t.Errorf("ChromosomeFission failed to respect the minimum size: observed %d", len(chromosome.Nucleosomes))
+			}
+		}
+		if changes := ChromosomeFission[int](1.0, Bounds[int]{}, Bounds[int]{})(genome, nil); len(changes) != 1 ||
+			changes[0].From[1] != 1 || changes[0].Count != 1 {
+			t.Errorf("ChromosomeFission failed at the minimum size: observed %v", changes)
+		}
+	})
+
+	t.Run("ForEachChromosome", func(t *testing.T) {
+		t.Parallel()
+		genome, _ := rangeGenome(2, 2, 2, 0, 5)
+		changes := ForEachChromosome(ForEachNucleosome(GeneDeletion[int](1.0, Bounds[int]{})))(genome, nil)
+		if len(changes) != 4 {
+			t.Fatalf("ForEachChromosome failed: expected 4 changes, observed %d", len(changes))
+		}
+		for i, change := range changes {
+			if len(change.From) != 3 || change.From[0] != i/2 || change.From[1] != i%2 || change.To != nil {
+				t.Errorf("ForEachChromosome failed to prefix the path: observed %v", change)
+			}
+		}
+		if changes[0].String() != "gene deleted: "+fmt.Sprint(changes[0].From)+" (1)" {
+			t.Errorf("StructuralChange.String failed: observed %s", changes[0])
+		}
+	})
+
+	t.Run("Optimize", func(t *testing.T) {
+		t.Parallel()
+		rng := NewRand(37)
+		initial_population := []Code[int]{}
+		for i := 0; i < 10; i++ {
+			chromosome, _ := rangeChromosome(2, 3, 0, 5)
+			initial_population = append(initial_population, Code[int]{Chromosome: NewOption(chromosome)})
+		}
+		mutate := ForEachNucleosome(GeneDuplication(0.05, SwapMutation[int](1.0), Bounds[int]{1, 6}))
+		remove := ForEachNucleosome(GeneDeletion[int](0.05, Bounds[int]{1, 6}))
+		translocate := GeneTranslocation[int](0.05, Bounds[int]{1, 6})
+		n_iterations, final_population, err := Optimize(OptimizationParams[int]{
+			InitialPopulation: NewOption(initial_population),
+			MeasureFitness:    NewOption(measureCodeFitness),
+			Mutate: NewOption(func(code *Code[int]) {
+				MutateCode(code)
+				mutate(code.Chromosome.Val, rng)
+				remove(code.Chromosome.Val, rng)
+				translocate(code.Chromosome.Val, rng)
+			}),
+			RecombinationOpts: NewOption(RecombineOptions{AlignGenes: NewOption(NameAlignment)}),
+			MaxIterations:     NewOption(1000),
+			Rand:              NewOption(rng),
+		})
+		if err != nil {
+			t.Fatalf("Optimize with structural mutations failed with error: %v", err)
+		}
+		if n_iterations < 1000 && final_population[0].Score < 0.99 {
+			t.Errorf("Optimize with structural mutations failed to meet fitness threshold: %f", final_population[0].Score)
+		}
+	})
+}